@@ -82,6 +82,7 @@ func (a *analyser) GetTaxonomyDatasets(request *restful.Request, response *restf
 		Category    string
 		GeoBBox     string
 		GeoToponym  string
+		License     string
 	}
 	var internalsets []internalDataset
 	reply, err = redis.Values(rcon.Do("SORT", datasetskey+":"+taxonomy+":"+subset,
@@ -94,7 +95,8 @@ func (a *analyser) GetTaxonomyDatasets(request *restful.Request, response *restf
 		"GET", datasetkey+":*->Version",
 		"GET", datasetkey+":*->Category",
 		"GET", datasetkey+":*->GeoBBox",
-		"GET", datasetkey+":*->GeoToponym"))
+		"GET", datasetkey+":*->GeoToponym",
+		"GET", datasetkey+":*->License"))
 	if err != nil {
 		response.WriteError(http.StatusInternalServerError, err)
 		return
@@ -113,7 +115,8 @@ func (a *analyser) GetTaxonomyDatasets(request *restful.Request, response *restf
 			Description: is.Description,
 			Version:     is.Version,
 			GeoBBox:     is.GeoBBox,
-			GeoToponym:  is.GeoToponym}
+			GeoToponym:  is.GeoToponym,
+			License:     is.License}
 
 		var strcats []string
 		if len(is.Category) > 0 {
@@ -164,6 +167,42 @@ func (a *analyser) GetAN003Data(request *restful.Request, response *restful.Resp
 	response.WriteEntity(responseset)
 }
 
+func (a *analyser) GetAN004Data(request *restful.Request, response *restful.Response) {
+
+	id := request.PathParameter("id")
+
+	var reply []interface{}
+	var err error
+
+	rcon := a.pool.Get()
+	defer rcon.Close()
+
+	reply, err = redis.Values(rcon.Do("LRANGE", an004+":"+id, 0, -1))
+
+	if err != nil {
+		response.WriteError(http.StatusInternalServerError, err)
+		return
+	}
+
+	var responseset []ResourceHeadRecord
+	var s string
+
+	for len(reply) > 0 {
+		if reply, err = redis.Scan(reply, &s); err != nil {
+			response.WriteError(http.StatusInternalServerError, err)
+			return
+		}
+		var item ResourceHeadRecord
+		if err := json.Unmarshal([]byte(s), &item); err != nil {
+			response.WriteError(http.StatusInternalServerError, err)
+			return
+		}
+
+		responseset = append(responseset, item)
+	}
+	response.WriteEntity(responseset)
+}
+
 func (a *analyser) GetAN003TaxonomyData(taxonomy string) func(request *restful.Request, response *restful.Response) {
 
 	return func(request *restful.Request, response *restful.Response) {
@@ -235,7 +274,8 @@ func (a *analyser) GetDataset(request *restful.Request, response *restful.Respon
 		"Version",
 		"Category",
 		"GeoBBox",
-		"GeoToponym"))
+		"GeoToponym",
+		"License"))
 	if err != nil {
 		response.WriteError(http.StatusInternalServerError, err)
 		return
@@ -254,6 +294,7 @@ func (a *analyser) GetDataset(request *restful.Request, response *restful.Respon
 		Category    string
 		GeoBBox     string
 		GeoToponym  string
+		License     string
 	)
 
 	if _, err = redis.Scan(reply,
@@ -265,7 +306,8 @@ func (a *analyser) GetDataset(request *restful.Request, response *restful.Respon
 		&Version,
 		&Category,
 		&GeoBBox,
-		&GeoToponym); err != nil {
+		&GeoToponym,
+		&License); err != nil {
 		response.WriteError(http.StatusInternalServerError, err)
 		return
 	}
@@ -277,7 +319,8 @@ func (a *analyser) GetDataset(request *restful.Request, response *restful.Respon
 		Description: Description,
 		Version:     Version,
 		GeoBBox:     GeoBBox,
-		GeoToponym:  GeoToponym}
+		GeoToponym:  GeoToponym,
+		License:     License}
 
 	if len(Category) > 0 {
 		var strcats []string
@@ -370,12 +413,19 @@ func NewAnalyseOGDATRESTService(an *analyser) *restful.WebService {
 		Writes(struct{ Entities []IDNums }{}))
 
 	ws.Route(ws.GET("/taxonomy/toponyms").To(an.GetSortedSet("taxonomy:toponyms")).
-		Doc("Retourniert welche geographischen Abdeckungen in den OGD-Datensätzen spezifiziert sind").
+		Doc("Retourniert welche geographischen Abdeckungen in den OGD-Datensätzen spezifiziert sind, zusammengefasst nach der amtlichen Schreibweise der Orte").
 		Operation("gettoponymscount").
-		Param(ws.QueryParameter("id", "Geographische Abdeckung, für die Anzahl der Datensätze retourniert werden soll. Leer für alle")).
+		Param(ws.QueryParameter("id", "Geographische Abdeckung in amtlicher Schreibweise, für die Anzahl der Datensätze retourniert werden soll. Leer für alle")).
 		Param(ws.QueryParameter("sortorder", "Sortierung der geographischen Abdeckung nach Anzahl Datensätze. 'asc' für aufsteigend, 'desc' für absteigend (standard)")).
 		Writes(struct{ Entities []IDNums }{}))
 
+	ws.Route(ws.GET("/taxonomy/licenses").To(an.GetSortedSet("taxonomy:licenses")).
+		Doc("Retourniert unter welchen Lizenzen die OGD-Datensätze veröffentlicht sind, bekannte Lizenzen zusammengefasst nach ihrer Kennung").
+		Operation("getlicensescount").
+		Param(ws.QueryParameter("id", "Lizenz, etwa CC-BY-3.0-AT, für die Anzahl der Datensätze retourniert werden soll. Leer für alle")).
+		Param(ws.QueryParameter("sortorder", "Sortierung der Lizenzen nach Anzahl Datensätze. 'asc' für aufsteigend, 'desc' für absteigend (standard)")).
+		Writes(struct{ Entities []IDNums }{}))
+
 	ws.Route(ws.GET("/taxonomy/categories").To(an.GetSortedSet("taxonomy:categories")).
 		Doc("Retourniert welche Kategorien in den OGD-Datensätzen spezifiziert sind").
 		Operation("getcategoriescount").
@@ -441,6 +491,19 @@ func NewAnalyseOGDATRESTService(an *analyser) *restful.WebService {
 		Param(ws.PathParameter("id", "Eindeutige Kennung des Datensatzes")).
 		Writes(struct{ CheckRecord []URLCheckRecord }{}))
 
+	ws.Route(ws.GET("/analyse/" + an004 + "/entities").To(an.GetSortedSet(an004 + ":entities")).
+		Doc("Welche Verwaltungseinheiten haben Datensätze mit Ressourcen, deren Größe oder Änderungsdatum nicht den Angaben des Servers entspricht?").
+		Operation("getanalyse004entities").
+		Param(ws.QueryParameter("id", "Verwaltungseinheit, für die Anzahl der veralteten oder falsch angegebenen Ressourcen retourniert werden soll. Leer für alle")).
+		Param(ws.QueryParameter("sortorder", "Sortierung der Verwaltungseinheiten nach Anzahl Datensätze. 'asc' für aufsteigend, 'desc' für absteigend (standard)")).
+		Writes(struct{ Entities []IDNums }{}))
+
+	ws.Route(ws.GET("/analyse/" + an004 + "/{id}").To(an.GetAN004Data).
+		Doc("Retourniert die Ressourcen des Datensatzes mit id, deren Größe oder Änderungsdatum nicht den Angaben des Servers entspricht, samt den Angaben des Servers").
+		Operation("getanalyse004details").
+		Param(ws.PathParameter("id", "Eindeutige Kennung des Datensatzes")).
+		Writes(struct{ ResourceHeadRecord []ResourceHeadRecord }{}))
+
 	// 	ws.Route(ws.POST("/").To(saveApplication).
 	// 		// for documentation
 	// 		Doc("Create or update the Application node").
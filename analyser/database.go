@@ -3,6 +3,7 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"github.com/the42/ogdat"
 	"github.com/the42/ogdat/database"
 	"time"
 )
@@ -13,7 +14,7 @@ type analyserdb struct {
 
 func (conn *analyserdb) GetDatasets() ([]Dataset, error) {
 	const sqldatasets = `
-SELECT id, ckanid, publisher, contact, description, vers, category, geobbox, geotoponym
+SELECT id, ckanid, publisher, contact, description, vers, category, geobbox, geotoponym, license
 FROM dataset`
 
 	rows, err := conn.Query(sqldatasets)
@@ -22,10 +23,10 @@ FROM dataset`
 	}
 
 	var datasets []Dataset
-	var id, ckanid, publisher, contact, description, version, scategory, geobbox, geotoponym *string
+	var id, ckanid, publisher, contact, description, version, scategory, geobbox, geotoponym, license *string
 
 	for rows.Next() {
-		if err := rows.Scan(&id, &ckanid, &publisher, &contact, &description, &version, &scategory, &geobbox, &geotoponym); err != nil {
+		if err := rows.Scan(&id, &ckanid, &publisher, &contact, &description, &version, &scategory, &geobbox, &geotoponym, &license); err != nil {
 			return nil, err
 		}
 
@@ -54,6 +55,9 @@ FROM dataset`
 		if geotoponym != nil {
 			ds.GeoToponym = *geotoponym
 		}
+		if license != nil {
+			ds.License = *license
+		}
 		if scategory != nil {
 			var strcats []string
 			if err := json.Unmarshal([]byte(*scategory), &strcats); err != nil {
@@ -96,12 +100,12 @@ func (conn *analyserdb) Getckanidurl(query string) ([]CKANIDUrl, error) {
 }
 
 func (conn *analyserdb) GetLastCheckResults() ([]CheckRecord, error) {
-	const sqlquery = `
+	sqlquery := fmt.Sprintf(`
 SELECT publisher, ckanid, outers.field_id, outers.hittime, outers.fieldstatus, outers.reason_text, outers.status
 FROM status outers
 INNER JOIN (select datasetid, MAX(hittime) AS hittime
   FROM status
-  WHERE (fieldstatus & x'2000'::int) != x'2000'::int
+  WHERE (fieldstatus & %[1]d) != %[1]d
   GROUP BY datasetid) as lastd
 ON outers.datasetid = lastd.datasetid
   AND outers.hittime = lastd.hittime
@@ -112,7 +116,7 @@ WHERE NOT EXISTS (
   FROM status AS s
   WHERE s.datasetid = outers.datasetid
   AND s.status = 'deleted')
-ORDER BY hittime DESC`
+ORDER BY hittime DESC`, ogdat.FetchableUrl)
 
 	rows, err := conn.Query(sqlquery)
 	if err != nil {
@@ -148,7 +152,7 @@ ORDER BY hittime DESC`
 
 // AN001: Welche Publisher haben unterschiedliche Metadaten, die auf gleiche Daten verweisen?
 func (conn *analyserdb) GetAN001Data() ([]CKANIDUrl, error) {
-	const sqlquery = `
+	sqlquery := fmt.Sprintf(`
 SELECT publisher, ckanid, o.reason_text
 FROM status o
 INNER JOIN dataset
@@ -156,7 +160,7 @@ ON dataset.sysid = o.datasetid
 JOIN (
   select datasetid, max(hittime) hittime
   from status
-  where fieldstatus = x'2001'::int
+  where fieldstatus = %[1]d
   group by datasetid
 ) t2
 ON t2.datasetid = o.datasetid
@@ -167,13 +171,13 @@ WHERE reason_text IN (
 	JOIN (
 	  select datasetid, max(hittime) hittime
 	  from status
-	  where fieldstatus = x'2001'::int
+	  where fieldstatus = %[1]d
 	  group by datasetid
 	) t3
 	ON t3.datasetid = o.datasetid
 	and o.hittime = t3.hittime
 	WHERE o.field_id = 14 -- nur die felder mit resource_url
-	AND o.fieldstatus = x'2001'::int -- datensätze, die von einem check importiert wurden
+	AND o.fieldstatus = %[1]d -- datensätze, die von einem check importiert wurden
 	AND NOT EXISTS ( -- Datensatz wurde nicht gelöscht
 	  SELECT 1
 	  FROM status
@@ -183,8 +187,8 @@ WHERE reason_text IN (
 	GROUP BY reason_text
 	HAVING COUNT(*) > 1)
 AND field_id = 14
-AND fieldstatus = x'2001'::int
-ORDER BY publisher`
+AND fieldstatus = %[1]d
+ORDER BY publisher`, ogdat.Info|ogdat.FetchableUrl)
 
 	return conn.Getckanidurl(sqlquery)
 
@@ -192,7 +196,7 @@ ORDER BY publisher`
 
 // AN002: Welche Publisher haben Metadaten, die mehrere Ressourceeinträge haben und dabei auf gleiche Daten verweisen?
 func (conn *analyserdb) GetAN002Data() ([]CKANIDUrl, error) {
-	const sqlquery = `
+	sqlquery := fmt.Sprintf(`
 SELECT publisher, ckanid, reason_text
 FROM dataset
 INNER JOIN (
@@ -201,7 +205,7 @@ INNER JOIN (
   JOIN (
     select datasetid, max(hittime) hittime
     from status
-    where fieldstatus = x'2001'::int
+    where fieldstatus = %[1]d
     group by datasetid
     ) t2
     ON t2.datasetid = t.datasetid
@@ -212,12 +216,12 @@ INNER JOIN (
     WHERE status.status = 'deleted'
     AND status.datasetid = t.datasetid
     AND status.hittime >= t.hittime)
-  AND t.fieldstatus = (1 | x'2000'::int) -- nur das URL-Infofeld
+  AND t.fieldstatus = %[1]d -- nur das URL-Infofeld
   AND t.field_id = 14 -- nur das Metadatenfeld #14 (resource_url)
   GROUP BY t.datasetid, t.reason_text
   HAVING COUNT(t.reason_text) > 1) AS t
 ON t.datasetid = dataset.sysid
-ORDER BY publisher`
+ORDER BY publisher`, ogdat.Info|ogdat.FetchableUrl)
 
 	return conn.Getckanidurl(sqlquery)
 
@@ -227,7 +231,7 @@ ORDER BY publisher`
 // TODO: Hier wäre es u.U. gut auch die Metadatenversion zu laden, um einen roundtrip zur Datenbank zu
 // vermeiden nur um festzustellen, welche Metadatenversion der Datensatz hat (um den Fehler zu interpretieren)
 func (conn *analyserdb) GetAN003Data() ([]URLCheckRecord, error) {
-	const sqlquery = `
+	sqlquery := fmt.Sprintf(`
 SELECT publisher, ckanid, outers.field_id, outers.reason_text, outers.hittime
 FROM status as outers
 INNER JOIN dataset
@@ -239,7 +243,7 @@ JOIN (
 ) t2
 ON t2.datasetid = outers.datasetid
 and outers.hittime = t2.hittime
-WHERE outers.fieldstatus = x'6004'::int
+WHERE outers.fieldstatus = %d
 -- und nicht gelöscht
 AND NOT EXISTS (
   SELECT 1
@@ -247,7 +251,7 @@ AND NOT EXISTS (
   WHERE status.status = 'deleted'
   AND status.datasetid = outers.datasetid)
   -- AND status.hittime >= outers.hittime)
-ORDER BY publisher, outers.datasetid, field_id`
+ORDER BY publisher, outers.datasetid, field_id`, ogdat.Error|ogdat.FetchableUrl|ogdat.NoDataatUrlError)
 
 	rows, err := conn.Query(sqlquery)
 	if err != nil {
@@ -279,6 +283,83 @@ ORDER BY publisher, outers.datasetid, field_id`
 	return urlcheckrecord, nil
 }
 
+// AN004: Welche Ressourcen sind veraltet oder mit falscher Größe angegeben? Verglichen werden Größe und
+// Änderungsdatum laut Metadaten mit den Angaben des Servers beim letzten Check
+func (conn *analyserdb) GetAN004Data() ([]ResourceHeadRecord, error) {
+	const sqlquery = `
+SELECT publisher, ckanid, outers.resource, outers.url, outers.contentlength, outers.lastmodified, outers.etag,
+  outers.declaredsize, outers.declaredlastmodified, outers.hittime
+FROM resourcehead as outers
+INNER JOIN dataset
+  ON dataset.sysid = outers.datasetid
+JOIN (
+  select datasetid, max(hittime) hittime
+  from resourcehead
+  group by datasetid
+) t2
+ON t2.datasetid = outers.datasetid
+and outers.hittime = t2.hittime
+-- und nicht gelöscht
+WHERE NOT EXISTS (
+  SELECT 1
+  FROM status
+  WHERE status.status = 'deleted'
+  AND status.datasetid = outers.datasetid)
+ORDER BY publisher, outers.datasetid, outers.resource`
+
+	rows, err := conn.Query(sqlquery)
+	if err != nil {
+		return nil, err
+	}
+
+	var resourceheadrecord []ResourceHeadRecord
+	var (
+		publisher, ckanid, url, etag, declaredsize *string
+		resource                                   int
+		contentlength                              *int64
+		lastmodified, declaredlastmodified         *time.Time
+		hittime                                    time.Time
+	)
+
+	for rows.Next() {
+		if err := rows.Scan(&publisher, &ckanid, &resource, &url, &contentlength, &lastmodified, &etag, &declaredsize, &declaredlastmodified, &hittime); err != nil {
+			return nil, err
+		}
+		if ckanid == nil || url == nil {
+			continue
+		}
+
+		head := &ogdat.ResourceHead{URL: *url, ContentLength: -1, LastModified: lastmodified}
+		if contentlength != nil {
+			head.ContentLength = *contentlength
+		}
+		if etag != nil {
+			head.ETag = *etag
+		}
+		checkresult := ogdat.CompareResourceHead(declaredsize, declaredlastmodified, head)
+		if len(checkresult) == 0 {
+			continue
+		}
+
+		record := ResourceHeadRecord{CKANID: *ckanid, Resource: resource, Url: *url, ContentLength: contentlength,
+			LastModified: lastmodified, ETag: head.ETag, DeclaredLastModified: declaredlastmodified, Hittime: hittime}
+		if publisher != nil {
+			record.Publisher = *publisher
+		}
+		if declaredsize != nil {
+			record.DeclaredSize = *declaredsize
+		}
+		for _, info := range checkresult {
+			record.Reason_Text = append(record.Reason_Text, info.Context)
+		}
+		resourceheadrecord = append(resourceheadrecord, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return resourceheadrecord, nil
+}
+
 // BS001: Die letzten num Änderungen mit CKANID und Datum
 func (conn *analyserdb) GetBS001Data(num int) ([]CKANIDTime, error) {
 	sqlquery := fmt.Sprintf(`
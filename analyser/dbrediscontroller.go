@@ -2,8 +2,8 @@ package main
 
 import (
 	"encoding/json"
+	"github.com/the42/ogdat"
 	"github.com/the42/ogdat/database"
-	"strings"
 )
 
 const (
@@ -19,9 +19,11 @@ const (
 	verskey = "versions"
 	entkey  = "entities"
 	topokey = "toponyms"
+	lickey  = "licenses"
 
 	an002 = "an002"
 	an003 = "an003"
+	an004 = "an004"
 )
 
 func (a analyser) populatedatasets() error {
@@ -37,7 +39,7 @@ func (a analyser) populatedatasets() error {
 
 	logger.Println("Deleting base dataset info keys from Redis")
 
-	rcon.Do("DEL", taxonomyprefix+":"+catkey, taxonomyprefix+":"+verskey, taxonomyprefix+":"+entkey, taxonomyprefix+":"+topokey)
+	rcon.Do("DEL", taxonomyprefix+":"+catkey, taxonomyprefix+":"+verskey, taxonomyprefix+":"+entkey, taxonomyprefix+":"+topokey, taxonomyprefix+":"+lickey)
 	database.RedisConn{Conn: rcon}.DeleteKeyPattern(datasetkey+"*", datasetskey+"*")
 
 	if err := rcon.Send("MULTI"); err != nil {
@@ -65,8 +67,12 @@ func (a analyser) populatedatasets() error {
 			return err
 		}
 
-		// populate geographic toponym count
-		if toponym := strings.TrimSpace(set.GeoToponym); len(toponym) > 0 {
+		// populate geographic toponym count, by the official spelling of each place named
+		toponyms, err := ogdat.NormalizeToponym(set.GeoToponym)
+		if err != nil {
+			return err
+		}
+		for _, toponym := range toponyms {
 			if err = rcon.Send("ZINCRBY", taxonomyprefix+":"+topokey, 1, toponym); err != nil {
 				return err
 			}
@@ -76,6 +82,21 @@ func (a analyser) populatedatasets() error {
 			}
 		}
 
+		// populate license count, by the identifier of known licenses
+		license, err := ogdat.NormalizeLicense(set.License)
+		if err != nil {
+			return err
+		}
+		if len(license) > 0 {
+			if err = rcon.Send("ZINCRBY", taxonomyprefix+":"+lickey, 1, license); err != nil {
+				return err
+			}
+			// associate license with ckanid
+			if err = rcon.Send("SADD", datasetskey+":"+lickey+":"+license, set.CKANID); err != nil {
+				return err
+			}
+		}
+
 		// populate category count
 		for _, cat := range set.Category {
 			if err = rcon.Send("ZINCRBY", taxonomyprefix+":"+catkey, 1, cat); err != nil {
@@ -98,7 +119,8 @@ func (a analyser) populatedatasets() error {
 			"Version", set.Version,
 			"Category", string(rv),
 			"GeoBBox", set.GeoBBox,
-			"GeoToponym", set.GeoToponym); err != nil {
+			"GeoToponym", set.GeoToponym,
+			"License", set.License); err != nil {
 			return err
 		}
 	}
@@ -292,6 +314,51 @@ func (a analyser) populatean003() error {
 	return nil
 }
 
+func (a analyser) populatean004() error {
+
+	logger.Println("AN004: Welche Ressourcen sind veraltet oder mit falscher Größe angegeben?")
+	logger.Println("AN004: SQL: Retrieving data")
+	sets, err := a.dbcon.GetAN004Data()
+	if err != nil {
+		return err
+	}
+
+	rcon := a.pool.Get()
+	defer rcon.Close()
+
+	logger.Println("AN004: Deleting keys from Redis")
+	database.RedisConn{Conn: rcon}.DeleteKeyPattern(an004 + "*")
+
+	if err := rcon.Send("MULTI"); err != nil {
+		return nil
+	}
+
+	for _, set := range sets {
+		serial, err := json.Marshal(set)
+		if err != nil {
+			return err
+		}
+		if err = rcon.Send("LPUSH", an004+":"+set.CKANID, string(serial)); err != nil {
+			return err
+		}
+
+		if err = rcon.Send("ZINCRBY", an004+":"+entkey+":"+set.Publisher, len(set.Reason_Text), set.CKANID); err != nil {
+			return err
+		}
+
+		// populate count of check results per entity
+		if err = rcon.Send("ZINCRBY", an004+":"+entkey, 1, set.Publisher); err != nil {
+			return err
+		}
+	}
+
+	logger.Println("AN004: Committing data to Redis")
+	if _, err := rcon.Do("EXEC"); err != nil {
+		return err
+	}
+	return nil
+}
+
 func (a analyser) populatebs001() error {
 	// TODO: How many "last changed datasets" shall be retrieved?
 	const num = 10
@@ -359,6 +426,9 @@ func (a analyser) populatedatasetinfo() error {
 	if err := a.populatean003(); err != nil {
 		return err
 	}
+	if err := a.populatean004(); err != nil {
+		return err
+	}
 	logger.Println("Done dataset analysis")
 	// END DATASET ANALYSIS
 	return nil
@@ -22,6 +22,7 @@ type Dataset struct {
 	Category    []string
 	GeoBBox     string
 	GeoToponym  string
+	License     string
 }
 
 type CheckStatus struct {
@@ -46,6 +47,20 @@ type URLCheckRecord struct {
 	Hittime     time.Time
 }
 
+type ResourceHeadRecord struct {
+	Publisher            string `redis:"-" json:"-"`
+	CKANID               string
+	Resource             int
+	Url                  string
+	ContentLength        *int64
+	LastModified         *time.Time
+	ETag                 string
+	DeclaredSize         string
+	DeclaredLastModified *time.Time
+	Reason_Text          []string
+	Hittime              time.Time
+}
+
 type CKANIDTime struct {
 	CKANID string
 	time.Time
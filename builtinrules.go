@@ -0,0 +1,568 @@
+package ogdat
+
+import (
+	"github.com/the42/ogdat/geometry"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// the rules common to all versions of the specification. Version packages
+// register rules which differ between versions themselves.
+// Rules for the same field run in the order of registration below.
+func init() {
+	RegisterRule(&Rule{
+		Name:        "empty-value",
+		Description: "Optionale Felder sollten nicht leer angegeben werden",
+		Fields: []string{"resource_name", "resource_created", "resource_lastmodified", "resource_size", "resource_language", "resource_encoding",
+			"publisher", "schema_name", "schema_language", "schema_characterset", "geographic_toponym", "geographic_bbox",
+			"end_datetime", "update_frequency", "lineage_quality", "en_title_and_desc", "license_citation"},
+		Check: checkemptyvalue})
+
+	RegisterRule(&Rule{
+		Name:        "description-length",
+		Description: "Die Attributbeschreibung muss eine Mindestlänge haben",
+		Fields:      []string{"attribute_description"},
+		Options:     map[string]string{"minlength": "20"},
+		Check:       checkdescriptionlength})
+
+	RegisterRule(&Rule{
+		Name:        "sane-characters",
+		Description: "Texte dürfen keine ungeeigneten Zeichen enthalten",
+		Fields: []string{"resource_name", "title", "description", "maintainer", "publisher", "license",
+			"attribute_description", "geographic_toponym", "lineage_quality", "en_title_and_desc", "license_citation"},
+		Check: checksanecharacters})
+
+	RegisterRule(&Rule{
+		Name:        "time-format",
+		Description: "Datums- und Zeitangaben müssen dem Format der Spezifikation entsprechen",
+		Fields:      []string{"metadata_modified", "resource_created", "resource_lastmodified", "begin_datetime", "end_datetime"},
+		Check:       checktimeformat})
+
+	RegisterRule(&Rule{
+		Name:        "linkage-array",
+		Description: "Metadatenverweise sollen als Array angegeben werden",
+		Fields:      []string{"metadata_linkage"},
+		Check:       checklinkagearray})
+
+	RegisterRule(&Rule{
+		Name:        "link",
+		Description: "Verweise müssen gültig und erreichbar sein",
+		Fields:      []string{"resource_url", "metadata_linkage", "maintainer_link", "maintainer_email"},
+		Check:       checklink})
+
+	RegisterRule(&Rule{
+		Name:        "portal-link",
+		Description: "Verweise auf das Ursprungsportal müssen der Konvention für Datenportale entsprechen",
+		Fields:      []string{"metadata_original_portal"},
+		Check:       checkportallink})
+
+	RegisterRule(&Rule{
+		Name:        "resource-format",
+		Description: "Das Format einer Ressource ist in Kleinbuchstaben und ohne Sonderzeichen anzugeben",
+		Fields:      []string{"resource_format"},
+		Check:       checkresourceformat})
+
+	RegisterRule(&Rule{
+		Name:        "resource-format-known",
+		Description: "Das Format einer Ressource sollte ein bekanntes Format sein",
+		Fields:      []string{"resource_format"},
+		Check:       checkresourceformatknown})
+
+	RegisterRule(&Rule{
+		Name:        "resource-content",
+		Description: "Content-Type und Inhalt einer Ressource müssen ihrem Format entsprechen",
+		Fields:      []string{"resource_format"},
+		Check:       checkresourcecontent})
+
+	RegisterRule(&Rule{
+		Name:        "resource-size",
+		Description: "Die Größe einer Ressource ist als Zahl anzugeben",
+		Fields:      []string{"resource_size"},
+		Check:       checkresourcesize})
+
+	RegisterRule(&Rule{
+		Name:        "iso-language",
+		Description: "Die Sprache einer Ressource ist als Code nach ISO 639-2 anzugeben",
+		Fields:      []string{"resource_language"},
+		Check:       checkisolanguage})
+
+	RegisterRule(&Rule{
+		Name:        "encoding",
+		Description: "Die Kodierung einer Ressource ist als utf8, utf16 oder utf32 anzugeben",
+		Fields:      []string{"resource_encoding"},
+		Check:       checkencoding})
+
+	RegisterRule(&Rule{
+		Name:        "uuid",
+		Description: "Der Metadatenidentifikator muss eine UUID sein",
+		Fields:      []string{"metadata_identifier"},
+		Check:       checkuuid})
+
+	RegisterRule(&Rule{
+		Name:        "categorization",
+		Description: "Kategorien müssen angegeben werden und der Spezifikation entsprechen",
+		Fields:      []string{"categorization"},
+		Check:       checkcategorization})
+
+	RegisterRule(&Rule{
+		Name:        "keywords",
+		Description: "Schlagworte sollen angegeben werden",
+		Fields:      []string{"keywords"},
+		Check:       checkkeywords})
+
+	RegisterRule(&Rule{
+		Name:        "schema-language",
+		Description: "Die Sprache der Metadaten muss der Spezifikation entsprechen",
+		Fields:      []string{"schema_language"},
+		Options:     map[string]string{"expected": "ger"},
+		Check:       checkschemalanguage})
+
+	RegisterRule(&Rule{
+		Name:        "schema-characterset",
+		Description: "Die Zeichenkodierung der Metadaten muss der Spezifikation entsprechen",
+		Fields:      []string{"schema_characterset"},
+		Options:     map[string]string{"expected": "utf8"},
+		Check:       checkschemacharacterset})
+
+	RegisterRule(&Rule{
+		Name:        "update-frequency",
+		Description: "Der Aktualisierungszyklus muss einem Wert der Spezifikation entsprechen",
+		Fields:      []string{"update_frequency"},
+		Check:       checkupdatefrequency})
+
+	RegisterRule(&Rule{
+		Name:        "toponym",
+		Description: "Ortsbezeichnungen sollen österreichische Gebietskörperschaften benennen und zur örtlichen Begrenzung passen",
+		Fields:      []string{"geographic_toponym"},
+		Check:       checktoponym})
+
+	RegisterRule(&Rule{
+		Name:        "license",
+		Description: "Die Lizenz soll als URI einer bekannten Lizenz angegeben werden und zur Namensnennung passen",
+		Fields:      []string{"license"},
+		Check:       checklicense})
+
+	RegisterRule(&Rule{
+		Name:        "allowed-values",
+		Description: "Felder dürfen nur einen der in Option 'values' durch '|' getrennt angegebenen Werte enthalten",
+		Disabled:    true,
+		Options:     map[string]string{"values": ""},
+		Check:       checkallowedvalues})
+}
+
+// StringValue returns the value of the field being checked as a string, as far as
+// the type of the field permits. ok is false if the field is not present or has another type.
+func (ctx *RuleContext) StringValue() (value string, ok bool) {
+	if ctx.IsNil() {
+		return "", false
+	}
+	switch val := ctx.Value.(type) {
+	case *string:
+		return *val, true
+	case *ResourceSpecifier:
+		return string(*val), true
+	case *Time:
+		return val.Raw, true
+	case *Cycle:
+		return val.Raw, true
+	case *Url:
+		return val.Raw, true
+	case *Identifier:
+		return val.Raw, true
+	}
+	return "", false
+}
+
+func checkemptyvalue(ctx *RuleContext) ([]CheckMessage, error) {
+	// required fields are checked for presence, but an empty value is left to the other rules
+	if ctx.Field.IsRequired() {
+		return nil, nil
+	}
+	if value, ok := ctx.StringValue(); ok && len(value) == 0 {
+		ctx.Done = true
+		return []CheckMessage{ctx.NewMessage(Info|EmptyData, CodeEmptyValue, NoParams)}, nil
+	}
+	return nil, nil
+}
+
+func checkdescriptionlength(ctx *RuleContext) ([]CheckMessage, error) {
+	desc, ok := ctx.Value.(*string)
+	if !ok || desc == nil {
+		return nil, nil
+	}
+	minlength, err := strconv.Atoi(ctx.Options["minlength"])
+	if err != nil {
+		return nil, err
+	}
+	if len(*desc) < minlength {
+		return []CheckMessage{ctx.NewMessage(Warning, CodeDescriptionShort, ValueParams(*desc, "minlength", ctx.Options["minlength"]))}, nil
+	}
+	return nil, nil
+}
+
+func checksanecharacters(ctx *RuleContext) ([]CheckMessage, error) {
+	str, ok := ctx.Value.(*string)
+	if !ok || str == nil {
+		return nil, nil
+	}
+	if ok, err := CheckOGDTextStringForSaneCharacters(*str); !ok {
+		if cerr, ok := err.(*CheckInfo); ok {
+			return []CheckMessage{ctx.Message(cerr)}, nil
+		}
+	}
+	return nil, nil
+}
+
+var timeformats = map[string]struct {
+	format, code string
+}{
+	"metadata_modified":     {CustomTimeSpecifier2, CodeDateFormat},
+	"resource_created":      {CustomTimeSpecifier2, CodeDateFormat},
+	"resource_lastmodified": {CustomTimeSpecifier2, CodeDateFormat},
+	"begin_datetime":        {CustomTimeSpecifier1, CodeDateTimeFormat},
+	"end_datetime":          {CustomTimeSpecifier1, CodeDateTimeFormat},
+}
+
+func checktimeformat(ctx *RuleContext) ([]CheckMessage, error) {
+	t, ok := ctx.Value.(*Time)
+	if !ok || t == nil {
+		return nil, nil
+	}
+	timeformat, ok := timeformats[ctx.Field.OGD_Kurzname]
+	if !ok {
+		return nil, nil
+	}
+	if t.Format != timeformat.format {
+		return []CheckMessage{ctx.NewMessage(Error, timeformat.code, ValueParams(t.Raw))}, nil
+	}
+	return nil, nil
+}
+
+func checklinkagearray(ctx *RuleContext) ([]CheckMessage, error) {
+	linkage, ok := ctx.Value.(*MetaDataLinkage)
+	if !ok || linkage == nil || linkage.IsArray {
+		return nil, nil
+	}
+	return []CheckMessage{ctx.NewMessage(Info|StructuralError, CodeLinkageNotArray, NoParams)}, nil
+}
+
+func checkurl(ctx *RuleContext, link Url, check func(string, bool) (bool, []CheckInfo)) []CheckMessage {
+	if link.URL == nil {
+		return []CheckMessage{ctx.NewMessage(Error, CodeLinkInvalid, ValueParams(link.Raw))}
+	}
+	_, checkresult := check(link.Raw, ctx.FollowHTTPLinks)
+	return AppendCheckInfos(nil, checkresult, ctx.Field.ID, ctx.Resource)
+}
+
+func checklink(ctx *RuleContext) ([]CheckMessage, error) {
+	switch val := ctx.Value.(type) {
+	case *Url:
+		if val != nil && val.URL != nil && ctx.Resource > -1 && ctx.FollowHTTPLinks && strings.HasPrefix(val.Raw, "http") {
+			return checkresourcelink(ctx, *val), nil
+		}
+		if val != nil {
+			return checkurl(ctx, *val, CheckUrl), nil
+		}
+	case *MetaDataLinkage:
+		if val != nil {
+			var messages []CheckMessage
+			for _, link := range val.Url {
+				messages = append(messages, checkurl(ctx, link, CheckUrl)...)
+			}
+			return messages, nil
+		}
+	}
+	return nil, nil
+}
+
+// checkresourcelink checks the link of a resource like checkurl and compares
+// the header of the response with resource_size and resource_lastmodified
+func checkresourcelink(ctx *RuleContext, link Url) []CheckMessage {
+	_, checkresult := CheckUrl(link.Raw, false)
+	head, info := FetchResourceHead(link.Raw)
+	checkresult = append(checkresult, info)
+
+	if md := ctx.Metadata.MinimalMetadata(); head != nil && md != nil && ctx.Resource < len(md.Resources) {
+		res := md.Resources[ctx.Resource]
+		var lastmodified *time.Time
+		// unparseable dates are reported by the check of the field
+		if res.LastModified != nil && res.LastModified.Format != "" {
+			lastmodified = &res.LastModified.Time
+		}
+		checkresult = append(checkresult, CompareResourceHead(res.Size, lastmodified, head)...)
+	}
+	return AppendCheckInfos(nil, checkresult, ctx.Field.ID, ctx.Resource)
+}
+
+func checkportallink(ctx *RuleContext) ([]CheckMessage, error) {
+	if link, ok := ctx.Value.(*Url); ok && link != nil {
+		return checkurl(ctx, *link, CheckDataPortalUrl), nil
+	}
+	return nil, nil
+}
+
+func checkresourceformat(ctx *RuleContext) ([]CheckMessage, error) {
+	spec, ok := ctx.Value.(*ResourceSpecifier)
+	if !ok || spec == nil {
+		return nil, nil
+	}
+	var messages []CheckMessage
+	const checkchars = `.:/\`
+	format := string(*spec)
+	if idx := strings.IndexAny(format, checkchars); idx > -1 {
+		messages = append(messages, ctx.NewMessage(Warning, CodeFormatInvalidChar, ValueParams(format, "char", string(format[idx])).AtPosition(idx)))
+	}
+	if format != strings.ToLower(format) {
+		messages = append(messages, ctx.NewMessage(Warning, CodeFormatLowerCase, ValueParams(format)))
+	}
+	return messages, nil
+}
+
+func checkresourceformatknown(ctx *RuleContext) ([]CheckMessage, error) {
+	spec, ok := ctx.Value.(*ResourceSpecifier)
+	if !ok || spec == nil || strings.TrimSpace(string(*spec)) == "" {
+		return nil, nil
+	}
+	reg, err := Formats()
+	if err != nil {
+		return nil, err
+	}
+	value := string(*spec)
+	format, known := reg.Lookup(value)
+	switch {
+	case !known:
+		return []CheckMessage{ctx.NewMessage(Info, CodeFormatUnknown, ValueParams(value))}, nil
+	// case and leading dots are reported by the rule resource-format
+	case format.ID != formatkey(value):
+		return []CheckMessage{ctx.NewMessage(Info, CodeFormatSynonym, ValueParams(value, "format", format.ID))}, nil
+	}
+	return nil, nil
+}
+
+func checkresourcecontent(ctx *RuleContext) ([]CheckMessage, error) {
+	spec, ok := ctx.Value.(*ResourceSpecifier)
+	if !ctx.FollowHTTPLinks || !ok || spec == nil || ctx.Resource < 0 {
+		return nil, nil
+	}
+	md := ctx.Metadata.MinimalMetadata()
+	if md == nil || ctx.Resource >= len(md.Resources) {
+		return nil, nil
+	}
+	url := md.Resources[ctx.Resource].URL
+	if url == nil || !strings.HasPrefix(*url, "http") {
+		return nil, nil
+	}
+	reg, err := Formats()
+	if err != nil {
+		return nil, err
+	}
+	// unknown formats are reported by the rule resource-format-known
+	format, known := reg.Lookup(string(*spec))
+	if !known {
+		return nil, nil
+	}
+	return AppendCheckInfos(nil, CheckResourceContent(*url, format), ctx.Field.ID, ctx.Resource), nil
+}
+
+func checkresourcesize(ctx *RuleContext) ([]CheckMessage, error) {
+	size, ok := ctx.Value.(*string)
+	if !ok || size == nil {
+		return nil, nil
+	}
+	if _, err := strconv.Atoi(*size); err != nil {
+		return []CheckMessage{ctx.NewMessage(Error, CodeSizeNotNumeric, ValueParams(*size))}, nil
+	}
+	return nil, nil
+}
+
+func checkisolanguage(ctx *RuleContext) ([]CheckMessage, error) {
+	lang, ok := ctx.Value.(*string)
+	if !ok || lang == nil {
+		return nil, nil
+	}
+	isolang, err := CheckISOLanguage(*lang)
+	if err != nil {
+		return nil, err
+	}
+	if !isolang {
+		return []CheckMessage{ctx.NewMessage(Error, CodeLanguageInvalid, ValueParams(*lang))}, nil
+	}
+	return nil, nil
+}
+
+func checkencoding(ctx *RuleContext) ([]CheckMessage, error) {
+	enc, ok := ctx.Value.(*string)
+	if !ok || enc == nil {
+		return nil, nil
+	}
+	// the specification mentions only these encodings as valid
+	if CheckEncodingString(*enc, []string{"utf8", "utf16", "utf32"}) {
+		return nil, nil
+	}
+	// ... but this is unfortunate, as certainly more encodings may be valid for OGD AT
+	ianaenc, err := CheckIANAEncoding(*enc)
+	if err != nil {
+		return nil, err
+	}
+	if ianaenc {
+		return []CheckMessage{ctx.NewMessage(Warning, CodeEncodingNotInSpec, ValueParams(*enc))}, nil
+	}
+	// unknown encoding, report it
+	return []CheckMessage{ctx.NewMessage(Error, CodeEncodingUnknown, ValueParams(*enc))}, nil
+}
+
+func checkuuid(ctx *RuleContext) ([]CheckMessage, error) {
+	if id, ok := ctx.Value.(*Identifier); ok && id != nil && id.UUID == nil {
+		return []CheckMessage{ctx.NewMessage(Error, CodeUUIDInvalid, ValueParams(id.Raw))}, nil
+	}
+	return nil, nil
+}
+
+func checkcategorization(ctx *RuleContext) ([]CheckMessage, error) {
+	cat, _ := ctx.Value.(*MetaDataKategorie)
+	if cat == nil || len(cat.Kategorie) == 0 {
+		return []CheckMessage{ctx.NewMessage(Warning, CodeCategoryMissing, NoParams)}, nil
+	}
+	var messages []CheckMessage
+	if cat.IsString {
+		messages = append(messages, ctx.NewMessage(Info|StructuralError, CodeCategoryNotArray, NoParams))
+	}
+	for _, element := range cat.Kategorie {
+		if element.NumID == -1 {
+			messages = append(messages, ctx.NewMessage(Error, CodeCategoryUnknown, ValueParams(element.ID)))
+		}
+	}
+	return messages, nil
+}
+
+func checkkeywords(ctx *RuleContext) ([]CheckMessage, error) {
+	if keywords, _ := ctx.Value.([]Tags); len(keywords) == 0 {
+		return []CheckMessage{ctx.NewMessage(Warning, CodeKeywordsMissing, NoParams)}, nil
+	}
+	return nil, nil
+}
+
+func checkschemalanguage(ctx *RuleContext) ([]CheckMessage, error) {
+	lang, ok := ctx.Value.(*string)
+	if !ok || lang == nil {
+		return nil, nil
+	}
+	if expected := ctx.Options["expected"]; expected != strings.ToLower(*lang) {
+		return []CheckMessage{ctx.NewMessage(Error, CodeSchemaLanguage, ValueParams(*lang, "expected", expected))}, nil
+	}
+	return nil, nil
+}
+
+func checkschemacharacterset(ctx *RuleContext) ([]CheckMessage, error) {
+	charset, ok := ctx.Value.(*string)
+	if !ok || charset == nil {
+		return nil, nil
+	}
+	if expected := ctx.Options["expected"]; !CheckEncodingString(*charset, []string{expected}) {
+		return []CheckMessage{ctx.NewMessage(Error, CodeSchemaCharacterset, ValueParams(*charset, "expected", expected))}, nil
+	}
+	return nil, nil
+}
+
+func checkupdatefrequency(ctx *RuleContext) ([]CheckMessage, error) {
+	if frequency, ok := ctx.Value.(*Cycle); ok && frequency != nil && frequency.NumID == -1 {
+		return []CheckMessage{ctx.NewMessage(Warning, CodeFrequencyInvalid, ValueParams(frequency.Raw))}, nil
+	}
+	return nil, nil
+}
+
+func checktoponym(ctx *RuleContext) ([]CheckMessage, error) {
+	toponym, ok := ctx.Value.(*string)
+	if !ok || toponym == nil {
+		return nil, nil
+	}
+	g, err := Toponyms()
+	if err != nil {
+		return nil, err
+	}
+	// an extent outside of Austria is reported by the check of geographic_bbox already
+	var bbox *geometry.BBox
+	if md := ctx.Metadata.MinimalMetadata(); md != nil && md.BBox != nil && md.BBox.Intersects(geometry.Austria) {
+		bbox = md.BBox
+	}
+
+	var messages []CheckMessage
+	for _, match := range g.Resolve(*toponym) {
+		if len(match.Places) == 0 {
+			messages = append(messages, ctx.NewMessage(Info, CodeToponymUnknown, ValueParams(match.Part)))
+			continue
+		}
+		if name := match.Name(); match.Part != name {
+			messages = append(messages, ctx.NewMessage(Info, CodeToponymSpelling, ValueParams(match.Part, "name", name)))
+		}
+		if bbox == nil {
+			continue
+		}
+		// ambiguous names as "Salzburg" match if any of the places does
+		matches := false
+		for _, place := range match.Places {
+			if place.BBox != nil && place.BBox.Intersects(*bbox) {
+				matches = true
+				break
+			}
+		}
+		if place := match.Places[0]; !matches && place.BBox != nil {
+			messages = append(messages, ctx.NewMessage(Warning, CodeToponymBBox, ValueParams(match.Part, "bbox", place.BBox.String())))
+		}
+	}
+	return messages, nil
+}
+
+func checklicense(ctx *RuleContext) ([]CheckMessage, error) {
+	value, ok := ctx.Value.(*string)
+	// empty values and invalid encodings are reported by other rules
+	if !ok || value == nil || strings.TrimSpace(*value) == "" || !utf8.ValidString(*value) {
+		return nil, nil
+	}
+	reg, err := Licenses()
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []CheckMessage
+	license, known := reg.Lookup(*value)
+	switch {
+	case known && !IsLicenseURI(*value):
+		messages = append(messages, ctx.NewMessage(Warning, CodeLicenseName, ValueParams(*value, "uri", license.URI)))
+	case !IsLicenseURI(*value):
+		messages = append(messages, ctx.NewMessage(Warning, CodeLicenseNotURI, ValueParams(*value)))
+	case !known:
+		messages = append(messages, ctx.NewMessage(Warning, CodeLicenseUnknown, ValueParams(*value)))
+	}
+	if !known {
+		return messages, nil
+	}
+
+	var citation string
+	if md := ctx.Metadata.MinimalMetadata(); md != nil && md.License_Citation != nil {
+		citation = strings.TrimSpace(*md.License_Citation)
+	}
+	switch {
+	case license.Attribution && citation == "":
+		messages = append(messages, ctx.NewMessage(Warning, CodeCitationMissing, ValueParams(license.ID)))
+	case !license.Attribution && citation != "":
+		messages = append(messages, ctx.NewMessage(Info, CodeCitationNotRequired, ValueParams(license.ID)))
+	}
+	return messages, nil
+}
+
+func checkallowedvalues(ctx *RuleContext) ([]CheckMessage, error) {
+	value, ok := ctx.StringValue()
+	if !ok {
+		return nil, nil
+	}
+	allowed := ctx.Options["values"]
+	for _, val := range strings.Split(allowed, "|") {
+		if val == value {
+			return nil, nil
+		}
+	}
+	return []CheckMessage{ctx.NewMessage(Error, CodeValueNotAllowed, ValueParams(value, "allowed", allowed))}, nil
+}
@@ -0,0 +1,123 @@
+package ogdat
+
+import (
+	"embed"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// DataDirEnv names the environment variable which may point to a directory
+// containing replacements for the embedded specification and reference data files
+const DataDirEnv = "OGDAT_DATADIR"
+
+const (
+	iso639file    = "ISO-639-2_utf-8.txt"
+	ianaencfile   = "character-sets.txt"
+	gazetteerfile = "gazetteer.csv"
+	licensefile   = "licenses.csv"
+	formatfile    = "formats.csv"
+)
+
+//go:embed data/ISO-639-2_utf-8.txt data/character-sets.txt data/gazetteer.csv data/licenses.csv data/formats.csv
+var referencedata embed.FS
+
+// the reference data files are embedded below data/
+var referencefs, _ = fs.Sub(referencedata, "data")
+
+var datadir = struct {
+	sync.RWMutex
+	dir string
+}{dir: os.Getenv(DataDirEnv)}
+
+// fsspec remembers where a specification has been registered from by RegisterFromFS
+type fsspec struct {
+	version, specfile string
+	fsys              fs.FS
+	set               *OGDSet
+}
+
+var fsspecs = struct {
+	sync.Mutex
+	specs []*fsspec
+}{}
+
+// DataDir returns the directory which is searched for the specification and
+// reference data files before falling back to the embedded ones. It is
+// initialised from the environment variable OGDAT_DATADIR.
+func DataDir() string {
+	datadir.RLock()
+	defer datadir.RUnlock()
+	return datadir.dir
+}
+
+// SetDataDir sets the directory which is searched for the specification and
+// reference data files before falling back to the embedded ones. An empty dir
+// uses the embedded files only. Specifications registered by RegisterFromFS get
+// reloaded, the language and encoding tables, the gazetteer, the licenses and
+// the formats will be reloaded on next use.
+// SetDataDir is meant to be called on startup, before any check is running.
+func SetDataDir(dir string) error {
+	datadir.Lock()
+	datadir.dir = dir
+	datadir.Unlock()
+
+	resetreferenceregistries()
+
+	fsspecs.Lock()
+	defer fsspecs.Unlock()
+	for _, spec := range fsspecs.specs {
+		set, err := loadogdatspecfs(spec.version, spec.fsys, spec.specfile)
+		if err != nil {
+			return err
+		}
+		*spec.set = *set
+	}
+	return nil
+}
+
+// opendatafile opens name from the data directory, if set and if it contains
+// a file of that name, otherwise from fsys
+func opendatafile(fsys fs.FS, name string) (io.ReadCloser, error) {
+	if dir := DataDir(); dir != "" {
+		file, err := os.Open(filepath.Join(dir, name))
+		if err == nil {
+			return file, nil
+		}
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	if fsys == nil {
+		return nil, fmt.Errorf("Datei '%s' nicht gefunden", name)
+	}
+	return fsys.Open(name)
+}
+
+func loadogdatspecfs(version string, fsys fs.FS, specfile string) (*OGDSet, error) {
+	reader, err := opendatafile(fsys, specfile)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return LoadogdatspecReader(version, reader)
+}
+
+// RegisterFromFS loads the specification file specfile, usually embedded into
+// the version package, and registers it for version. A file of the same name
+// in DataDir takes precedence over the one in fsys.
+func RegisterFromFS(version string, fsys fs.FS, specfile string) (*OGDSet, error) {
+	set, err := loadogdatspecfs(version, fsys, specfile)
+	if err != nil {
+		return nil, fmt.Errorf("Can not load specification '%s' for %s: %s", specfile, version, err)
+	}
+
+	fsspecs.Lock()
+	fsspecs.specs = append(fsspecs.specs, &fsspec{version: version, specfile: specfile, fsys: fsys, set: set})
+	fsspecs.Unlock()
+
+	return Register(version, set), nil
+}
@@ -0,0 +1,223 @@
+package dcat
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"github.com/the42/ogdat"
+	"github.com/the42/ogdat/ogdatv23"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func readfile(t *testing.T, filename string) *ogdatv23.MetaData {
+	file, err := os.Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	md, err := ogdatv23.MetadatafromJSONStream(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return md
+}
+
+func exportfile(t *testing.T, filename string) *Graph {
+	g, err := Export(readfile(t, filename), DefaultBaseURI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func TestExport(t *testing.T) {
+	g := exportfile(t, "testfiles/fullandok.json")
+
+	dataset := NewIRI(DefaultBaseURI + "0045692c-00e7-4e46-8bfc-336a92bd51e9")
+	expected := []Triple{
+		{dataset, NewIRI("rdf:type"), NewIRI("dcat:Dataset")},
+		{dataset, NewIRI("dct:title"), NewLangLiteral("Informationen über ACME county", "de")},
+		{dataset, NewIRI("dct:issued"), NewTypedLiteral("2012-10-17", "xsd:date")},
+		{dataset, NewIRI("cnt:characterEncoding"), NewLiteral("utf8")},
+		{dataset, NewIRI("dct:accrualPeriodicity"), NewIRI(frequencyvocabulary + "MONTHLY")},
+		{dataset, NewIRI("dcat:distribution"), NewIRI(dataset.Value + "/resource/0")},
+		{NewIRI(dataset.Value + "/resource/0"), NewIRI("dcat:accessURL"), NewIRI("https://example.org/acme.csv")},
+		{NewIRI(dataset.Value + "/resource/0"), NewIRI("dcat:byteSize"), NewTypedLiteral("4681", "xsd:nonNegativeInteger")},
+		{NewIRI(dataset.Value + "/resource/0"), NewIRI("dct:format"), NewIRI(filetypevocabulary + "CSV")},
+		{NewIRI(dataset.Value + "/resource/0"), NewIRI("dct:license"), NewIRI("https://creativecommons.org/licenses/by/3.0/at/")},
+	}
+	for _, triple := range expected {
+		if !g.seen[triple] {
+			t.Errorf("TestExport: missing triple %s %s %s", triple.Subject, triple.Predicate, triple.Object)
+		}
+	}
+	themes := 0
+	for _, triple := range g.Triples {
+		if triple.Predicate == NewIRI("dcat:theme") {
+			themes++
+		}
+	}
+	if themes != 2 {
+		t.Errorf("TestExport: expected the three categories to map to two themes, got %d", themes)
+	}
+
+	// the URL of the resource in the test file of ogdatv23 is relative
+	if _, err := Export(readfile(t, "../ogdatv23/testfiles/fullandok.json"), DefaultBaseURI); err == nil {
+		t.Errorf("TestExport: expected error for a resource without absolute URL")
+	}
+}
+
+func TestWrite(t *testing.T) {
+	g := exportfile(t, "testfiles/fullandok.json")
+
+	var buf bytes.Buffer
+	if err := g.Write(&buf, Turtle); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `dct:title "Informationen über ACME county"@de`) {
+		t.Errorf("TestWrite: unexpected Turtle:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := g.Write(&buf, RDFXML); err != nil {
+		t.Fatal(err)
+	}
+	dec := xml.NewDecoder(&buf)
+	for {
+		if _, err := dec.Token(); err != nil {
+			if err != io.EOF {
+				t.Errorf("TestWrite: invalid RDF/XML: %s", err)
+			}
+			break
+		}
+	}
+
+	buf.Reset()
+	if err := g.Write(&buf, JSONLD); err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Graph []map[string]interface{} `json:"@graph"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("TestWrite: invalid JSON-LD: %s", err)
+	}
+	if subjects, _ := g.subjects(); len(doc.Graph) != len(subjects) {
+		t.Errorf("TestWrite: expected %d nodes in JSON-LD, got %d", len(subjects), len(doc.Graph))
+	}
+
+	if err := g.Write(&buf, Format("n3")); err == nil {
+		t.Errorf("TestWrite: expected error for unsupported format")
+	}
+}
+
+func TestImport(t *testing.T) {
+	g := exportfile(t, "testfiles/fullandok.json")
+
+	for _, format := range Formats {
+		var buf bytes.Buffer
+		if err := g.Write(&buf, format); err != nil {
+			t.Fatal(err)
+		}
+		parsed, err := Parse(&buf, format, "")
+		if err != nil {
+			t.Fatalf("TestImport [%s]: %s", format, err)
+		}
+		if len(parsed.Triples) != len(g.Triples) {
+			t.Errorf("TestImport [%s]: expected %d triples, got %d", format, len(g.Triples), len(parsed.Triples))
+		}
+		docs, err := Import(parsed)
+		if err != nil {
+			t.Fatalf("TestImport [%s]: %s", format, err)
+		}
+		if len(docs) != 1 {
+			t.Fatalf("TestImport [%s]: expected one dataset, got %d", format, len(docs))
+		}
+		md, err := ogdatv23.MetadatafromJSONStream(bytes.NewReader(docs[0].Data))
+		if err != nil {
+			t.Fatalf("TestImport [%s]: %s", format, err)
+		}
+		if md.Title == nil || *md.Title != "Informationen über ACME county" {
+			t.Errorf("TestImport [%s]: unexpected title %v", format, md.Title)
+		}
+		if md.Update_Frequency == nil || md.Update_Frequency.MD_MaintenanceFrequencyCode != ogdat.CycMonthly.MD_MaintenanceFrequencyCode {
+			t.Errorf("TestImport [%s]: unexpected update frequency %v", format, md.Update_Frequency)
+		}
+		if md.Schema_Language == nil || *md.Schema_Language != "ger" {
+			t.Errorf("TestImport [%s]: unexpected schema language %v", format, md.Schema_Language)
+		}
+		if len(md.Resource) != 1 || md.Resource[0].Format == nil || *md.Resource[0].Format != "csv" {
+			t.Errorf("TestImport [%s]: unexpected resources %v", format, md.Resource)
+		}
+		if md.Categorization == nil || len(md.Categorization.Kategorie) != 2 {
+			t.Errorf("TestImport [%s]: expected two categories from two themes, got %v", format, md.Categorization)
+		}
+		messages, err := md.Check(false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, msg := range messages {
+			if msg.Type&ogdat.Error != 0 {
+				t.Errorf("TestImport [%s]: unexpected error %v", format, msg)
+			}
+		}
+	}
+}
+
+func TestParseTurtle(t *testing.T) {
+	const src = `@prefix dcat: <http://www.w3.org/ns/dcat#> .
+PREFIX dct: <http://purl.org/dc/terms/>
+@base <https://example.org/> .
+
+<ds/1> a dcat:Dataset ;
+    dct:title "Öffentliche \"Toiletten\""@DE, 'public toilets'@en ;
+    dcat:keyword """Infrastruktur""" , "Wien" ;
+    dcat:theme <http://publications.europa.eu/resource/authority/data-theme/HEAL> ;
+    dct:spatial [ a dct:Location ; dcat:bbox "POLYGON((16 48, 17 48, 17 49, 16 49, 16 48))" ] ;
+    dcat:distribution _:d1 .
+_:d1 dcat:accessURL <toiletten.csv> ; dct:format "CSV" ; dcat:byteSize 1024 ;
+    <http://example.org/list> ( 1 2.5 true ) .
+`
+	g, err := Parse(strings.NewReader(src), Turtle, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	docs, err := Import(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 1 || docs[0].Subject != "https://example.org/ds/1" {
+		t.Fatalf("TestParseTurtle: unexpected datasets %v", docs)
+	}
+	var doc struct {
+		Title     string
+		Tags      []string
+		Extras    map[string]interface{}
+		Resources []map[string]string
+	}
+	if err := json.Unmarshal(docs[0].Data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Title != `Öffentliche "Toiletten"` {
+		t.Errorf("TestParseTurtle: unexpected title '%s'", doc.Title)
+	}
+	if len(doc.Tags) != 2 {
+		t.Errorf("TestParseTurtle: unexpected tags %v", doc.Tags)
+	}
+	if cats, ok := doc.Extras["categorization"].([]interface{}); !ok || len(cats) != 1 || cats[0] != ogdat.Gesundheit.ID {
+		t.Errorf("TestParseTurtle: unexpected categories %v", doc.Extras["categorization"])
+	}
+	if doc.Extras["geographic_bbox"] != "POLYGON((16 48, 17 48, 17 49, 16 49, 16 48))" {
+		t.Errorf("TestParseTurtle: unexpected bbox %v", doc.Extras["geographic_bbox"])
+	}
+	if len(doc.Resources) != 1 || doc.Resources[0]["url"] != "https://example.org/toiletten.csv" || doc.Resources[0]["size"] != "1024" {
+		t.Errorf("TestParseTurtle: unexpected resources %v", doc.Resources)
+	}
+
+	if _, err := Parse(strings.NewReader(`<a> <b> "unterminated .`), Turtle, ""); err == nil {
+		t.Errorf("TestParseTurtle: expected error for unterminated literal")
+	}
+}
@@ -0,0 +1,451 @@
+// Package dcat converts OGD metadata to and from DCAT-AP.at, the Austrian
+// application profile of the Data Catalog Vocabulary, as used to feed
+// metadata into European data portals.
+package dcat
+
+import (
+	"fmt"
+	"github.com/the42/ogdat"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DefaultBaseURI prefixes the metadata_identifier to form the IRI of an exported dataset
+const DefaultBaseURI = "https://www.data.gv.at/katalog/dataset/"
+
+const (
+	frequencyvocabulary = "http://publications.europa.eu/resource/authority/frequency/"
+	filetypevocabulary  = "http://publications.europa.eu/resource/authority/file-type/"
+	languagevocabulary  = "http://publications.europa.eu/resource/authority/language/"
+)
+
+// OGD IDs of the fields, identical in all versions of the specification
+const (
+	idMetadataIdentifier   = 1
+	idSchemaName           = 2
+	idSchemaLanguage       = 3
+	idSchemaCharacterset   = 4
+	idMetadataModified     = 5
+	idMetadataLinkage      = 6
+	idTitle                = 8
+	idDescription          = 9
+	idCategorization       = 10
+	idKeywords             = 11
+	idAttributeDescription = 12
+	idMaintainerLink       = 13
+	idResourceURL          = 14
+	idResourceFormat       = 15
+	idResourceName         = 16
+	idResourceCreated      = 17
+	idResourceLastModified = 18
+	idMaintainer           = 19
+	idPublisher            = 20
+	idLicense              = 21
+	idGeographicToponym    = 22
+	idGeographicBBox       = 23
+	idBeginDateTime        = 24
+	idEndDateTime          = 25
+	idUpdateFrequency      = 26
+	idLineageQuality       = 27
+	idEnTitleDesc          = 28
+	idResourceSize         = 29
+	idLicenseCitation      = 30
+	idResourceLanguage     = 31
+	idResourceEncoding     = 32
+	idOriginalPortal       = 33
+	idMaintainerEmail      = 34
+)
+
+// cycle codes of ON/EN/ISO 19115:2003 mapped to the EU frequency vocabulary
+var frequencies = map[string]string{
+	ogdat.CycCont.MD_MaintenanceFrequencyCode:     "CONT",
+	ogdat.CycDaily.MD_MaintenanceFrequencyCode:    "DAILY",
+	ogdat.CycWeekly.MD_MaintenanceFrequencyCode:   "WEEKLY",
+	ogdat.CycFortNly.MD_MaintenanceFrequencyCode:  "BIWEEKLY",
+	ogdat.CycMonthly.MD_MaintenanceFrequencyCode:  "MONTHLY",
+	ogdat.CycQuart.MD_MaintenanceFrequencyCode:    "QUARTERLY",
+	ogdat.CycBiAnn.MD_MaintenanceFrequencyCode:    "ANNUAL_2",
+	ogdat.CycAnnually.MD_MaintenanceFrequencyCode: "ANNUAL",
+	ogdat.CycNeeded.MD_MaintenanceFrequencyCode:   "IRREG",
+	ogdat.CycIrreg.MD_MaintenanceFrequencyCode:    "IRREG",
+	ogdat.CycNP.MD_MaintenanceFrequencyCode:       "NEVER",
+	ogdat.CycUnknown.MD_MaintenanceFrequencyCode:  "UNKNOWN",
+}
+
+// the EU language vocabulary uses the terminology codes of ISO 639-2 where
+// they differ from the bibliographic codes used by OGD
+var terminologycodes = map[string]string{
+	"alb": "sqi", "arm": "hye", "baq": "eus", "bur": "mya", "chi": "zho",
+	"cze": "ces", "dut": "nld", "fre": "fra", "geo": "kat", "ger": "deu",
+	"gre": "ell", "ice": "isl", "mac": "mkd", "mao": "mri", "may": "msa",
+	"per": "fas", "rum": "ron", "slo": "slk", "tib": "bod", "wel": "cym",
+}
+
+// the predicates DCAT-AP.at uses where it deliberately differs from the RDF
+// property given by the specification
+var dcatapat = map[int]string{
+	// the specification names the schema an alternative title of the dataset,
+	// DCAT-AP.at refers to the metadata standard by dct:conformsTo
+	idSchemaName: "dct:conformsTo",
+	// dcat:dataDictionary, dcat:dataQuality and dcat:bytes are no terms of DCAT
+	idMetadataLinkage: "foaf:page",
+	idLineageQuality:  "dct:provenance",
+	idResourceSize:    "dcat:byteSize",
+	// DCAT-AP requires dct:description for datasets and knows no dct:abstract
+	idDescription: "dct:description",
+	idEnTitleDesc: "dct:description",
+	// dct:description holds the description already
+	idAttributeDescription: "rdfs:comment",
+	// rdfs:literal is a class, not a property
+	idResourceName: "dct:title",
+	// DCAT-AP describes contact and publisher as vcard:Kind and foaf:Agent
+	// instead of the creator and publisher of Dublin Core. maintainer_link
+	// and maintainer_email become properties of the contact.
+	idMaintainer: "dcat:contactPoint",
+	idPublisher:  "dct:publisher",
+}
+
+// rdfproperty matches the prefixed name at the start of the RDF property
+// column, as in "dcterms:title (mit language tag "de")"
+var rdfproperty = regexp.MustCompile(`^\W*([a-z]+):([A-Za-z]+)`)
+
+// Export converts md, a parsed MetaData of one of the ogdatv2x packages, into
+// a DCAT-AP.at graph of a dcat:Dataset with a dcat:Distribution per resource.
+// The predicates are taken from the RDF property of the specification of md,
+// except where DCAT-AP.at differs. The IRI of the dataset is baseuri followed
+// by the metadata_identifier, the dataset is a blank node if there is no
+// metadata_identifier. Resources without absolute URL can't be exported.
+func Export(md ogdat.Metadater, baseuri string) (*Graph, error) {
+	if reflect.ValueOf(md).Kind() != reflect.Ptr || reflect.ValueOf(md).IsNil() {
+		return nil, fmt.Errorf("Keine Metadaten zum Exportieren")
+	}
+	spec, err := specfor(md)
+	if err != nil {
+		return nil, err
+	}
+	g := &Graph{}
+	e := &exporter{g: g, spec: spec}
+
+	var dataset Term
+	id := e.text(md, idMetadataIdentifier)
+	if id != "" {
+		dataset = NewIRI(baseuri + url.PathEscape(id))
+	} else {
+		dataset = g.NewBlankNode()
+	}
+	g.Add(dataset, "rdf:type", NewIRI("dcat:Dataset"))
+	e.literal(dataset, md, idMetadataIdentifier)
+
+	e.langliteral(dataset, md, idTitle, "de")
+	e.langliteral(dataset, md, idDescription, "de")
+	e.langliteral(dataset, md, idEnTitleDesc, "en")
+
+	if cats, ok := e.field(md, idCategorization).(*ogdat.MetaDataKategorie); ok && cats != nil {
+		for _, cat := range cats.Kategorie {
+			if cat.RDFProperty != "" {
+				e.add(dataset, idCategorization, NewIRI(cat.RDFProperty))
+			}
+		}
+	}
+	if tags, ok := e.field(md, idKeywords).([]ogdat.Tags); ok {
+		for _, tag := range tags {
+			e.add(dataset, idKeywords, NewLangLiteral(string(tag), "de"))
+		}
+	}
+
+	e.time(dataset, e.predicate(idMetadataModified), md, idMetadataModified)
+	e.language(dataset, md, idSchemaLanguage)
+	e.literal(dataset, md, idSchemaCharacterset)
+	if schema := e.text(md, idSchemaName); schema != "" {
+		standard := g.NewBlankNode()
+		e.add(dataset, idSchemaName, standard)
+		g.Add(standard, "rdf:type", NewIRI("dct:Standard"))
+		g.Add(standard, "rdfs:label", NewLiteral(schema))
+	}
+	if linkage, ok := e.field(md, idMetadataLinkage).(*ogdat.MetaDataLinkage); ok && linkage != nil {
+		for idx := range linkage.Url {
+			e.link(dataset, e.predicate(idMetadataLinkage), &linkage.Url[idx])
+		}
+	}
+	e.langliteral(dataset, md, idAttributeDescription, "de")
+
+	if maintainer := e.text(md, idMaintainer); maintainer != "" || e.text(md, idMaintainerEmail) != "" || e.text(md, idMaintainerLink) != "" {
+		contact := g.NewBlankNode()
+		e.add(dataset, idMaintainer, contact)
+		g.Add(contact, "rdf:type", NewIRI("vcard:Organization"))
+		if maintainer != "" {
+			g.Add(contact, "vcard:fn", NewLiteral(maintainer))
+		}
+		if email, ok := e.field(md, idMaintainerEmail).(*ogdat.Url); ok && email != nil && email.Raw != "" {
+			address := email.Raw
+			if !strings.HasPrefix(address, "mailto:") {
+				address = "mailto:" + address
+			}
+			g.Add(contact, "vcard:hasEmail", NewIRI(address))
+		}
+		if link, ok := e.field(md, idMaintainerLink).(*ogdat.Url); ok {
+			e.link(contact, "vcard:hasURL", link)
+		}
+	}
+
+	if publisher := e.text(md, idPublisher); publisher != "" {
+		agent := g.NewBlankNode()
+		e.add(dataset, idPublisher, agent)
+		g.Add(agent, "rdf:type", NewIRI("foaf:Agent"))
+		g.Add(agent, "foaf:name", NewLiteral(publisher))
+	}
+
+	// the toponym names the dct:Location of geographic_bbox instead of describing the dataset
+	if toponym, bbox := e.text(md, idGeographicToponym), e.text(md, idGeographicBBox); toponym != "" || bbox != "" {
+		location := g.NewBlankNode()
+		e.add(dataset, idGeographicBBox, location)
+		g.Add(location, "rdf:type", NewIRI("dct:Location"))
+		if toponym != "" {
+			g.Add(location, "skos:prefLabel", NewLangLiteral(toponym, "de"))
+		}
+		if bbox != "" {
+			g.Add(location, "dcat:bbox", NewTypedLiteral(bbox, "gsp:wktLiteral"))
+		}
+	}
+
+	if e.text(md, idBeginDateTime) != "" || e.text(md, idEndDateTime) != "" {
+		period := g.NewBlankNode()
+		e.add(dataset, idBeginDateTime, period)
+		g.Add(period, "rdf:type", NewIRI("dct:PeriodOfTime"))
+		e.time(period, "dcat:startDate", md, idBeginDateTime)
+		e.time(period, "dcat:endDate", md, idEndDateTime)
+	}
+
+	if cyc, ok := e.field(md, idUpdateFrequency).(*ogdat.Cycle); ok && cyc != nil {
+		if frequency, ok := frequencies[cyc.MD_MaintenanceFrequencyCode]; ok {
+			e.add(dataset, idUpdateFrequency, NewIRI(frequencyvocabulary+frequency))
+		}
+	}
+
+	if lineage := e.text(md, idLineageQuality); lineage != "" {
+		provenance := g.NewBlankNode()
+		e.add(dataset, idLineageQuality, provenance)
+		g.Add(provenance, "rdf:type", NewIRI("dct:ProvenanceStatement"))
+		g.Add(provenance, "rdfs:label", NewLangLiteral(lineage, "de"))
+	}
+	e.literal(dataset, md, idLicenseCitation)
+	if portal, ok := e.field(md, idOriginalPortal).(*ogdat.Url); ok {
+		e.link(dataset, e.predicate(idOriginalPortal), portal)
+	}
+
+	// the license of the metadata set applies to all its resources, known
+	// licenses given by name are referred to by their URI
+	var license *Term
+	if raw := e.text(md, idLicense); raw != "" {
+		if !ogdat.IsLicenseURI(raw) {
+			reg, err := ogdat.Licenses()
+			if err != nil {
+				return nil, err
+			}
+			if known, ok := reg.Lookup(raw); ok {
+				raw = known.URI
+			}
+		}
+		if u, err := url.Parse(raw); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+			iri := Term{Kind: IRI, Value: u.String()}
+			license = &iri
+		} else {
+			doc := g.NewBlankNode()
+			g.Add(doc, "rdf:type", NewIRI("dct:LicenseDocument"))
+			g.Add(doc, "rdfs:label", NewLiteral(raw))
+			license = &doc
+		}
+	}
+
+	for idx, res := range resources(md) {
+		// dcat:accessURL is mandatory for a dcat:Distribution
+		link, _ := e.field(res, idResourceURL).(*ogdat.Url)
+		if link == nil || link.URL == nil || !link.URL.IsAbs() {
+			return nil, fmt.Errorf("Ressource %d hat keine absolute URL und kann nicht als dcat:Distribution exportiert werden: '%s'", idx, e.text(res, idResourceURL))
+		}
+
+		distribution := g.NewBlankNode()
+		if dataset.Kind == IRI {
+			distribution = NewIRI(fmt.Sprintf("%s/resource/%d", dataset.Value, idx))
+		}
+		g.Add(dataset, "dcat:distribution", distribution)
+		g.Add(distribution, "rdf:type", NewIRI("dcat:Distribution"))
+		e.link(distribution, e.predicate(idResourceURL), link)
+		if format := strings.ToUpper(strings.TrimLeft(e.text(res, idResourceFormat), ".")); format != "" {
+			if islocalname(format) {
+				e.add(distribution, idResourceFormat, NewIRI(filetypevocabulary+format))
+			} else {
+				e.add(distribution, idResourceFormat, NewLiteral(format))
+			}
+		}
+		e.langliteral(distribution, res, idResourceName, "de")
+		e.time(distribution, e.predicate(idResourceCreated), res, idResourceCreated)
+		e.time(distribution, e.predicate(idResourceLastModified), res, idResourceLastModified)
+		if size := e.text(res, idResourceSize); size != "" {
+			if _, err := strconv.ParseUint(size, 10, 64); err == nil {
+				e.add(distribution, idResourceSize, NewTypedLiteral(size, "xsd:nonNegativeInteger"))
+			}
+		}
+		e.language(distribution, res, idResourceLanguage)
+		e.literal(distribution, res, idResourceEncoding)
+		if license != nil {
+			e.add(distribution, idLicense, *license)
+		}
+	}
+	return g, nil
+}
+
+// specfor returns the specification of the version md was parsed for
+func specfor(md ogdat.Metadater) (*ogdat.OGDSet, error) {
+	for _, f := range ogdat.MetadataFactories() {
+		if f.New != nil && reflect.TypeOf(f.New()) == reflect.TypeOf(md) {
+			return f.Spec, nil
+		}
+	}
+	return nil, fmt.Errorf("Keine Spezifikation für Metadaten vom Typ %T registriert", md)
+}
+
+type exporter struct {
+	g    *Graph
+	spec *ogdat.OGDSet
+}
+
+// predicate returns the predicate of the field id, "" if the specification
+// names none in a namespace of the export
+func (e *exporter) predicate(id int) string {
+	if predicate, ok := dcatapat[id]; ok {
+		return predicate
+	}
+	desc, _ := e.spec.GetBeschreibungForID(id)
+	if desc == nil {
+		return ""
+	}
+	match := rdfproperty.FindStringSubmatch(desc.RDFProperty)
+	if match == nil {
+		return ""
+	}
+	prefix := match[1]
+	if prefix == "dcterms" {
+		prefix = "dct"
+	}
+	if predicate := prefix + ":" + match[2]; expand(predicate) != predicate {
+		return predicate
+	}
+	return ""
+}
+
+// add adds object with the predicate of the field id
+func (e *exporter) add(subject Term, id int, object Term) {
+	if predicate := e.predicate(id); predicate != "" {
+		e.g.Add(subject, predicate, object)
+	}
+}
+
+// literal adds the field as plain literal
+func (e *exporter) literal(subject Term, md interface{}, id int) {
+	if text := e.text(md, id); text != "" {
+		e.add(subject, id, NewLiteral(text))
+	}
+}
+
+func (e *exporter) field(md interface{}, id int) interface{} {
+	val, _ := ogdat.FieldByID(md, id)
+	return val
+}
+
+// text returns the field as given in the document, "" if not present
+func (e *exporter) text(md interface{}, id int) string {
+	switch val := e.field(md, id).(type) {
+	case *string:
+		if val != nil {
+			return strings.TrimSpace(*val)
+		}
+	case *ogdat.ResourceSpecifier:
+		if val != nil {
+			return strings.TrimSpace(string(*val))
+		}
+	case *ogdat.Url:
+		if val != nil {
+			return val.Raw
+		}
+	case *ogdat.Identifier:
+		if val != nil {
+			return val.Raw
+		}
+	case *ogdat.Time:
+		if val != nil {
+			return val.Raw
+		}
+	}
+	return ""
+}
+
+func (e *exporter) langliteral(subject Term, md interface{}, id int, lang string) {
+	if text := e.text(md, id); text != "" {
+		e.add(subject, id, NewLangLiteral(text, lang))
+	}
+}
+
+// time adds the field as xsd:date or xsd:dateTime, depending on the format
+// given. Times not given in any of ogdat.TimeFormat are added as plain literal.
+func (e *exporter) time(subject Term, predicate string, md interface{}, id int) {
+	t, ok := e.field(md, id).(*ogdat.Time)
+	if !ok || t == nil || t.Raw == "" || predicate == "" {
+		return
+	}
+	switch t.Format {
+	case "":
+		e.g.Add(subject, predicate, NewLiteral(t.Raw))
+	case ogdat.CustomTimeSpecifier2:
+		e.g.Add(subject, predicate, NewTypedLiteral(t.Raw, "xsd:date"))
+	default:
+		e.g.Add(subject, predicate, NewTypedLiteral(t.Raw, "xsd:dateTime"))
+	}
+}
+
+// link adds u if it is an absolute URL
+func (e *exporter) link(subject Term, predicate string, u *ogdat.Url) {
+	if u == nil || u.URL == nil || !u.URL.IsAbs() || predicate == "" {
+		return
+	}
+	e.g.Add(subject, predicate, Term{Kind: IRI, Value: u.URL.String()})
+}
+
+// language adds the ISO 639-2 code of the field as IRI of the EU language vocabulary
+func (e *exporter) language(subject Term, md interface{}, id int) {
+	lang := strings.ToLower(e.text(md, id))
+	if len(lang) != 3 {
+		return
+	}
+	if code, ok := terminologycodes[lang]; ok {
+		lang = code
+	}
+	e.add(subject, id, NewIRI(languagevocabulary+strings.ToUpper(lang)))
+}
+
+// resources returns the resources of md, the elements of the field tagged as json:"resources"
+func resources(md interface{}) (res []interface{}) {
+	val := reflect.ValueOf(md)
+	for val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			return nil
+		}
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Struct {
+		return nil
+	}
+	for i := 0; i < val.NumField(); i++ {
+		f := val.Type().Field(i)
+		if strings.Split(f.Tag.Get("json"), ",")[0] != "resources" || f.Type.Kind() != reflect.Slice {
+			continue
+		}
+		for j := 0; j < val.Field(i).Len(); j++ {
+			res = append(res, val.Field(i).Index(j).Addr().Interface())
+		}
+	}
+	return res
+}
@@ -0,0 +1,172 @@
+package dcat
+
+import (
+	"fmt"
+	"strings"
+)
+
+// namespaces used by the export, in the order they are declared
+var namespaces = []struct {
+	prefix, uri string
+}{
+	{"rdf", "http://www.w3.org/1999/02/22-rdf-syntax-ns#"},
+	{"rdfs", "http://www.w3.org/2000/01/rdf-schema#"},
+	{"xsd", "http://www.w3.org/2001/XMLSchema#"},
+	{"dcat", "http://www.w3.org/ns/dcat#"},
+	{"dct", "http://purl.org/dc/terms/"},
+	{"foaf", "http://xmlns.com/foaf/0.1/"},
+	{"vcard", "http://www.w3.org/2006/vcard/ns#"},
+	{"skos", "http://www.w3.org/2004/02/skos/core#"},
+	{"cnt", "http://www.w3.org/2011/content#"},
+	{"cc", "http://creativecommons.org/ns#"},
+	{"gsp", "http://www.opengis.net/ont/geosparql#"},
+}
+
+// expand turns a prefixed name as "dct:title" into a full IRI. Full IRIs are returned unaltered.
+func expand(name string) string {
+	if idx := strings.Index(name, ":"); idx > 0 {
+		for _, ns := range namespaces {
+			if ns.prefix == name[:idx] {
+				return ns.uri + name[idx+1:]
+			}
+		}
+	}
+	return name
+}
+
+// compact is the inverse of expand. ok is false if iri can not be written as a prefixed name.
+func compact(iri string) (name string, ok bool) {
+	for _, ns := range namespaces {
+		if strings.HasPrefix(iri, ns.uri) {
+			local := iri[len(ns.uri):]
+			if islocalname(local) {
+				return ns.prefix + ":" + local, true
+			}
+		}
+	}
+	return iri, false
+}
+
+func islocalname(local string) bool {
+	if local == "" {
+		return false
+	}
+	for idx, r := range local {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_':
+		case idx > 0 && (r >= '0' && r <= '9' || r == '-'):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// TermKind distinguishes the kinds of RDF terms
+type TermKind int
+
+const (
+	IRI TermKind = iota
+	BlankNode
+	Literal
+)
+
+// Term is an RDF term: an IRI, a blank node or a literal, optionally with
+// language tag or datatype
+type Term struct {
+	Kind     TermKind
+	Value    string // the IRI, the label of the blank node or the lexical form of the literal
+	Lang     string
+	Datatype string // IRI of the datatype of a literal, plain string if empty
+}
+
+func NewIRI(iri string) Term {
+	return Term{Kind: IRI, Value: expand(iri)}
+}
+
+func NewLiteral(value string) Term {
+	return Term{Kind: Literal, Value: value}
+}
+
+func NewLangLiteral(value, lang string) Term {
+	return Term{Kind: Literal, Value: value, Lang: lang}
+}
+
+func NewTypedLiteral(value, datatype string) Term {
+	return Term{Kind: Literal, Value: value, Datatype: expand(datatype)}
+}
+
+func (t Term) String() string {
+	switch t.Kind {
+	case IRI:
+		return "<" + t.Value + ">"
+	case BlankNode:
+		return "_:" + t.Value
+	}
+	str := `"` + turtleescape(t.Value) + `"`
+	if t.Lang != "" {
+		return str + "@" + t.Lang
+	}
+	if t.Datatype != "" {
+		return str + "^^<" + t.Datatype + ">"
+	}
+	return str
+}
+
+// Triple is a single statement of a Graph
+type Triple struct {
+	Subject, Predicate, Object Term
+}
+
+// Graph holds the triples of an export in the order they were added
+type Graph struct {
+	Triples []Triple
+	blanks  int
+	seen    map[Triple]bool
+}
+
+// NewBlankNode returns a blank node with a label unique in g
+func (g *Graph) NewBlankNode() Term {
+	g.blanks++
+	return Term{Kind: BlankNode, Value: fmt.Sprintf("b%d", g.blanks)}
+}
+
+// Add adds the triple subject predicate object to g, unless g already holds
+// it. predicate may be given as prefixed name.
+func (g *Graph) Add(subject Term, predicate string, object Term) {
+	t := Triple{Subject: subject, Predicate: NewIRI(predicate), Object: object}
+	if g.seen == nil {
+		g.seen = make(map[Triple]bool)
+	}
+	if g.seen[t] {
+		return
+	}
+	g.seen[t] = true
+	g.Triples = append(g.Triples, t)
+}
+
+// subjects returns the subjects of g in the order of their first appearance,
+// with the triples of each subject
+func (g *Graph) subjects() ([]Term, map[Term][]Triple) {
+	var order []Term
+	bysubject := make(map[Term][]Triple)
+	for _, t := range g.Triples {
+		if _, ok := bysubject[t.Subject]; !ok {
+			order = append(order, t.Subject)
+		}
+		bysubject[t.Subject] = append(bysubject[t.Subject], t)
+	}
+	return order, bysubject
+}
+
+// list adds items as RDF collection and returns its head, rdf:nil if items is empty
+func (g *Graph) list(items []Term) Term {
+	head := NewIRI("rdf:nil")
+	for idx := len(items) - 1; idx >= 0; idx-- {
+		node := g.NewBlankNode()
+		g.Add(node, "rdf:first", items[idx])
+		g.Add(node, "rdf:rest", head)
+		head = node
+	}
+	return head
+}
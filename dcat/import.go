@@ -0,0 +1,360 @@
+package dcat
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/the42/ogdat"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+// Parse reads an RDF graph in format from r. Relative IRIs are resolved against base.
+func Parse(r io.Reader, format Format, base string) (*Graph, error) {
+	switch format {
+	case Turtle:
+		data, err := ioutil.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		return parseturtle(string(data), base)
+	case RDFXML:
+		return parserdfxml(r, base)
+	case JSONLD:
+		return parsejsonld(r, base)
+	}
+	return nil, fmt.Errorf("Nicht unterstütztes RDF-Format: '%s'", format)
+}
+
+// Document is a dcat:Dataset converted into a CKAN JSON metadata document
+type Document struct {
+	Subject string // IRI of the dataset, or label of the blank node
+	Data    []byte
+}
+
+// prefixes used by the RDF property column of the specification
+var specprefixes = map[string]string{
+	"dcterms":    "http://purl.org/dc/terms/",
+	"dcelements": "http://purl.org/dc/elements/1.1/",
+	"adms":       "http://www.w3.org/ns/adms#",
+}
+
+// specproperty returns the IRI of the RDF property of the specification, as
+// e.g. 'dcterms:title (mit language tag "de")', "" if there is none
+func specproperty(property string) string {
+	fields := strings.Fields(strings.Trim(property, `"`))
+	if len(fields) == 0 {
+		return ""
+	}
+	name := fields[0]
+	idx := strings.Index(name, ":")
+	if idx < 1 {
+		return ""
+	}
+	if ns, ok := specprefixes[name[:idx]]; ok {
+		return ns + name[idx+1:]
+	}
+	if iri := expand(name); iri != name {
+		return iri
+	}
+	return ""
+}
+
+// fields of the CKAN document which hold an array, not a string
+var arrayfields = map[string]bool{
+	"tags":                    true,
+	"extras:categorization":   true,
+	"extras:metadata_linkage": true,
+}
+
+type importer struct {
+	bysubject map[Term][]Triple
+}
+
+// objects returns the objects of subject for predicate, given as IRI or prefixed name
+func (im *importer) objects(subject Term, predicate string) (objects []Term) {
+	iri := expand(predicate)
+	for _, t := range im.bysubject[subject] {
+		if t.Predicate.Value == iri {
+			objects = append(objects, t.Object)
+		}
+	}
+	return
+}
+
+// first returns the first object of subject for predicate, preferring
+// literals tagged as German, then untagged ones
+func (im *importer) first(subject Term, predicate string) (Term, bool) {
+	objects := im.objects(subject, predicate)
+	if len(objects) == 0 {
+		return Term{}, false
+	}
+	for _, lang := range []string{"de", ""} {
+		for _, o := range objects {
+			if o.Kind == Literal && strings.HasPrefix(strings.ToLower(o.Lang), lang) && (lang != "" || o.Lang == "") {
+				return o, true
+			}
+		}
+	}
+	return objects[0], true
+}
+
+// text returns the first object of subject for predicate as string. For blank
+// nodes the first of labels of the node is returned.
+func (im *importer) text(subject Term, predicate string, labels ...string) string {
+	o, ok := im.first(subject, predicate)
+	if !ok {
+		return ""
+	}
+	if o.Kind != BlankNode {
+		return strings.TrimSpace(o.Value)
+	}
+	for _, label := range labels {
+		if text := im.text(o, label); text != "" {
+			return text
+		}
+	}
+	return ""
+}
+
+// lasttoken returns the last path segment or fragment of an IRI
+func lasttoken(iri string) string {
+	if idx := strings.LastIndexAny(iri, "/#"); idx > -1 {
+		return iri[idx+1:]
+	}
+	return iri
+}
+
+// Import converts every dcat:Dataset of g into a CKAN JSON metadata document,
+// which can be checked as any other document. Properties of DCAT-AP.at as
+// written by Export are read, then literal properties named in the RDF
+// property column of the registered specifications fill the fields not set yet.
+// Categories are taken from the themes: a theme whose IRI ends in the ID of an
+// OGD category is mapped to that category, an EU data theme to the first category
+// mapped to it by Kategorie.RDFProperty.
+func Import(g *Graph) ([]Document, error) {
+	order, bysubject := g.subjects()
+	im := &importer{bysubject: bysubject}
+
+	var docs []Document
+	for _, subject := range order {
+		isdataset := false
+		for _, typ := range im.objects(subject, "rdf:type") {
+			if typ.Value == expand("dcat:Dataset") {
+				isdataset = true
+			}
+		}
+		if !isdataset {
+			continue
+		}
+		data, err := im.dataset(subject)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, Document{Subject: subject.Value, Data: data})
+	}
+	return docs, nil
+}
+
+func (im *importer) dataset(dataset Term) ([]byte, error) {
+	doc := make(map[string]interface{})
+	extras := make(map[string]interface{})
+	set := func(m map[string]interface{}, key, value string) {
+		if _, ok := m[key]; !ok && value != "" {
+			m[key] = value
+		}
+	}
+
+	set(extras, "metadata_identifier", im.text(dataset, "dct:identifier"))
+	if dataset.Kind == IRI {
+		set(extras, "metadata_identifier", dataset.Value)
+	}
+	set(doc, "title", im.text(dataset, "dct:title"))
+	for _, desc := range im.objects(dataset, "dct:description") {
+		if desc.Kind != Literal {
+			continue
+		}
+		if strings.HasPrefix(strings.ToLower(desc.Lang), "en") {
+			set(extras, "en_title_and_desc", desc.Value)
+		}
+	}
+	if desc, ok := im.first(dataset, "dct:description"); ok && !strings.HasPrefix(strings.ToLower(desc.Lang), "en") {
+		set(doc, "notes", desc.Value)
+	}
+
+	var cats []string
+	for _, theme := range im.objects(dataset, "dcat:theme") {
+		if id := category(theme.Value); id != "" {
+			cats = append(cats, id)
+		}
+	}
+	if len(cats) > 0 {
+		extras["categorization"] = cats
+	}
+	var tags []string
+	for _, keyword := range im.objects(dataset, "dcat:keyword") {
+		tags = append(tags, keyword.Value)
+	}
+	if len(tags) > 0 {
+		doc["tags"] = tags
+	}
+
+	set(extras, "metadata_modified", im.text(dataset, "dct:modified"))
+	set(extras, "metadata_modified", im.text(dataset, "dct:issued"))
+	set(extras, "schema_language", language(im.text(dataset, "dct:language")))
+	set(extras, "schema_characterset", im.text(dataset, "cnt:characterEncoding"))
+	set(extras, "schema_name", im.text(dataset, "dct:conformsTo", "rdfs:label", "dct:title"))
+	var linkage []string
+	for _, page := range im.objects(dataset, "foaf:page") {
+		if page.Kind == IRI {
+			linkage = append(linkage, page.Value)
+		}
+	}
+	if len(linkage) > 0 {
+		extras["metadata_linkage"] = linkage
+	}
+	set(extras, "attribute_description", im.text(dataset, "rdfs:comment"))
+
+	if contact, ok := im.first(dataset, "dcat:contactPoint"); ok {
+		if contact.Kind == Literal {
+			set(doc, "maintainer", contact.Value)
+		} else {
+			set(doc, "maintainer", im.text(contact, "vcard:fn"))
+			set(doc, "maintainer", im.text(contact, "vcard:organization-name"))
+			set(doc, "maintainer_email", strings.TrimPrefix(im.text(contact, "vcard:hasEmail"), "mailto:"))
+			set(extras, "maintainer_link", im.text(contact, "vcard:hasURL"))
+		}
+	}
+	set(extras, "publisher", im.text(dataset, "dct:publisher", "foaf:name", "vcard:fn", "rdfs:label"))
+
+	if location, ok := im.first(dataset, "dct:spatial"); ok && location.Kind != Literal {
+		set(extras, "geographic_toponym", im.text(location, "skos:prefLabel"))
+		set(extras, "geographic_toponym", im.text(location, "rdfs:label"))
+		set(extras, "geographic_bbox", im.text(location, "dcat:bbox"))
+	}
+	if period, ok := im.first(dataset, "dct:temporal"); ok && period.Kind != Literal {
+		set(extras, "begin_datetime", im.text(period, "dcat:startDate"))
+		set(extras, "end_datetime", im.text(period, "dcat:endDate"))
+	}
+	if frequency, ok := im.first(dataset, "dct:accrualPeriodicity"); ok {
+		set(extras, "update_frequency", cycle(frequency.Value))
+	}
+	set(extras, "lineage_quality", im.text(dataset, "dct:provenance", "rdfs:label"))
+	set(extras, "license_citation", im.text(dataset, "cc:attributionName"))
+	set(extras, "metadata_original_portal", im.text(dataset, "dcat:landingPage"))
+
+	set(doc, "license", im.license(dataset))
+	var resources []interface{}
+	for _, distribution := range im.objects(dataset, "dcat:distribution") {
+		res := make(map[string]interface{})
+		set(res, "url", im.text(distribution, "dcat:accessURL"))
+		set(res, "url", im.text(distribution, "dcat:downloadURL"))
+		if format, ok := im.first(distribution, "dct:format"); ok {
+			if format.Kind == IRI {
+				set(res, "format", strings.ToLower(lasttoken(format.Value)))
+			} else {
+				set(res, "format", im.text(distribution, "dct:format", "rdfs:label"))
+			}
+		}
+		set(res, "name", im.text(distribution, "dct:title"))
+		set(res, "created", im.text(distribution, "dct:issued"))
+		set(res, "last_modified", im.text(distribution, "dct:modified"))
+		set(res, "size", im.text(distribution, "dcat:byteSize"))
+		set(res, "language", language(im.text(distribution, "dct:language")))
+		set(res, "characterset", im.text(distribution, "cnt:characterEncoding"))
+		set(doc, "license", im.license(distribution))
+		im.specfields(distribution, "resources:", res)
+		resources = append(resources, res)
+	}
+	im.specfields(dataset, "", doc)
+	im.specfields(dataset, "extras:", extras)
+
+	if len(resources) > 0 {
+		doc["resources"] = resources
+	}
+	doc["extras"] = extras
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSpace(buf.Bytes()), nil
+}
+
+func (im *importer) license(subject Term) string {
+	return im.text(subject, "dct:license", "rdfs:label", "dct:title", "dct:identifier")
+}
+
+// specfields fills the string fields of the CKAN object m, whose CKAN fields
+// are prefixed by prefix, from the literal properties named by the specifications
+func (im *importer) specfields(subject Term, prefix string, m map[string]interface{}) {
+	for _, f := range ogdat.MetadataFactories() {
+		for _, b := range f.Spec.Beschreibung {
+			field := b.CKAN_Feld
+			if idx := strings.IndexRune(field, '['); idx > -1 {
+				field = field[:idx]
+			}
+			if arrayfields[field] || !strings.HasPrefix(field, prefix) {
+				continue
+			}
+			key := strings.TrimPrefix(field, prefix)
+			if strings.Contains(key, ":") {
+				continue
+			}
+			if _, ok := m[key]; ok {
+				continue
+			}
+			property := specproperty(b.RDFProperty)
+			if property == "" {
+				continue
+			}
+			if o, ok := im.first(subject, property); ok && o.Kind == Literal && o.Value != "" {
+				m[key] = o.Value
+			}
+		}
+	}
+}
+
+// category returns the ID of the OGD category for the theme IRI, "" if there is none
+func category(theme string) string {
+	token := lasttoken(theme)
+	for _, cat := range ogdat.Categories {
+		if strings.EqualFold(cat.ID, token) {
+			return cat.ID
+		}
+	}
+	for _, cat := range ogdat.Categories {
+		if cat.RDFProperty == theme {
+			return cat.ID
+		}
+	}
+	return ""
+}
+
+// cycle returns the ON/EN/ISO 19115:2003 code for an IRI of the EU frequency vocabulary
+func cycle(frequency string) string {
+	token := lasttoken(frequency)
+	if token == "IRREG" {
+		return ogdat.CycIrreg.MD_MaintenanceFrequencyCode
+	}
+	for code, eu := range frequencies {
+		if eu == token {
+			return code
+		}
+	}
+	return token
+}
+
+// language returns the ISO 639-2 bibliographic code for an IRI of the EU language vocabulary
+func language(iri string) string {
+	token := strings.ToLower(lasttoken(iri))
+	for bibliographic, terminology := range terminologycodes {
+		if token == terminology {
+			return bibliographic
+		}
+	}
+	return token
+}
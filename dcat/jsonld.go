@@ -0,0 +1,313 @@
+package dcat
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// jsonldterm is a term definition of a JSON-LD context
+type jsonldterm struct {
+	id       string
+	typ      string // "@id", "@vocab" or the IRI of a datatype
+	language *string
+}
+
+type jsonldcontext struct {
+	terms    map[string]jsonldterm
+	vocab    string
+	base     string
+	language string
+}
+
+func (ctx *jsonldcontext) clone() *jsonldcontext {
+	c := *ctx
+	c.terms = make(map[string]jsonldterm, len(ctx.terms))
+	for key, val := range ctx.terms {
+		c.terms[key] = val
+	}
+	return &c
+}
+
+// jsonldparser reads JSON-LD documents with embedded contexts. Remote
+// contexts, @reverse, @index and framing are not supported.
+type jsonldparser struct {
+	blanks map[string]Term
+	g      *Graph
+}
+
+func parsejsonld(r io.Reader, base string) (*Graph, error) {
+	var doc interface{}
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("JSON-LD: %s", err)
+	}
+	p := &jsonldparser{blanks: make(map[string]Term), g: &Graph{}}
+	ctx := &jsonldcontext{terms: make(map[string]jsonldterm), base: base}
+	if err := p.toplevel(doc, ctx); err != nil {
+		return nil, fmt.Errorf("JSON-LD: %s", err)
+	}
+	return p.g, nil
+}
+
+func (p *jsonldparser) toplevel(doc interface{}, ctx *jsonldcontext) error {
+	switch val := doc.(type) {
+	case []interface{}:
+		for _, item := range val {
+			if err := p.toplevel(item, ctx); err != nil {
+				return err
+			}
+		}
+		return nil
+	case map[string]interface{}:
+		if c, ok := val["@context"]; ok {
+			var err error
+			if ctx, err = ctx.with(c); err != nil {
+				return err
+			}
+		}
+		if graph, ok := val["@graph"]; ok {
+			for _, item := range asarray(graph) {
+				if node, ok := item.(map[string]interface{}); ok {
+					if _, err := p.node(node, ctx); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		}
+		_, err := p.node(val, ctx)
+		return err
+	}
+	return fmt.Errorf("Objekt oder Array erwartet")
+}
+
+func asarray(val interface{}) []interface{} {
+	if arr, ok := val.([]interface{}); ok {
+		return arr
+	}
+	return []interface{}{val}
+}
+
+// with returns ctx extended by the local context c
+func (ctx *jsonldcontext) with(c interface{}) (*jsonldcontext, error) {
+	ctx = ctx.clone()
+	for _, item := range asarray(c) {
+		def, ok := item.(map[string]interface{})
+		if !ok {
+			if item == nil {
+				ctx.terms = make(map[string]jsonldterm)
+				continue
+			}
+			return nil, fmt.Errorf("Externe Kontexte werden nicht unterstützt: %v", item)
+		}
+		if vocab, ok := def["@vocab"].(string); ok {
+			ctx.vocab = vocab
+		}
+		if base, ok := def["@base"].(string); ok {
+			ctx.base = resolve(ctx.base, base)
+		}
+		if lang, ok := def["@language"].(string); ok {
+			ctx.language = strings.ToLower(lang)
+		}
+		// prefixes first, as term definitions may use them
+		for key, val := range def {
+			if iri, ok := val.(string); ok && !strings.HasPrefix(key, "@") {
+				ctx.terms[key] = jsonldterm{id: iri}
+			}
+		}
+		for key, val := range def {
+			switch d := val.(type) {
+			case string:
+				if !strings.HasPrefix(key, "@") {
+					ctx.terms[key] = jsonldterm{id: ctx.expand(d, true)}
+				}
+			case map[string]interface{}:
+				term := jsonldterm{id: key}
+				if id, ok := d["@id"].(string); ok {
+					term.id = id
+				}
+				term.id = ctx.expand(term.id, true)
+				if typ, ok := d["@type"].(string); ok {
+					if typ == "@id" || typ == "@vocab" {
+						term.typ = typ
+					} else {
+						term.typ = ctx.expand(typ, true)
+					}
+				}
+				if lang, ok := d["@language"]; ok {
+					l, _ := lang.(string)
+					l = strings.ToLower(l)
+					term.language = &l
+				}
+				ctx.terms[key] = term
+			}
+		}
+	}
+	return ctx, nil
+}
+
+// expand turns a term, compact IRI or relative IRI into an IRI. Relative IRIs
+// are resolved against @vocab if vocab is set, against @base otherwise.
+func (ctx *jsonldcontext) expand(value string, vocab bool) string {
+	if strings.HasPrefix(value, "@") || strings.HasPrefix(value, "_:") {
+		return value
+	}
+	if vocab {
+		if term, ok := ctx.terms[value]; ok {
+			return term.id
+		}
+	}
+	if idx := strings.Index(value, ":"); idx > 0 {
+		prefix, suffix := value[:idx], value[idx+1:]
+		if strings.HasPrefix(suffix, "//") {
+			return value
+		}
+		if term, ok := ctx.terms[prefix]; ok {
+			return term.id + suffix
+		}
+		return value
+	}
+	if vocab && ctx.vocab != "" {
+		return ctx.vocab + value
+	}
+	return resolve(ctx.base, value)
+}
+
+func (p *jsonldparser) ref(value string, ctx *jsonldcontext, vocab bool) Term {
+	iri := ctx.expand(value, vocab)
+	if strings.HasPrefix(iri, "_:") {
+		if node, ok := p.blanks[iri]; ok {
+			return node
+		}
+		node := p.g.NewBlankNode()
+		p.blanks[iri] = node
+		return node
+	}
+	return Term{Kind: IRI, Value: iri}
+}
+
+// node adds the properties of the node object obj and returns the node
+func (p *jsonldparser) node(obj map[string]interface{}, ctx *jsonldcontext) (Term, error) {
+	if c, ok := obj["@context"]; ok {
+		var err error
+		if ctx, err = ctx.with(c); err != nil {
+			return Term{}, err
+		}
+	}
+	var subject Term
+	if id, ok := obj["@id"].(string); ok {
+		subject = p.ref(id, ctx, false)
+	} else {
+		subject = p.g.NewBlankNode()
+	}
+
+	if types, ok := obj["@type"]; ok {
+		for _, typ := range asarray(types) {
+			if t, ok := typ.(string); ok {
+				p.g.Add(subject, rdfns+"type", p.ref(t, ctx, true))
+			}
+		}
+	}
+	if graph, ok := obj["@graph"]; ok {
+		for _, item := range asarray(graph) {
+			if node, ok := item.(map[string]interface{}); ok {
+				if _, err := p.node(node, ctx); err != nil {
+					return subject, err
+				}
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if strings.HasPrefix(key, "@") {
+			continue
+		}
+		val := obj[key]
+		predicate := ctx.expand(key, true)
+		if !strings.Contains(predicate, ":") || strings.HasPrefix(predicate, "_:") {
+			continue // not mapped to an IRI, ignored by JSON-LD
+		}
+		term := ctx.terms[key]
+		for _, item := range asarray(val) {
+			objects, err := p.value(item, term, ctx)
+			if err != nil {
+				return subject, err
+			}
+			for _, object := range objects {
+				p.g.Add(subject, predicate, object)
+			}
+		}
+	}
+	return subject, nil
+}
+
+// value returns the objects the JSON value val of a property defined by term stands for
+func (p *jsonldparser) value(val interface{}, term jsonldterm, ctx *jsonldcontext) ([]Term, error) {
+	switch v := val.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		switch term.typ {
+		case "@id":
+			return []Term{p.ref(v, ctx, false)}, nil
+		case "@vocab":
+			return []Term{p.ref(v, ctx, true)}, nil
+		case "":
+			lang := ctx.language
+			if term.language != nil {
+				lang = *term.language
+			}
+			return []Term{NewLangLiteral(v, lang)}, nil
+		}
+		return []Term{{Kind: Literal, Value: v, Datatype: term.typ}}, nil
+	case json.Number:
+		if strings.ContainsAny(v.String(), ".eE") {
+			return []Term{NewTypedLiteral(v.String(), "xsd:double")}, nil
+		}
+		return []Term{NewTypedLiteral(v.String(), "xsd:integer")}, nil
+	case bool:
+		return []Term{NewTypedLiteral(fmt.Sprint(v), "xsd:boolean")}, nil
+	case []interface{}:
+		var objects []Term
+		for _, item := range v {
+			o, err := p.value(item, term, ctx)
+			if err != nil {
+				return nil, err
+			}
+			objects = append(objects, o...)
+		}
+		return objects, nil
+	case map[string]interface{}:
+		if value, ok := v["@value"]; ok {
+			lit := NewLiteral(fmt.Sprint(value))
+			if lang, ok := v["@language"].(string); ok {
+				lit.Lang = strings.ToLower(lang)
+			} else if typ, ok := v["@type"].(string); ok {
+				lit.Datatype = ctx.expand(typ, true)
+			}
+			return []Term{lit}, nil
+		}
+		if list, ok := v["@list"]; ok {
+			items, err := p.value(asarray(list), term, ctx)
+			if err != nil {
+				return nil, err
+			}
+			return []Term{p.g.list(items)}, nil
+		}
+		if set, ok := v["@set"]; ok {
+			return p.value(asarray(set), term, ctx)
+		}
+		node, err := p.node(v, ctx)
+		return []Term{node}, err
+	}
+	return nil, fmt.Errorf("Unerwarteter Wert: %v", val)
+}
@@ -0,0 +1,200 @@
+package dcat
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	rdfns = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+	xmlns = "http://www.w3.org/XML/1998/namespace"
+)
+
+// rdfxmlparser reads the RDF/XML serialisation: node elements, typed or as
+// rdf:Description, with property elements and property attributes, nested
+// node elements and rdf:parseType="Resource". Reification and containers
+// are not supported.
+type rdfxmlparser struct {
+	dec    *xml.Decoder
+	base   string
+	blanks map[string]Term
+	g      *Graph
+}
+
+func parserdfxml(r io.Reader, base string) (*Graph, error) {
+	p := &rdfxmlparser{dec: xml.NewDecoder(r), base: base, blanks: make(map[string]Term), g: &Graph{}}
+	for {
+		tok, err := p.dec.Token()
+		if err == io.EOF {
+			return p.g, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("RDF/XML: %s", err)
+		}
+		if se, ok := tok.(xml.StartElement); ok {
+			if err := p.root(se); err != nil {
+				return nil, fmt.Errorf("RDF/XML: %s", err)
+			}
+		}
+	}
+}
+
+func attr(se xml.StartElement, space, local string) (string, bool) {
+	for _, a := range se.Attr {
+		if a.Name.Space == space && a.Name.Local == local {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
+
+func (p *rdfxmlparser) root(se xml.StartElement) error {
+	if base, ok := attr(se, xmlns, "base"); ok {
+		p.base = base
+	}
+	lang, _ := attr(se, xmlns, "lang")
+	if se.Name.Space != rdfns || se.Name.Local != "RDF" {
+		_, err := p.node(se, lang)
+		return err
+	}
+	for {
+		tok, err := p.dec.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if _, err := p.node(t, lang); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+func (p *rdfxmlparser) blank(label string) Term {
+	if node, ok := p.blanks[label]; ok {
+		return node
+	}
+	node := p.g.NewBlankNode()
+	p.blanks[label] = node
+	return node
+}
+
+// subject returns the node described by the attributes of se
+func (p *rdfxmlparser) subject(se xml.StartElement) Term {
+	if about, ok := attr(se, rdfns, "about"); ok {
+		return Term{Kind: IRI, Value: resolve(p.base, about)}
+	}
+	if id, ok := attr(se, rdfns, "ID"); ok {
+		return Term{Kind: IRI, Value: resolve(p.base, "#"+id)}
+	}
+	if label, ok := attr(se, rdfns, "nodeID"); ok {
+		return p.blank(label)
+	}
+	return p.g.NewBlankNode()
+}
+
+// propertyattrs adds the attributes of se which are not part of the syntax as literal properties of subject
+func (p *rdfxmlparser) propertyattrs(subject Term, se xml.StartElement, lang string) {
+	for _, a := range se.Attr {
+		if a.Name.Space == "" || a.Name.Space == xmlns || a.Name.Space == "xmlns" || a.Name.Space == rdfns && a.Name.Local != "type" {
+			continue
+		}
+		if a.Name.Space == rdfns {
+			p.g.Add(subject, rdfns+"type", Term{Kind: IRI, Value: resolve(p.base, a.Value)})
+			continue
+		}
+		p.g.Add(subject, a.Name.Space+a.Name.Local, NewLangLiteral(a.Value, lang))
+	}
+}
+
+// node reads the node element se up to its end and returns the node it describes
+func (p *rdfxmlparser) node(se xml.StartElement, lang string) (Term, error) {
+	if l, ok := attr(se, xmlns, "lang"); ok {
+		lang = l
+	}
+	subject := p.subject(se)
+	if se.Name.Space != rdfns || se.Name.Local != "Description" {
+		p.g.Add(subject, rdfns+"type", Term{Kind: IRI, Value: se.Name.Space + se.Name.Local})
+	}
+	p.propertyattrs(subject, se, lang)
+	return subject, p.properties(subject, lang)
+}
+
+// properties reads property elements of subject up to the end of the enclosing element
+func (p *rdfxmlparser) properties(subject Term, lang string) error {
+	for {
+		tok, err := p.dec.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if err := p.property(subject, t, lang); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+func (p *rdfxmlparser) property(subject Term, se xml.StartElement, lang string) error {
+	if l, ok := attr(se, xmlns, "lang"); ok {
+		lang = l
+	}
+	predicate := se.Name.Space + se.Name.Local
+
+	if parsetype, ok := attr(se, rdfns, "parseType"); ok && parsetype == "Resource" {
+		object := p.g.NewBlankNode()
+		p.g.Add(subject, predicate, object)
+		return p.properties(object, lang)
+	}
+
+	var object *Term
+	if resource, ok := attr(se, rdfns, "resource"); ok {
+		o := Term{Kind: IRI, Value: resolve(p.base, resource)}
+		object = &o
+	} else if label, ok := attr(se, rdfns, "nodeID"); ok {
+		o := p.blank(label)
+		object = &o
+	}
+	if object != nil {
+		p.g.Add(subject, predicate, *object)
+		p.propertyattrs(*object, se, lang)
+		return p.dec.Skip()
+	}
+
+	datatype, _ := attr(se, rdfns, "datatype")
+	var text strings.Builder
+	for {
+		tok, err := p.dec.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.CharData:
+			text.Write(t)
+		case xml.StartElement:
+			o, err := p.node(t, lang)
+			if err != nil {
+				return err
+			}
+			p.g.Add(subject, predicate, o)
+			return p.dec.Skip()
+		case xml.EndElement:
+			lit := NewLiteral(text.String())
+			if datatype != "" {
+				lit.Datatype = resolve(p.base, datatype)
+			} else {
+				lit.Lang = lang
+			}
+			p.g.Add(subject, predicate, lit)
+			return nil
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package dcat
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// Format is an RDF serialisation supported by Write
+type Format string
+
+const (
+	Turtle Format = "turtle"
+	RDFXML Format = "rdfxml"
+	JSONLD Format = "jsonld"
+)
+
+// Formats lists the supported serialisations
+var Formats = []Format{Turtle, RDFXML, JSONLD}
+
+// FormatForFilename returns the serialisation usually stored in files named
+// as filename: .ttl, .rdf or .xml, .jsonld or .json
+func FormatForFilename(filename string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".ttl":
+		return Turtle, nil
+	case ".rdf", ".xml":
+		return RDFXML, nil
+	case ".jsonld", ".json":
+		return JSONLD, nil
+	}
+	return "", fmt.Errorf("Kein RDF-Format für die Dateiendung von '%s' bekannt", filename)
+}
+
+// Write writes g to w in format
+func (g *Graph) Write(w io.Writer, format Format) error {
+	switch format {
+	case Turtle:
+		return g.WriteTurtle(w)
+	case RDFXML:
+		return g.WriteRDFXML(w)
+	case JSONLD:
+		return g.WriteJSONLD(w)
+	}
+	return fmt.Errorf("Nicht unterstütztes RDF-Format: '%s'", format)
+}
+
+// turtleescape escapes str for use in a Turtle or N-Triples string literal
+func turtleescape(str string) string {
+	var b strings.Builder
+	for _, r := range str {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(&b, `\u%04X`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	return b.String()
+}
+
+func (t Term) turtle() string {
+	switch t.Kind {
+	case IRI:
+		if name, ok := compact(t.Value); ok {
+			return name
+		}
+		return "<" + t.Value + ">"
+	case BlankNode:
+		return "_:" + t.Value
+	}
+	str := `"` + turtleescape(t.Value) + `"`
+	if t.Lang != "" {
+		return str + "@" + t.Lang
+	}
+	if t.Datatype != "" {
+		return str + "^^" + NewIRI(t.Datatype).turtle()
+	}
+	return str
+}
+
+// WriteTurtle writes g to w as Turtle, grouping the triples by subject
+func (g *Graph) WriteTurtle(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for _, ns := range namespaces {
+		fmt.Fprintf(bw, "@prefix %s: <%s> .\n", ns.prefix, ns.uri)
+	}
+
+	order, bysubject := g.subjects()
+	for _, subject := range order {
+		fmt.Fprintf(bw, "\n%s", subject.turtle())
+		for idx, t := range bysubject[subject] {
+			sep := " ;"
+			if idx == 0 {
+				sep = ""
+			}
+			predicate := t.Predicate.turtle()
+			if t.Predicate.Value == expand("rdf:type") {
+				predicate = "a"
+			}
+			fmt.Fprintf(bw, "%s\n    %s %s", sep, predicate, t.Object.turtle())
+		}
+		fmt.Fprint(bw, " .\n")
+	}
+	return bw.Flush()
+}
+
+// qname splits iri into namespace prefix and local name for use as XML element name
+func qname(iri string) (prefix, local string, err error) {
+	name, ok := compact(iri)
+	if !ok {
+		return "", "", fmt.Errorf("Property kann nicht in RDF/XML geschrieben werden: %s", iri)
+	}
+	idx := strings.Index(name, ":")
+	return name[:idx], name[idx+1:], nil
+}
+
+func xmlescape(str string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(str))
+	return b.String()
+}
+
+// WriteRDFXML writes g to w as RDF/XML, one rdf:Description per subject
+func (g *Graph) WriteRDFXML(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprint(bw, xml.Header)
+	fmt.Fprint(bw, "<rdf:RDF")
+	for _, ns := range namespaces {
+		fmt.Fprintf(bw, "\n    xmlns:%s=\"%s\"", ns.prefix, ns.uri)
+	}
+	fmt.Fprint(bw, ">\n")
+
+	order, bysubject := g.subjects()
+	for _, subject := range order {
+		if subject.Kind == BlankNode {
+			fmt.Fprintf(bw, "  <rdf:Description rdf:nodeID=\"%s\">\n", xmlescape(subject.Value))
+		} else {
+			fmt.Fprintf(bw, "  <rdf:Description rdf:about=\"%s\">\n", xmlescape(subject.Value))
+		}
+		for _, t := range bysubject[subject] {
+			prefix, local, err := qname(t.Predicate.Value)
+			if err != nil {
+				return err
+			}
+			element := prefix + ":" + local
+			switch obj := t.Object; {
+			case obj.Kind == IRI:
+				fmt.Fprintf(bw, "    <%s rdf:resource=\"%s\"/>\n", element, xmlescape(obj.Value))
+			case obj.Kind == BlankNode:
+				fmt.Fprintf(bw, "    <%s rdf:nodeID=\"%s\"/>\n", element, xmlescape(obj.Value))
+			case obj.Lang != "":
+				fmt.Fprintf(bw, "    <%s xml:lang=\"%s\">%s</%s>\n", element, xmlescape(obj.Lang), xmlescape(obj.Value), element)
+			case obj.Datatype != "":
+				fmt.Fprintf(bw, "    <%s rdf:datatype=\"%s\">%s</%s>\n", element, xmlescape(obj.Datatype), xmlescape(obj.Value), element)
+			default:
+				fmt.Fprintf(bw, "    <%s>%s</%s>\n", element, xmlescape(obj.Value), element)
+			}
+		}
+		fmt.Fprint(bw, "  </rdf:Description>\n")
+	}
+	fmt.Fprint(bw, "</rdf:RDF>\n")
+	return bw.Flush()
+}
+
+func (t Term) jsonldid() string {
+	if t.Kind == BlankNode {
+		return "_:" + t.Value
+	}
+	if name, ok := compact(t.Value); ok {
+		return name
+	}
+	return t.Value
+}
+
+// WriteJSONLD writes g to w as JSON-LD, as a @graph of node objects using
+// the prefixes of the export as @context
+func (g *Graph) WriteJSONLD(w io.Writer) error {
+	context := make(map[string]string, len(namespaces))
+	for _, ns := range namespaces {
+		context[ns.prefix] = ns.uri
+	}
+
+	var nodes []map[string]interface{}
+	order, bysubject := g.subjects()
+	for _, subject := range order {
+		node := map[string]interface{}{"@id": subject.jsonldid()}
+		for _, t := range bysubject[subject] {
+			if t.Predicate.Value == expand("rdf:type") {
+				types, _ := node["@type"].([]string)
+				node["@type"] = append(types, t.Object.jsonldid())
+				continue
+			}
+			var value map[string]string
+			switch obj := t.Object; {
+			case obj.Kind != Literal:
+				value = map[string]string{"@id": obj.jsonldid()}
+			case obj.Lang != "":
+				value = map[string]string{"@value": obj.Value, "@language": obj.Lang}
+			case obj.Datatype != "":
+				value = map[string]string{"@value": obj.Value, "@type": NewIRI(obj.Datatype).jsonldid()}
+			default:
+				value = map[string]string{"@value": obj.Value}
+			}
+			key := t.Predicate.jsonldid()
+			values, _ := node[key].([]map[string]string)
+			node[key] = append(values, value)
+		}
+		nodes = append(nodes, node)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(map[string]interface{}{"@context": context, "@graph": nodes})
+}
@@ -0,0 +1,474 @@
+package dcat
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// turtleparser reads the Turtle serialisation, including collections and
+// nested blank node property lists, by recursive descent
+type turtleparser struct {
+	src      string
+	pos      int
+	line     int
+	base     string
+	prefixes map[string]string
+	blanks   map[string]Term
+	g        *Graph
+}
+
+func parseturtle(src, base string) (*Graph, error) {
+	p := &turtleparser{src: src, line: 1, base: base, prefixes: make(map[string]string), blanks: make(map[string]Term), g: &Graph{}}
+	for {
+		p.skipspace()
+		if p.eof() {
+			return p.g, nil
+		}
+		if err := p.statement(); err != nil {
+			return nil, fmt.Errorf("Turtle, Zeile %d: %s", p.line, err)
+		}
+	}
+}
+
+func (p *turtleparser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *turtleparser) peek() rune {
+	if p.eof() {
+		return 0
+	}
+	r, _ := utf8.DecodeRuneInString(p.src[p.pos:])
+	return r
+}
+
+func (p *turtleparser) next() rune {
+	r, size := utf8.DecodeRuneInString(p.src[p.pos:])
+	p.pos += size
+	if r == '\n' {
+		p.line++
+	}
+	return r
+}
+
+func (p *turtleparser) skipspace() {
+	for !p.eof() {
+		switch r := p.peek(); {
+		case r == '#':
+			for !p.eof() && p.peek() != '\n' {
+				p.next()
+			}
+		case unicode.IsSpace(r):
+			p.next()
+		default:
+			return
+		}
+	}
+}
+
+func (p *turtleparser) expect(r rune) error {
+	p.skipspace()
+	if p.peek() != r {
+		return fmt.Errorf("'%c' erwartet, gefunden: '%s'", r, p.excerpt())
+	}
+	p.next()
+	return nil
+}
+
+func (p *turtleparser) excerpt() string {
+	end := p.pos + 20
+	if end > len(p.src) {
+		end = len(p.src)
+	}
+	return p.src[p.pos:end]
+}
+
+// keyword consumes word if it follows, case-insensitive if fold is set
+func (p *turtleparser) keyword(word string, fold bool) bool {
+	if len(p.src)-p.pos < len(word) {
+		return false
+	}
+	candidate := p.src[p.pos : p.pos+len(word)]
+	if candidate != word && !(fold && strings.EqualFold(candidate, word)) {
+		return false
+	}
+	if end := p.pos + len(word); end < len(p.src) && isnamerune(rune(p.src[end])) {
+		return false
+	}
+	p.pos += len(word)
+	return true
+}
+
+func (p *turtleparser) statement() error {
+	switch {
+	case p.keyword("@prefix", false):
+		if err := p.prefix(); err != nil {
+			return err
+		}
+		return p.expect('.')
+	case p.keyword("@base", false):
+		if err := p.setbase(); err != nil {
+			return err
+		}
+		return p.expect('.')
+	case p.keyword("PREFIX", true):
+		return p.prefix()
+	case p.keyword("BASE", true):
+		return p.setbase()
+	}
+
+	var subject Term
+	var err error
+	if p.peek() == '[' {
+		if subject, err = p.blanknodepropertylist(); err != nil {
+			return err
+		}
+		p.skipspace()
+		if p.peek() == '.' {
+			p.next()
+			return nil
+		}
+	} else if subject, err = p.subject(); err != nil {
+		return err
+	}
+	if err := p.predicateobjectlist(subject); err != nil {
+		return err
+	}
+	return p.expect('.')
+}
+
+func (p *turtleparser) prefix() error {
+	p.skipspace()
+	start := p.pos
+	for !p.eof() && p.peek() != ':' && !unicode.IsSpace(p.peek()) {
+		p.next()
+	}
+	name := p.src[start:p.pos]
+	if err := p.expect(':'); err != nil {
+		return err
+	}
+	p.skipspace()
+	iri, err := p.iriref()
+	if err != nil {
+		return err
+	}
+	p.prefixes[name] = iri
+	return nil
+}
+
+func (p *turtleparser) setbase() error {
+	p.skipspace()
+	iri, err := p.iriref()
+	if err != nil {
+		return err
+	}
+	p.base = iri
+	return nil
+}
+
+func (p *turtleparser) subject() (Term, error) {
+	p.skipspace()
+	switch p.peek() {
+	case '(':
+		return p.collection()
+	case '_':
+		return p.blanknodelabel()
+	}
+	return p.iri()
+}
+
+func (p *turtleparser) predicateobjectlist(subject Term) error {
+	for {
+		p.skipspace()
+		var predicate Term
+		if p.keyword("a", false) {
+			predicate = NewIRI("rdf:type")
+		} else {
+			var err error
+			if predicate, err = p.iri(); err != nil {
+				return err
+			}
+		}
+		for {
+			object, err := p.object()
+			if err != nil {
+				return err
+			}
+			p.g.Add(subject, predicate.Value, object)
+			p.skipspace()
+			if p.peek() != ',' {
+				break
+			}
+			p.next()
+		}
+		if p.peek() != ';' {
+			return nil
+		}
+		// any number of ';' may follow, optionally without a further predicate
+		for p.peek() == ';' {
+			p.next()
+			p.skipspace()
+		}
+		if r := p.peek(); r == '.' || r == ']' || r == 0 {
+			return nil
+		}
+	}
+}
+
+func (p *turtleparser) object() (Term, error) {
+	p.skipspace()
+	switch r := p.peek(); {
+	case r == '[':
+		return p.blanknodepropertylist()
+	case r == '(':
+		return p.collection()
+	case r == '_':
+		return p.blanknodelabel()
+	case r == '"' || r == '\'':
+		return p.literal()
+	case r == '+' || r == '-' || r == '.' || r >= '0' && r <= '9':
+		return p.numeric()
+	case p.keyword("true", false):
+		return NewTypedLiteral("true", "xsd:boolean"), nil
+	case p.keyword("false", false):
+		return NewTypedLiteral("false", "xsd:boolean"), nil
+	}
+	return p.iri()
+}
+
+func (p *turtleparser) blanknodepropertylist() (Term, error) {
+	p.next() // [
+	node := p.g.NewBlankNode()
+	p.skipspace()
+	if p.peek() != ']' {
+		if err := p.predicateobjectlist(node); err != nil {
+			return node, err
+		}
+	}
+	return node, p.expect(']')
+}
+
+func (p *turtleparser) collection() (Term, error) {
+	p.next() // (
+	var items []Term
+	for {
+		p.skipspace()
+		if p.peek() == ')' {
+			p.next()
+			break
+		}
+		if p.eof() {
+			return Term{}, fmt.Errorf("Ende der Liste erwartet")
+		}
+		item, err := p.object()
+		if err != nil {
+			return Term{}, err
+		}
+		items = append(items, item)
+	}
+	return p.g.list(items), nil
+}
+
+func (p *turtleparser) blanknodelabel() (Term, error) {
+	if !strings.HasPrefix(p.src[p.pos:], "_:") {
+		return Term{}, fmt.Errorf("Blank Node erwartet, gefunden: '%s'", p.excerpt())
+	}
+	p.pos += 2
+	label := p.name()
+	if label == "" {
+		return Term{}, fmt.Errorf("Bezeichner des Blank Node fehlt")
+	}
+	if node, ok := p.blanks[label]; ok {
+		return node, nil
+	}
+	node := p.g.NewBlankNode()
+	p.blanks[label] = node
+	return node, nil
+}
+
+func isnamerune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' || r == '.' || r == ':' || r == '%' || r == '\\'
+}
+
+// name reads a prefixed name or blank node label, which must not end with a '.'
+func (p *turtleparser) name() string {
+	var b strings.Builder
+	for !p.eof() && isnamerune(p.peek()) {
+		r := p.next()
+		if r == '\\' && !p.eof() {
+			r = p.next()
+		}
+		b.WriteRune(r)
+	}
+	name := b.String()
+	for strings.HasSuffix(name, ".") {
+		name = name[:len(name)-1]
+		p.pos--
+	}
+	return name
+}
+
+func (p *turtleparser) iri() (Term, error) {
+	p.skipspace()
+	if p.peek() == '<' {
+		iri, err := p.iriref()
+		return Term{Kind: IRI, Value: iri}, err
+	}
+	name := p.name()
+	idx := strings.Index(name, ":")
+	if idx < 0 {
+		return Term{}, fmt.Errorf("IRI erwartet, gefunden: '%s'", name+p.excerpt())
+	}
+	ns, ok := p.prefixes[name[:idx]]
+	if !ok {
+		return Term{}, fmt.Errorf("Präfix nicht deklariert: '%s'", name[:idx])
+	}
+	return Term{Kind: IRI, Value: ns + name[idx+1:]}, nil
+}
+
+// iriref reads an IRI in angle brackets and resolves it against the base IRI
+func (p *turtleparser) iriref() (string, error) {
+	if p.peek() != '<' {
+		return "", fmt.Errorf("'<' erwartet, gefunden: '%s'", p.excerpt())
+	}
+	p.next()
+	var b strings.Builder
+	for {
+		if p.eof() {
+			return "", fmt.Errorf("'>' erwartet")
+		}
+		r := p.next()
+		if r == '>' {
+			break
+		}
+		if r == '\\' {
+			var err error
+			if r, err = p.unicodeescape(); err != nil {
+				return "", err
+			}
+		}
+		b.WriteRune(r)
+	}
+	return resolve(p.base, b.String()), nil
+}
+
+// resolve resolves the IRI reference ref against base
+func resolve(base, ref string) string {
+	if base == "" {
+		return ref
+	}
+	r, err := url.Parse(ref)
+	if err != nil || r.IsAbs() {
+		return ref
+	}
+	b, err := url.Parse(base)
+	if err != nil {
+		return ref
+	}
+	return b.ResolveReference(r).String()
+}
+
+func (p *turtleparser) unicodeescape() (rune, error) {
+	var digits int
+	switch p.next() {
+	case 'u':
+		digits = 4
+	case 'U':
+		digits = 8
+	default:
+		return 0, fmt.Errorf("ungültige Escape-Sequenz")
+	}
+	if len(p.src)-p.pos < digits {
+		return 0, fmt.Errorf("ungültige Escape-Sequenz")
+	}
+	val, err := strconv.ParseUint(p.src[p.pos:p.pos+digits], 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("ungültige Escape-Sequenz: %s", err)
+	}
+	p.pos += digits
+	return rune(val), nil
+}
+
+func (p *turtleparser) literal() (Term, error) {
+	quote := p.next()
+	long := strings.HasPrefix(p.src[p.pos:], string([]rune{quote, quote}))
+	if long {
+		p.pos += 2
+	}
+	var b strings.Builder
+	for {
+		if p.eof() {
+			return Term{}, fmt.Errorf("Ende der Zeichenfolge erwartet")
+		}
+		r := p.next()
+		if r == quote {
+			if !long {
+				break
+			}
+			if strings.HasPrefix(p.src[p.pos:], string([]rune{quote, quote})) {
+				p.pos += 2
+				break
+			}
+		}
+		if r == '\n' && !long {
+			return Term{}, fmt.Errorf("Zeilenumbruch in Zeichenfolge")
+		}
+		if r == '\\' {
+			switch esc := p.peek(); esc {
+			case 't', 'b', 'n', 'r', 'f', '"', '\'', '\\':
+				p.next()
+				r = map[rune]rune{'t': '\t', 'b': '\b', 'n': '\n', 'r': '\r', 'f': '\f', '"': '"', '\'': '\'', '\\': '\\'}[esc]
+			default:
+				var err error
+				if r, err = p.unicodeescape(); err != nil {
+					return Term{}, err
+				}
+			}
+		}
+		b.WriteRune(r)
+	}
+
+	lit := NewLiteral(b.String())
+	switch {
+	case p.peek() == '@':
+		p.next()
+		start := p.pos
+		for !p.eof() && (unicode.IsLetter(p.peek()) || unicode.IsDigit(p.peek()) || p.peek() == '-') {
+			p.next()
+		}
+		lit.Lang = strings.ToLower(p.src[start:p.pos])
+	case strings.HasPrefix(p.src[p.pos:], "^^"):
+		p.pos += 2
+		datatype, err := p.iri()
+		if err != nil {
+			return Term{}, err
+		}
+		lit.Datatype = datatype.Value
+	}
+	return lit, nil
+}
+
+func (p *turtleparser) numeric() (Term, error) {
+	start := p.pos
+	for !p.eof() && strings.ContainsRune("+-.0123456789eE", p.peek()) {
+		p.next()
+	}
+	// a trailing '.' ends the statement
+	for p.pos > start && p.src[p.pos-1] == '.' {
+		p.pos--
+	}
+	num := p.src[start:p.pos]
+	switch {
+	case strings.ContainsAny(num, "eE"):
+		return NewTypedLiteral(num, "xsd:double"), nil
+	case strings.Contains(num, "."):
+		return NewTypedLiteral(num, "xsd:decimal"), nil
+	case num == "" || num == "+" || num == "-":
+		return Term{}, fmt.Errorf("Zahl erwartet, gefunden: '%s'", p.excerpt())
+	}
+	return NewTypedLiteral(num, "xsd:integer"), nil
+}
@@ -0,0 +1,127 @@
+package ogdat
+
+import (
+	"sort"
+	"strings"
+)
+
+// kinds of differences of a field between two versions of the specification
+const (
+	FieldAdded   = "added"
+	FieldRemoved = "removed"
+	FieldChanged = "changed"
+)
+
+// PropertyChange is a property of a field, as e.g. "Anzahl", which differs
+// between two versions of the specification. For added fields Old, for removed
+// fields New is empty.
+type PropertyChange struct {
+	Property string `json:"property"`
+	Old      string `json:"old"`
+	New      string `json:"new"`
+}
+
+// FieldDiff describes how a field differs between two versions of the specification
+type FieldDiff struct {
+	ID      int              `json:"id"`
+	Name    string           `json:"name"` // OGD_Kurzname in the newer version, if present there
+	Kind    string           `json:"kind"` // FieldAdded, FieldRemoved or FieldChanged
+	Changes []PropertyChange `json:"changes"`
+}
+
+// SpecDiff holds the differences between two versions of the specification
+type SpecDiff struct {
+	Old    string      `json:"old"` // versions of the specification compared
+	New    string      `json:"new"`
+	Fields []FieldDiff `json:"fields"` // ordered by ID
+}
+
+// Kind returns the differences of kind FieldAdded, FieldRemoved or FieldChanged
+func (d *SpecDiff) Kind(kind string) (fields []FieldDiff) {
+	for _, f := range d.Fields {
+		if f.Kind == kind {
+			fields = append(fields, f)
+		}
+	}
+	return
+}
+
+// occurrencename returns "R" for required and "O" for optional fields, as in the specification
+func occurrencename(occ Occurrence) string {
+	switch occ {
+	case OccRequired:
+		return "R"
+	case OccOptional:
+		return "O"
+	}
+	return ""
+}
+
+// diffproperties returns the properties of a field compared by DiffSpec
+func diffproperties(desc *Beschreibung) [][2]string {
+	if desc == nil {
+		return [][2]string{{"OGD_Kurzname"}, {"CKAN_Feld"}, {"Occurrence"}, {"Anzahl"}, {"Definition_DE"}, {"Definition_EN"}}
+	}
+	return [][2]string{
+		{"OGD_Kurzname", desc.OGD_Kurzname},
+		{"CKAN_Feld", desc.CKAN_Feld},
+		{"Occurrence", occurrencename(desc.occurrence)},
+		{"Anzahl", desc.Anzahl},
+		{"Definition_DE", desc.Definition_DE},
+		{"Definition_EN", desc.Definition_EN},
+	}
+}
+
+func specversion(set *OGDSet) string {
+	if set != nil && len(set.Beschreibung) > 0 {
+		return set.Beschreibung[0].Version()
+	}
+	return ""
+}
+
+// DiffSpec compares the specifications old and new field by field, fields are
+// identified by their ID. Reported are added and removed fields, changed names,
+// occurrence (required or optional), cardinality (Anzahl) and definitions.
+// Differences in whitespace only are ignored.
+func DiffSpec(old, new *OGDSet) *SpecDiff {
+	diff := &SpecDiff{Old: specversion(old), New: specversion(new), Fields: []FieldDiff{}}
+
+	ids := make(map[int]bool)
+	for _, set := range []*OGDSet{old, new} {
+		if set != nil {
+			for _, desc := range set.Beschreibung {
+				ids[desc.ID] = true
+			}
+		}
+	}
+	sorted := make([]int, 0, len(ids))
+	for id := range ids {
+		sorted = append(sorted, id)
+	}
+	sort.Ints(sorted)
+
+	for _, id := range sorted {
+		olddesc, _ := old.GetBeschreibungForID(id)
+		newdesc, _ := new.GetBeschreibungForID(id)
+		f := FieldDiff{ID: id, Kind: FieldChanged}
+		switch {
+		case olddesc == nil:
+			f.Kind, f.Name = FieldAdded, newdesc.OGD_Kurzname
+		case newdesc == nil:
+			f.Kind, f.Name = FieldRemoved, olddesc.OGD_Kurzname
+		default:
+			f.Name = newdesc.OGD_Kurzname
+		}
+		oldprops, newprops := diffproperties(olddesc), diffproperties(newdesc)
+		for idx := range oldprops {
+			o, n := oldprops[idx][1], newprops[idx][1]
+			if strings.Join(strings.Fields(o), " ") != strings.Join(strings.Fields(n), " ") {
+				f.Changes = append(f.Changes, PropertyChange{Property: oldprops[idx][0], Old: o, New: n})
+			}
+		}
+		if len(f.Changes) > 0 {
+			diff.Fields = append(diff.Fields, f)
+		}
+	}
+	return diff
+}
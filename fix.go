@@ -0,0 +1,218 @@
+package ogdat
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+)
+
+// Change describes a modification made by Fix to a metadata document
+type Change struct {
+	Field    string `json:"field"`          // CKAN field as named in the specification, e.g. "extras:categorization"
+	Resource int    `json:"resource"`       // index of the resource, -1 for fields of the metadata set
+	Code     string `json:"code,omitempty"` // code of the check message the change resolves, if any
+	Old      string `json:"old"`            // JSON encoding of the value before the change
+	New      string `json:"new"`            // JSON encoding of the value after the change
+	Reason   string `json:"reason"`
+}
+
+func (c Change) String() string {
+	field := c.Field
+	if c.Resource > -1 {
+		field = fmt.Sprintf("%s[%d]", field, c.Resource)
+	}
+	return fmt.Sprintf("%s: %s: %s -> %s", field, c.Reason, c.Old, c.New)
+}
+
+// a fieldfix returns the corrected value, the code of the message it resolves
+// and the reason, or ok = false if there is nothing to fix
+type fieldfix func(value interface{}) (fixed interface{}, code, reason string, ok bool)
+
+var fieldfixes = []struct {
+	field string
+	fix   fieldfix
+}{
+	{"title", fixhtmlescapes},
+	{"notes", fixhtmlescapes},
+	{"maintainer", fixhtmlescapes},
+	{"license", fixhtmlescapes},
+	{"extras:metadata_modified", fixtimeformat(CustomTimeSpecifier2, CodeDateFormat)},
+	{"extras:categorization", fixcategorization},
+	{"extras:begin_datetime", fixtimeformat(CustomTimeSpecifier1, CodeDateTimeFormat)},
+	{"extras:publisher", fixhtmlescapes},
+	{"extras:attribute_description", fixhtmlescapes},
+	{"extras:geographic_toponym", fixhtmlescapes},
+	{"extras:end_datetime", fixtimeformat(CustomTimeSpecifier1, CodeDateTimeFormat)},
+	{"extras:update_frequency", fixupdatefrequency},
+	{"extras:lineage_quality", fixhtmlescapes},
+	{"extras:en_title_and_desc", fixhtmlescapes},
+	{"extras:license_citation", fixhtmlescapes},
+	{"resources:format", fixresourceformat},
+	{"resources:name", fixhtmlescapes},
+	{"resources:created", fixtimeformat(CustomTimeSpecifier2, CodeDateFormat)},
+	{"resources:last_modified", fixtimeformat(CustomTimeSpecifier2, CodeDateFormat)},
+}
+
+// Fix corrects the mechanically fixable deviations from the specification in
+// the CKAN JSON metadata document bytedata and returns the corrected document
+// together with the changes made. Fields not mentioned by the specification are
+// retained. Findings which can not be fixed are left to Check.
+func Fix(bytedata []byte) ([]byte, []Change, error) {
+	var doc map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(bytedata))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, nil, fmt.Errorf("Metadaten können nicht gelesen werden: %s", err)
+	}
+
+	var changes []Change
+	apply := func(obj map[string]interface{}, key, field string, resource int, fix fieldfix) {
+		value, ok := obj[key]
+		if !ok || value == nil {
+			return
+		}
+		fixed, code, reason, ok := fix(value)
+		if !ok {
+			return
+		}
+		obj[key] = fixed
+		changes = append(changes, Change{Field: field, Resource: resource, Code: code, Old: jsonstring(value), New: jsonstring(fixed), Reason: reason})
+	}
+
+	extras, _ := doc["extras"].(map[string]interface{})
+	resources, _ := doc["resources"].([]interface{})
+	for _, ff := range fieldfixes {
+		switch {
+		case strings.HasPrefix(ff.field, "extras:"):
+			if extras != nil {
+				apply(extras, strings.TrimPrefix(ff.field, "extras:"), ff.field, -1, ff.fix)
+			}
+		case strings.HasPrefix(ff.field, "resources:"):
+			for idx, res := range resources {
+				if resource, ok := res.(map[string]interface{}); ok {
+					apply(resource, strings.TrimPrefix(ff.field, "resources:"), ff.field, idx, ff.fix)
+				}
+			}
+		default:
+			apply(doc, ff.field, ff.field, -1, ff.fix)
+		}
+	}
+
+	if len(changes) == 0 {
+		return bytedata, nil, nil
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return nil, nil, err
+	}
+	return buf.Bytes(), changes, nil
+}
+
+func jsonstring(value interface{}) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(value); err != nil {
+		return fmt.Sprint(value)
+	}
+	return strings.TrimSpace(buf.String())
+}
+
+// fixhtmlescapes replaces HTML escapes as "&auml;" by the characters they stand
+// for, unless the text contains HTML or would contain HTML afterwards
+func fixhtmlescapes(value interface{}) (interface{}, string, string, bool) {
+	str, ok := value.(string)
+	if !ok || !regexphtmlescape.MatchString(str) || regexphtmlcodecheck.MatchString(str) {
+		return nil, "", "", false
+	}
+	unescaped := html.UnescapeString(str)
+	if unescaped == str || regexphtmlcodecheck.MatchString(unescaped) {
+		return nil, "", "", false
+	}
+	return unescaped, CodeTextHTMLEscape, "HTML-Escapes durch die entsprechenden Zeichen ersetzt", true
+}
+
+// fixcategorization turns categories given as a single string, or as an array
+// embedded in a string, into an array
+func fixcategorization(value interface{}) (interface{}, string, string, bool) {
+	str, ok := value.(string)
+	if !ok {
+		return nil, "", "", false
+	}
+	if strings.HasPrefix(str, "[") {
+		var embedded []interface{}
+		if err := json.Unmarshal([]byte(str), &embedded); err == nil {
+			return embedded, CodeCategoryNotArray, "In einer Zeichenfolge eingebettetes Array als Array angegeben", true
+		}
+	}
+	return []interface{}{str}, CodeCategoryNotArray, "Einzelne Kategorie als Array angegeben", true
+}
+
+// fixtimeformat returns a fix which converts times given in any of TimeFormat into format
+func fixtimeformat(format, code string) fieldfix {
+	return func(value interface{}) (interface{}, string, string, bool) {
+		str, ok := value.(string)
+		if !ok {
+			return nil, "", "", false
+		}
+		for _, tf := range TimeFormat {
+			t, err := time.Parse(tf, str)
+			if err != nil {
+				continue
+			}
+			if tf == format {
+				return nil, "", "", false
+			}
+			return t.Format(format), code, fmt.Sprintf("Zeitangabe in das Format %s umgewandelt", format), true
+		}
+		return nil, "", "", false
+	}
+}
+
+// fixupdatefrequency replaces the German name, the domain code or the number of
+// an update frequency by its ISO 19115 MD_MaintenanceFrequencyCode
+func fixupdatefrequency(value interface{}) (interface{}, string, string, bool) {
+	str, ok := value.(string)
+	if !ok {
+		return nil, "", "", false
+	}
+	trimmed := strings.TrimSpace(str)
+	for _, cyc := range cycles {
+		if str == cyc.MD_MaintenanceFrequencyCode {
+			return nil, "", "", false
+		}
+		if cmpstrtocycle(trimmed, cyc) || strings.EqualFold(trimmed, cyc.Name_DE) || strings.EqualFold(trimmed, cyc.MD_MaintenanceFrequencyCode) {
+			// values accepted by Cycle.UnmarshalJSON as given do not raise a message
+			code := ""
+			if !cmpstrtocycle(str, cyc) {
+				code = CodeFrequencyInvalid
+			}
+			return cyc.MD_MaintenanceFrequencyCode, code, "Aktualisierungszyklus als Code nach ON/EN/ISO 19115:2003 angegeben", true
+		}
+	}
+	return nil, "", "", false
+}
+
+// fixresourceformat removes leading dots and converts the format to lower case
+func fixresourceformat(value interface{}) (interface{}, string, string, bool) {
+	str, ok := value.(string)
+	if !ok {
+		return nil, "", "", false
+	}
+	fixed := strings.ToLower(strings.TrimLeft(strings.TrimSpace(str), "."))
+	if fixed == str || fixed == "" {
+		return nil, "", "", false
+	}
+	code := CodeFormatLowerCase
+	if strings.HasPrefix(str, ".") {
+		code = CodeFormatInvalidChar
+	}
+	return fixed, code, "Format ohne führende Punkte und in Kleinbuchstaben angegeben", true
+}
@@ -0,0 +1,149 @@
+package ogdat
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// Format is a format resources are published in
+type Format struct {
+	ID        string   // the format as to be given in resource_format, e.g. "csv"
+	Name      string   // human readable name, e.g. "Comma-separated values"
+	Synonyms  []string // further names of the format, e.g. "shape" for "shp"
+	MIMETypes []string // media types servers deliver the format with, the first one is the format's own
+	Content   []string // media types content sniffing yields for the format, cf. http.DetectContentType
+}
+
+// MIMEType returns the media type of the format, empty if there is none
+func (f *Format) MIMEType() string {
+	if len(f.MIMETypes) > 0 {
+		return f.MIMETypes[0]
+	}
+	return ""
+}
+
+// AcceptsContentType reports whether the Content-Type header contenttype, with
+// or without parameters, is one of the media types of the format
+func (f *Format) AcceptsContentType(contenttype string) bool {
+	return containsmediatype(f.MIMETypes, contenttype)
+}
+
+// AcceptsContent reports whether data, the first bytes of a resource, may be
+// of the format. sniffed is the media type data is recognised as.
+func (f *Format) AcceptsContent(data []byte) (sniffed string, ok bool) {
+	sniffed = mediatype(http.DetectContentType(data))
+	return sniffed, len(f.Content) == 0 || containsmediatype(f.Content, sniffed)
+}
+
+// mediatype returns the media type of contenttype without parameters in lower case
+func mediatype(contenttype string) string {
+	if mt, _, err := mime.ParseMediaType(contenttype); err == nil {
+		return mt
+	}
+	if idx := strings.IndexByte(contenttype, ';'); idx > -1 {
+		contenttype = contenttype[:idx]
+	}
+	return strings.ToLower(strings.TrimSpace(contenttype))
+}
+
+func containsmediatype(types []string, contenttype string) bool {
+	contenttype = mediatype(contenttype)
+	for _, mt := range types {
+		if mt == contenttype {
+			return true
+		}
+	}
+	return false
+}
+
+// FormatRegistry resolves the formats of resources by their name, synonyms and media type.
+// A FormatRegistry is read-only after creation and thus safe for concurrent use.
+type FormatRegistry struct {
+	formats []*Format
+	byname  map[string]*Format
+}
+
+// NewFormatRegistry reads the formats from reader, one format per line given
+// as Format|Bezeichnung|Synonyme|MIME-Typen|Inhalt with the lists separated by
+// ';'. Lines starting with '#' are comments.
+func NewFormatRegistry(reader io.Reader) (*FormatRegistry, error) {
+	reg := &FormatRegistry{byname: make(map[string]*Format)}
+
+	csvreader := csv.NewReader(reader)
+	csvreader.Comma = '|'
+	csvreader.Comment = '#'
+	csvreader.FieldsPerRecord = 5
+
+	split := func(field string) []string {
+		if field == "" {
+			return nil
+		}
+		return strings.Split(field, ";")
+	}
+	for record, err := csvreader.Read(); err != io.EOF; record, err = csvreader.Read() {
+		if err != nil {
+			return nil, err
+		}
+		format := &Format{ID: strings.TrimPrefix(record[0], "\ufeff"), Name: record[1], Synonyms: split(record[2]),
+			MIMETypes: split(record[3]), Content: split(record[4])}
+		reg.formats = append(reg.formats, format)
+
+		names := append([]string{format.ID}, format.Synonyms...)
+		if mt := format.MIMEType(); mt != "" {
+			names = append(names, mt)
+		}
+		for _, name := range names {
+			if key := formatkey(name); reg.byname[key] == nil {
+				reg.byname[key] = format
+			}
+		}
+	}
+	return reg, nil
+}
+
+// Len returns the number of formats known to the registry
+func (reg *FormatRegistry) Len() int {
+	return len(reg.formats)
+}
+
+// Lookup returns the format given by its name, a synonym or its media type.
+// Case and leading dots are ignored.
+func (reg *FormatRegistry) Lookup(name string) (*Format, bool) {
+	format, ok := reg.byname[formatkey(name)]
+	return format, ok
+}
+
+// formatkey lower cases name and removes leading dots and media type parameters
+func formatkey(name string) string {
+	if strings.IndexByte(name, '/') > -1 {
+		name = mediatype(name)
+	}
+	return strings.TrimLeft(strings.ToLower(strings.TrimSpace(name)), ".")
+}
+
+// Formats returns the registry of resource formats as embedded into the library,
+// or as found in DataDir
+func Formats() (*FormatRegistry, error) {
+	referenceregistries.Lock()
+	defer referenceregistries.Unlock()
+
+	if referenceregistries.formats == nil {
+		reader, err := opendatafile(referencefs, formatfile)
+		if err != nil {
+			return nil, fmt.Errorf("Can not load format file '%s': %s", formatfile, err)
+		}
+		defer reader.Close()
+		reg, err := NewFormatRegistry(reader)
+		if err != nil {
+			return nil, fmt.Errorf("Can not load format file '%s': %s", formatfile, err)
+		}
+		log.Printf("Info: Read %d format records", reg.Len())
+		referenceregistries.formats = reg
+	}
+	return referenceregistries.formats, nil
+}
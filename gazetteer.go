@@ -0,0 +1,214 @@
+package ogdat
+
+import (
+	"encoding/csv"
+	"fmt"
+	"github.com/the42/ogdat/geometry"
+	"html"
+	"io"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// types of places in the gazetteer
+const (
+	PlaceCountry      = "Staat"
+	PlaceState        = "Bundesland"
+	PlaceDistrict     = "Bezirk"
+	PlaceMunicipality = "Gemeinde"
+	PlaceCityDistrict = "Gemeindebezirk"
+)
+
+// Place is an administrative unit of Austria as listed in the gazetteer
+type Place struct {
+	GKZ      string         // Gemeindekennziffer or the code of the Bezirk or Bundesland, "0" for Austria
+	Type     string         // one of the Place... constants
+	Name     string         // the official spelling, e.g. "St. Pölten"
+	Variants []string       // further spellings, e.g. "Sankt Pölten"
+	BBox     *geometry.BBox // approximate bounding box, inherited from the enclosing unit if not given
+}
+
+// Gazetteer resolves toponyms to the administrative units of Austria.
+// A Gazetteer is read-only after creation and thus safe for concurrent use.
+type Gazetteer struct {
+	places []*Place
+	bygkz  map[string]*Place
+	byname map[string][]*Place
+}
+
+// NewGazetteer reads the places from reader, one place per line given as
+// GKZ|Typ|Name|Varianten|West|Süd|Ost|Nord with the variants separated by ';'.
+// Lines starting with '#' are comments. Places without bounding box inherit it
+// from the unit whose code is the longest prefix of their GKZ.
+func NewGazetteer(reader io.Reader) (*Gazetteer, error) {
+	g := &Gazetteer{bygkz: make(map[string]*Place), byname: make(map[string][]*Place)}
+
+	csvreader := csv.NewReader(reader)
+	csvreader.Comma = '|'
+	csvreader.Comment = '#'
+	csvreader.FieldsPerRecord = 8
+
+	for record, err := csvreader.Read(); err != io.EOF; record, err = csvreader.Read() {
+		if err != nil {
+			return nil, err
+		}
+		place := &Place{GKZ: strings.TrimPrefix(record[0], "\ufeff"), Type: record[1], Name: record[2]}
+		if record[3] != "" {
+			place.Variants = strings.Split(record[3], ";")
+		}
+		if record[4] != "" {
+			var bounds [4]float64
+			for idx := range bounds {
+				if bounds[idx], err = strconv.ParseFloat(record[4+idx], 64); err != nil {
+					return nil, fmt.Errorf("Ungültige Begrenzung für %s: %s", place.Name, err)
+				}
+			}
+			place.BBox = &geometry.BBox{West: bounds[0], South: bounds[1], East: bounds[2], North: bounds[3]}
+		}
+
+		g.places = append(g.places, place)
+		g.bygkz[place.GKZ] = place
+		for _, name := range append([]string{place.Name}, place.Variants...) {
+			key := normalizeplacename(name)
+			if known := g.byname[key]; len(known) == 0 || known[len(known)-1] != place {
+				g.byname[key] = append(known, place)
+			}
+		}
+	}
+
+	for _, place := range g.places {
+		for prefix := len(place.GKZ) - 1; place.BBox == nil && prefix > 0; prefix-- {
+			if parent, ok := g.bygkz[place.GKZ[:prefix]]; ok {
+				place.BBox = parent.BBox
+			}
+		}
+	}
+	return g, nil
+}
+
+// Len returns the number of places known to the gazetteer
+func (g *Gazetteer) Len() int {
+	return len(g.places)
+}
+
+// LookupGKZ returns the place with the code gkz
+func (g *Gazetteer) LookupGKZ(gkz string) (*Place, bool) {
+	place, ok := g.bygkz[gkz]
+	return place, ok
+}
+
+// the words a toponym may be prefixed with, e.g. "Bezirk Baden"
+var placeprefixes = map[string]bool{
+	"bundesland": true, "land": true, "bezirk": true, "politischer": true, "stadt": true, "landeshauptstadt": true,
+	"statutarstadt": true, "stadtgemeinde": true, "marktgemeinde": true, "gemeinde": true}
+
+// Lookup returns the places named name, the larger units first. The comparison
+// ignores case, punctuation, the spelling of umlauts, "Sankt" and "St." as well
+// as prefixes like "Bezirk" or "Stadt".
+func (g *Gazetteer) Lookup(name string) []*Place {
+	words := strings.Fields(normalizeplacename(name))
+	for len(words) > 0 {
+		if places, ok := g.byname[strings.Join(words, " ")]; ok {
+			return places
+		}
+		if !placeprefixes[words[0]] {
+			break
+		}
+		words = words[1:]
+	}
+	return nil
+}
+
+// ToponymMatch is a part of a toponym and the places it names, none if it is unknown
+type ToponymMatch struct {
+	Part   string
+	Places []*Place
+}
+
+// Name returns the official spelling of the part, or the part itself if it is unknown
+func (m ToponymMatch) Name() string {
+	if len(m.Places) > 0 {
+		return m.Places[0].Name
+	}
+	return m.Part
+}
+
+var toponymseparator = regexp.MustCompile(`\s*(?:[,;/]|\s(?:und|&)\s)\s*`)
+
+// Resolve looks up toponym as a whole and, if that fails, each of the places
+// it enumerates, as in "Wien, Niederösterreich und Burgenland". HTML escapes
+// found in toponym are replaced beforehand.
+func (g *Gazetteer) Resolve(toponym string) []ToponymMatch {
+	toponym = strings.TrimSpace(html.UnescapeString(toponym))
+	if toponym == "" {
+		return nil
+	}
+	if places := g.Lookup(toponym); places != nil {
+		return []ToponymMatch{{Part: toponym, Places: places}}
+	}
+	var matches []ToponymMatch
+	for _, part := range toponymseparator.Split(toponym, -1) {
+		if part != "" {
+			matches = append(matches, ToponymMatch{Part: part, Places: g.Lookup(part)})
+		}
+	}
+	return matches
+}
+
+var umlauts = strings.NewReplacer("ä", "ae", "ö", "oe", "ü", "ue", "ß", "ss")
+
+// normalizeplacename lower cases name, spells out umlauts, reduces punctuation
+// to single blanks and abbreviates "Sankt" to "st"
+func normalizeplacename(name string) string {
+	name = umlauts.Replace(strings.ToLower(name))
+	words := strings.FieldsFunc(name, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+	for idx, word := range words {
+		if word == "sankt" {
+			words[idx] = "st"
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// NormalizeToponym returns the official spellings of the places named by
+// toponym, unknown parts as given. Places named several times are returned once.
+func NormalizeToponym(toponym string) ([]string, error) {
+	g, err := Toponyms()
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	seen := make(map[string]bool)
+	for _, match := range g.Resolve(toponym) {
+		if name := match.Name(); !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// Toponyms returns the gazetteer as found in DataDir or else as embedded into
+// the library
+func Toponyms() (*Gazetteer, error) {
+	referenceregistries.Lock()
+	defer referenceregistries.Unlock()
+
+	if referenceregistries.gazetteer == nil {
+		reader, err := opendatafile(referencefs, gazetteerfile)
+		if err != nil {
+			return nil, fmt.Errorf("Can not load gazetteer file '%s': %s", gazetteerfile, err)
+		}
+		defer reader.Close()
+		g, err := NewGazetteer(reader)
+		if err != nil {
+			return nil, fmt.Errorf("Can not load gazetteer file '%s': %s", gazetteerfile, err)
+		}
+		log.Printf("Info: Read %d gazetteer records", g.Len())
+		referenceregistries.gazetteer = g
+	}
+	return referenceregistries.gazetteer, nil
+}
@@ -0,0 +1,68 @@
+package ogdat
+
+import (
+	"fmt"
+	"github.com/the42/ogdat/geometry"
+	"unicode/utf8"
+)
+
+// GeographicBBox returns the bounding box of the geographic extent bbox, given
+// as WKT or GeoJSON, or nil if bbox is not given or can not be parsed
+func GeographicBBox(bbox *string) *geometry.BBox {
+	if bbox == nil {
+		return nil
+	}
+	g, err := geometry.Parse(*bbox)
+	if err != nil {
+		return nil
+	}
+	b := g.BBox()
+	return &b
+}
+
+// CheckGeographicExtent checks the geographic extent str, given as WKT (POINT,
+// POLYGON, MULTIPOLYGON, ENVELOPE) or GeoJSON. Coordinates have to be valid
+// longitudes and latitudes, rings should follow the right-hand rule and the
+// extent should cover part of Austria. If closed is true, rings of polygons
+// have to be closed; otherwise rings of two points, the corners of the
+// bounding box as used by version 2.1 of the specification, are accepted.
+func CheckGeographicExtent(str string, closed bool) []*CheckInfo {
+	if !utf8.ValidString(str) {
+		return []*CheckInfo{{Status: Error, Position: -1, Context: "Zeichenfolge ist nicht durchgängig gültig als UTF8 kodiert", Code: CodeBBoxEncoding, Value: str}}
+	}
+	g, err := geometry.Parse(str)
+	if err != nil {
+		return []*CheckInfo{{Status: Error, Position: -1, Context: fmt.Sprintf("Keine gültige WKT- oder GeoJSON-Angabe der örtlichen Begrenzung: %s", err), Code: CodeBBoxInvalid, Value: str, Args: map[string]string{"reason": err.Error()}}}
+	}
+
+	var infos []*CheckInfo
+	notclosed, winding := false, false
+	for _, problem := range g.Validate() {
+		twopoint := problem.Ring > -1 && len(g.Polygons[problem.Polygon][problem.Ring]) == 2
+		switch {
+		case problem.Kind == geometry.ProblemRange:
+			infos = append(infos, &CheckInfo{Status: Error, Position: -1, Context: fmt.Sprintf("Koordinaten außerhalb des gültigen Bereichs für Länge und Breite: %s", str), Code: CodeBBoxRange, Value: str})
+		case !closed && twopoint:
+		case problem.Kind == geometry.ProblemWinding:
+			winding = true
+		default:
+			notclosed = true
+		}
+	}
+	if notclosed {
+		infos = append(infos, &CheckInfo{Status: Error, Position: -1, Context: fmt.Sprintf("Beginn und Ende des Polygons ergeben kein geschlossenes Polygon: %s", str), Code: CodeBBoxNotClosed, Value: str})
+	}
+	if winding {
+		infos = append(infos, &CheckInfo{Status: Info, Position: -1, Context: fmt.Sprintf("Die Ringe des Polygons folgen nicht der Rechte-Hand-Regel: %s", str), Code: CodeBBoxWinding, Value: str})
+	}
+
+	bbox := g.BBox()
+	switch {
+	case bbox.Intersects(geometry.Austria):
+	case bbox.Swapped().Intersects(geometry.Austria):
+		infos = append(infos, &CheckInfo{Status: Warning, Position: -1, Context: fmt.Sprintf("Länge und Breite sind vermutlich vertauscht: %s", str), Code: CodeBBoxSwapped, Value: str})
+	default:
+		infos = append(infos, &CheckInfo{Status: Warning, Position: -1, Context: fmt.Sprintf("Die örtliche Begrenzung liegt außerhalb Österreichs: %s", str), Code: CodeBBoxOutsideAustria, Value: str})
+	}
+	return infos
+}
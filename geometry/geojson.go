@@ -0,0 +1,99 @@
+package geometry
+
+import (
+	"encoding/json"
+)
+
+type geojsonobject struct {
+	Type        string           `json:"type"`
+	Coordinates json.RawMessage  `json:"coordinates"`
+	Geometry    *json.RawMessage `json:"geometry"`
+}
+
+func geojsonerror(msg string) error {
+	return &SyntaxError{Format: FormatGeoJSON, Offset: -1, Msg: msg}
+}
+
+func geojsonpoint(coords []float64) (Point, error) {
+	if len(coords) < 2 {
+		return Point{}, geojsonerror("Position mit weniger als zwei Koordinaten")
+	}
+	return Point{X: coords[0], Y: coords[1]}, nil
+}
+
+func geojsonpolygon(rings [][][]float64) (Polygon, error) {
+	if len(rings) == 0 {
+		return nil, geojsonerror("Polygon ohne Ring")
+	}
+	polygon := make(Polygon, len(rings))
+	for iring, positions := range rings {
+		for _, coords := range positions {
+			point, err := geojsonpoint(coords)
+			if err != nil {
+				return nil, err
+			}
+			polygon[iring] = append(polygon[iring], point)
+		}
+	}
+	return polygon, nil
+}
+
+// ParseGeoJSON reads a Point, Polygon or MultiPolygon given as GeoJSON
+// geometry or as Feature holding such a geometry
+func ParseGeoJSON(data []byte) (*Geometry, error) {
+	var obj geojsonobject
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, geojsonerror(err.Error())
+	}
+	if obj.Type == "Feature" {
+		if obj.Geometry == nil {
+			return nil, geojsonerror("Feature ohne Geometrie")
+		}
+		return ParseGeoJSON(*obj.Geometry)
+	}
+	if len(obj.Coordinates) == 0 {
+		return nil, geojsonerror("keine Koordinaten angegeben")
+	}
+
+	g := &Geometry{Type: obj.Type, Format: FormatGeoJSON}
+	switch obj.Type {
+	case TypePoint:
+		var coords []float64
+		if err := json.Unmarshal(obj.Coordinates, &coords); err != nil {
+			return nil, geojsonerror(err.Error())
+		}
+		point, err := geojsonpoint(coords)
+		if err != nil {
+			return nil, err
+		}
+		g.Point = point
+	case TypePolygon:
+		var rings [][][]float64
+		if err := json.Unmarshal(obj.Coordinates, &rings); err != nil {
+			return nil, geojsonerror(err.Error())
+		}
+		polygon, err := geojsonpolygon(rings)
+		if err != nil {
+			return nil, err
+		}
+		g.Polygons = []Polygon{polygon}
+	case TypeMultiPolygon:
+		var polygons [][][][]float64
+		if err := json.Unmarshal(obj.Coordinates, &polygons); err != nil {
+			return nil, geojsonerror(err.Error())
+		}
+		if len(polygons) == 0 {
+			return nil, geojsonerror("MultiPolygon ohne Polygon")
+		}
+		for _, rings := range polygons {
+			polygon, err := geojsonpolygon(rings)
+			if err != nil {
+				return nil, err
+			}
+			g.Polygons = append(g.Polygons, polygon)
+		}
+	default:
+		return nil, geojsonerror("nicht unterstützter Geometrietyp " + obj.Type)
+	}
+	return g, nil
+}
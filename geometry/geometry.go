@@ -0,0 +1,194 @@
+// Package geometry parses the geographic extent of OGD metadata, given as WKT
+// (POINT, POLYGON, MULTIPOLYGON, ENVELOPE) or as GeoJSON, and provides its
+// bounding box and the checks of coordinates and rings.
+package geometry
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// types of geometries
+const (
+	TypePoint        = "Point"
+	TypePolygon      = "Polygon"
+	TypeMultiPolygon = "MultiPolygon"
+	TypeEnvelope     = "Envelope"
+)
+
+// formats geometries are given in
+const (
+	FormatWKT     = "WKT"
+	FormatGeoJSON = "GeoJSON"
+)
+
+// epsilon is the tolerance when comparing coordinates
+const epsilon = 0.00000001
+
+// Point is a position given by longitude X and latitude Y
+type Point struct {
+	X, Y float64
+}
+
+func (p Point) equals(o Point) bool {
+	return math.Abs(p.X-o.X) <= epsilon && math.Abs(p.Y-o.Y) <= epsilon
+}
+
+// Ring is a sequence of points, closed if the first and last point are equal
+type Ring []Point
+
+// Closed reports whether the first and last point of the ring are equal
+func (r Ring) Closed() bool {
+	return len(r) > 1 && r[0].equals(r[len(r)-1])
+}
+
+// Area returns the signed area of the ring, positive if its points are given
+// counterclockwise
+func (r Ring) Area() float64 {
+	var area float64
+	for idx := range r {
+		next := r[(idx+1)%len(r)]
+		area += r[idx].X*next.Y - next.X*r[idx].Y
+	}
+	return area / 2
+}
+
+// Polygon is the exterior ring followed by the rings of its holes
+type Polygon []Ring
+
+// Geometry is a parsed geographic extent. POLYGON holds a single, MULTIPOLYGON
+// several polygons, ENVELOPE the rectangle given and POINT none.
+type Geometry struct {
+	Type     string
+	Format   string // FormatWKT or FormatGeoJSON
+	Point    Point  // the position of TypePoint
+	Polygons []Polygon
+}
+
+// Points returns all points of g
+func (g *Geometry) Points() []Point {
+	if g.Type == TypePoint {
+		return []Point{g.Point}
+	}
+	var points []Point
+	for _, polygon := range g.Polygons {
+		for _, ring := range polygon {
+			points = append(points, ring...)
+		}
+	}
+	return points
+}
+
+// BBox returns the bounding box enclosing all points of g
+func (g *Geometry) BBox() BBox {
+	var b BBox
+	for idx, p := range g.Points() {
+		if idx == 0 {
+			b = BBox{West: p.X, South: p.Y, East: p.X, North: p.Y}
+			continue
+		}
+		b.West, b.East = math.Min(b.West, p.X), math.Max(b.East, p.X)
+		b.South, b.North = math.Min(b.South, p.Y), math.Max(b.North, p.Y)
+	}
+	return b
+}
+
+// BBox is a bounding box given by its bounds in degrees
+type BBox struct {
+	West, South, East, North float64
+}
+
+// Austria is the bounding box of Austria
+var Austria = BBox{West: 9.53, South: 46.37, East: 17.17, North: 49.02}
+
+// Valid reports whether the bounds of b are valid longitudes and latitudes
+func (b BBox) Valid() bool {
+	return b.West >= -180 && b.East <= 180 && b.South >= -90 && b.North <= 90
+}
+
+// Intersects reports whether b and o have at least a point in common
+func (b BBox) Intersects(o BBox) bool {
+	return b.West <= o.East && o.West <= b.East && b.South <= o.North && o.South <= b.North
+}
+
+// Swapped returns b with longitude and latitude exchanged
+func (b BBox) Swapped() BBox {
+	return BBox{West: b.South, South: b.West, East: b.North, North: b.East}
+}
+
+// String returns b as closed WKT polygon
+func (b BBox) String() string {
+	f := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+	w, s, e, n := f(b.West), f(b.South), f(b.East), f(b.North)
+	return fmt.Sprintf("POLYGON ((%s %s, %s %s, %s %s, %s %s, %s %s))", w, s, e, s, e, n, w, n, w, s)
+}
+
+// kinds of problems found by Validate
+const (
+	ProblemRange     = "range"      // a coordinate is no valid longitude or latitude
+	ProblemTooFew    = "too-few"    // a ring has less than four points
+	ProblemNotClosed = "not-closed" // first and last point of a ring differ
+	ProblemWinding   = "winding"    // the exterior ring is not counterclockwise or a hole not clockwise
+)
+
+// Problem is a problem of a geometry found by Validate. Polygon and Ring are the
+// indices of the ring concerned, -1 for problems of the geometry as a whole.
+type Problem struct {
+	Kind    string
+	Polygon int
+	Ring    int
+}
+
+// Validate checks that all coordinates are valid longitudes and latitudes and
+// that the rings of polygons are closed and oriented by the right-hand rule of
+// GeoJSON (RFC 7946), that is exterior rings counterclockwise, holes clockwise
+func (g *Geometry) Validate() []Problem {
+	var problems []Problem
+	if !g.BBox().Valid() {
+		problems = append(problems, Problem{Kind: ProblemRange, Polygon: -1, Ring: -1})
+	}
+	if g.Type == TypeEnvelope {
+		return problems
+	}
+	for ipolygon, polygon := range g.Polygons {
+		for iring, ring := range polygon {
+			if len(ring) < 4 {
+				problems = append(problems, Problem{Kind: ProblemTooFew, Polygon: ipolygon, Ring: iring})
+			}
+			if !ring.Closed() {
+				problems = append(problems, Problem{Kind: ProblemNotClosed, Polygon: ipolygon, Ring: iring})
+				continue
+			}
+			if area := ring.Area(); (iring == 0 && area < 0) || (iring > 0 && area > 0) {
+				problems = append(problems, Problem{Kind: ProblemWinding, Polygon: ipolygon, Ring: iring})
+			}
+		}
+	}
+	return problems
+}
+
+// SyntaxError describes why a geometry could not be parsed
+type SyntaxError struct {
+	Format string
+	Offset int // position within the input, -1 if unknown
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	if e.Offset > -1 {
+		return fmt.Sprintf("Ungültige %s-Angabe an Position %d: %s", e.Format, e.Offset, e.Msg)
+	}
+	return fmt.Sprintf("Ungültige %s-Angabe: %s", e.Format, e.Msg)
+}
+
+// Parse reads the geometry in str, which is taken as GeoJSON if it starts with
+// '{' and as WKT otherwise
+func Parse(str string) (*Geometry, error) {
+	str = strings.TrimSpace(str)
+	if strings.HasPrefix(str, "{") {
+		return ParseGeoJSON([]byte(str))
+	}
+	return ParseWKT(str)
+}
@@ -0,0 +1,103 @@
+package geometry
+
+import (
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input  string
+		typ    string
+		format string
+		bbox   BBox
+	}{
+		{"POLYGON ((16.18 48.11, 16.58 48.11, 16.58 48.32, 16.18 48.32, 16.18 48.11))", TypePolygon, FormatWKT, BBox{16.18, 48.11, 16.58, 48.32}},
+		{"POLYGON (-180.00 -90.00, 180.00 90.00)", TypePolygon, FormatWKT, BBox{-180, -90, 180, 90}},
+		{"SRID=4326;POINT (16.37 48.21)", TypePoint, FormatWKT, BBox{16.37, 48.21, 16.37, 48.21}},
+		{"POINT Z (16.37 48.21 170)", TypePoint, FormatWKT, BBox{16.37, 48.21, 16.37, 48.21}},
+		{"MULTIPOLYGON (((9 47, 10 47, 10 48, 9 47)), ((16 48, 17 48, 17 49, 16 48), (16.2 48.2, 16.4 48.4, 16.4 48.2, 16.2 48.2)))", TypeMultiPolygon, FormatWKT, BBox{9, 47, 17, 49}},
+		{"ENVELOPE(9.53, 17.17, 49.02, 46.37)", TypeEnvelope, FormatWKT, Austria},
+		{`{"type": "Polygon", "coordinates": [[[16, 48], [17, 48], [17, 49], [16, 49], [16, 48]]]}`, TypePolygon, FormatGeoJSON, BBox{16, 48, 17, 49}},
+		{`{"type": "Feature", "properties": {}, "geometry": {"type": "Point", "coordinates": [16.37, 48.21]}}`, TypePoint, FormatGeoJSON, BBox{16.37, 48.21, 16.37, 48.21}},
+		{`{"type": "MultiPolygon", "coordinates": [[[[9, 47], [10, 47], [10, 48], [9, 47]]], [[[16, 48], [17, 48], [17, 49], [16, 48]]]]}`, TypeMultiPolygon, FormatGeoJSON, BBox{9, 47, 17, 49}},
+	}
+	for idx, test := range tests {
+		g, err := Parse(test.input)
+		if err != nil {
+			t.Errorf("TestParse [%d]: unexpected error %s", idx, err)
+			continue
+		}
+		if g.Type != test.typ || g.Format != test.format {
+			t.Errorf("TestParse [%d]: expected %s %s, got %s %s", idx, test.format, test.typ, g.Format, g.Type)
+		}
+		if b := g.BBox(); b != test.bbox {
+			t.Errorf("TestParse [%d]: expected bounding box %v, got %v", idx, test.bbox, b)
+		}
+	}
+
+	invalid := []string{
+		"",
+		"polygon ((16 48, 17 48, 17 49, 16 48))",
+		"POLYGON (-180,00 -90,00, 180.00 90.00)",
+		"POLYGON ((16 48, 17 48, 17 49, 16 48)",
+		"POLYGON EMPTY",
+		"LINESTRING (16 48, 17 49)",
+		"POINT (16.37 48.21) trailing",
+		`{"type": "LineString", "coordinates": [[16, 48], [17, 49]]}`,
+		`{"type": "Point", "coordinates": [16.37]}`,
+		`{"type": "Polygon"`,
+	}
+	for _, input := range invalid {
+		if _, err := Parse(input); err == nil {
+			t.Errorf("TestParse: expected '%s' to be rejected", input)
+		} else if _, ok := err.(*SyntaxError); !ok {
+			t.Errorf("TestParse: expected a SyntaxError for '%s', got %T", input, err)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		input    string
+		problems []string
+	}{
+		{"POLYGON ((16 48, 17 48, 17 49, 16 49, 16 48))", nil},
+		{"POLYGON ((16 48, 16 49, 17 49, 17 48, 16 48))", []string{ProblemWinding}},
+		{"POLYGON ((16 48, 17 48, 17 49, 16 49))", []string{ProblemNotClosed}},
+		{"POLYGON (-180.00 -90.00, 180.00 90.00)", []string{ProblemTooFew, ProblemNotClosed}},
+		{"POLYGON ((16 48, 17 48, 17 49, 16 49, 16 48), (16.2 48.2, 16.4 48.2, 16.4 48.4, 16.2 48.2))", []string{ProblemWinding}},
+		{"POLYGON ((16 48, 200 48, 200 49, 16 49, 16 48))", []string{ProblemRange}},
+		{"ENVELOPE(9.53, 17.17, 49.02, 46.37)", nil},
+		{"POINT (16.37 95)", []string{ProblemRange}},
+	}
+	for idx, test := range tests {
+		g, err := Parse(test.input)
+		if err != nil {
+			t.Errorf("TestValidate [%d]: unexpected error %s", idx, err)
+			continue
+		}
+		problems := g.Validate()
+		if len(problems) != len(test.problems) {
+			t.Errorf("TestValidate [%d]: expected problems %v, got %v", idx, test.problems, problems)
+			continue
+		}
+		for pidx, problem := range problems {
+			if problem.Kind != test.problems[pidx] {
+				t.Errorf("TestValidate [%d]: expected problems %v, got %v", idx, test.problems, problems)
+			}
+		}
+	}
+}
+
+func TestBBox(t *testing.T) {
+	vienna := BBox{West: 16.18, South: 48.11, East: 16.58, North: 48.32}
+	if !vienna.Intersects(Austria) || vienna.Swapped().Intersects(Austria) {
+		t.Errorf("TestBBox: expected Vienna to lie in Austria, but not when swapped")
+	}
+	if !vienna.Valid() || (BBox{West: 48, South: 100, East: 49, North: 101}).Valid() {
+		t.Errorf("TestBBox: unexpected validity of coordinates")
+	}
+	if s := vienna.String(); s != "POLYGON ((16.18 48.11, 16.58 48.11, 16.58 48.32, 16.18 48.32, 16.18 48.11))" {
+		t.Errorf("TestBBox: unexpected WKT %s", s)
+	}
+}
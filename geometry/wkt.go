@@ -0,0 +1,237 @@
+package geometry
+
+import (
+	"strconv"
+	"strings"
+)
+
+type wktparser struct {
+	str string
+	pos int
+}
+
+func (p *wktparser) errorf(msg string) error {
+	return &SyntaxError{Format: FormatWKT, Offset: p.pos, Msg: msg}
+}
+
+func (p *wktparser) skipspace() {
+	for p.pos < len(p.str) && strings.IndexByte(" \t\r\n", p.str[p.pos]) > -1 {
+		p.pos++
+	}
+}
+
+// peek returns the next character which is not white space, 0 at the end
+func (p *wktparser) peek() byte {
+	p.skipspace()
+	if p.pos < len(p.str) {
+		return p.str[p.pos]
+	}
+	return 0
+}
+
+func (p *wktparser) expect(c byte) error {
+	if p.peek() != c {
+		return p.errorf("'" + string(c) + "' erwartet")
+	}
+	p.pos++
+	return nil
+}
+
+// word returns the next keyword, which has to be given in upper case
+func (p *wktparser) word() (string, error) {
+	p.skipspace()
+	start := p.pos
+	for p.pos < len(p.str) && (p.str[p.pos] >= 'A' && p.str[p.pos] <= 'Z' || p.str[p.pos] >= 'a' && p.str[p.pos] <= 'z') {
+		p.pos++
+	}
+	word := p.str[start:p.pos]
+	if word != strings.ToUpper(word) {
+		p.pos = start
+		return "", p.errorf("Schlüsselwort in Großbuchstaben erwartet")
+	}
+	return word, nil
+}
+
+func isnumberstart(c byte) bool {
+	return c >= '0' && c <= '9' || c == '-' || c == '+' || c == '.'
+}
+
+func (p *wktparser) number() (float64, error) {
+	p.skipspace()
+	start := p.pos
+	for p.pos < len(p.str) && (isnumberstart(p.str[p.pos]) || p.str[p.pos] == 'e' || p.str[p.pos] == 'E') {
+		p.pos++
+	}
+	f, err := strconv.ParseFloat(p.str[start:p.pos], 64)
+	if err != nil {
+		p.pos = start
+		return 0, p.errorf("Zahl erwartet")
+	}
+	return f, nil
+}
+
+// point reads a position, further coordinates as Z and M are ignored
+func (p *wktparser) point() (Point, error) {
+	x, err := p.number()
+	if err != nil {
+		return Point{}, err
+	}
+	y, err := p.number()
+	if err != nil {
+		return Point{}, err
+	}
+	for isnumberstart(p.peek()) {
+		if _, err := p.number(); err != nil {
+			return Point{}, err
+		}
+	}
+	return Point{X: x, Y: y}, nil
+}
+
+// points reads points separated by commas up to the closing parenthesis
+func (p *wktparser) points() (Ring, error) {
+	var ring Ring
+	for {
+		point, err := p.point()
+		if err != nil {
+			return nil, err
+		}
+		ring = append(ring, point)
+		if p.peek() != ',' {
+			return ring, p.expect(')')
+		}
+		p.pos++
+	}
+}
+
+// polygon reads the rings of a polygon. A single ring without enclosing
+// parentheses, as in "POLYGON (16 48, 17 49)" accepted by version 2.1 of the
+// specification, is read as well.
+func (p *wktparser) polygon() (Polygon, error) {
+	if err := p.expect('('); err != nil {
+		return nil, err
+	}
+	if p.peek() != '(' {
+		ring, err := p.points()
+		return Polygon{ring}, err
+	}
+	var polygon Polygon
+	for {
+		p.pos++ // '('
+		ring, err := p.points()
+		if err != nil {
+			return nil, err
+		}
+		polygon = append(polygon, ring)
+		if p.peek() != ',' {
+			return polygon, p.expect(')')
+		}
+		p.pos++
+		if p.peek() != '(' {
+			return nil, p.errorf("'(' erwartet")
+		}
+	}
+}
+
+// envelope reads ENVELOPE(minx, maxx, maxy, miny) as used by OGC CQL
+func (p *wktparser) envelope() (Polygon, error) {
+	if err := p.expect('('); err != nil {
+		return nil, err
+	}
+	var bounds [4]float64
+	for idx := range bounds {
+		if idx > 0 {
+			if err := p.expect(','); err != nil {
+				return nil, err
+			}
+		}
+		f, err := p.number()
+		if err != nil {
+			return nil, err
+		}
+		bounds[idx] = f
+	}
+	if err := p.expect(')'); err != nil {
+		return nil, err
+	}
+	minx, maxx, maxy, miny := bounds[0], bounds[1], bounds[2], bounds[3]
+	return Polygon{Ring{{minx, miny}, {maxx, miny}, {maxx, maxy}, {minx, maxy}, {minx, miny}}}, nil
+}
+
+// ParseWKT reads a POINT, POLYGON, MULTIPOLYGON or ENVELOPE given as WKT. A
+// leading spatial reference as in EWKT ("SRID=4326;") is skipped.
+func ParseWKT(str string) (*Geometry, error) {
+	p := &wktparser{str: str}
+	if strings.HasPrefix(strings.ToUpper(strings.TrimSpace(str)), "SRID=") {
+		if idx := strings.IndexByte(str, ';'); idx > -1 {
+			p.pos = idx + 1
+		}
+	}
+
+	g := &Geometry{Format: FormatWKT}
+	keyword, err := p.word()
+	if err != nil {
+		return nil, err
+	}
+	tag, err := p.word()
+	if err != nil {
+		return nil, err
+	}
+	switch tag {
+	case "", "Z", "M", "ZM":
+	case "EMPTY":
+		return nil, p.errorf("leere Geometrie")
+	default:
+		return nil, p.errorf("unerwartet: " + tag)
+	}
+
+	switch keyword {
+	case "POINT":
+		g.Type = TypePoint
+		if err = p.expect('('); err == nil {
+			if g.Point, err = p.point(); err == nil {
+				err = p.expect(')')
+			}
+		}
+	case "POLYGON":
+		g.Type = TypePolygon
+		var polygon Polygon
+		if polygon, err = p.polygon(); err == nil {
+			g.Polygons = []Polygon{polygon}
+		}
+	case "MULTIPOLYGON":
+		g.Type = TypeMultiPolygon
+		if err = p.expect('('); err != nil {
+			break
+		}
+		for {
+			var polygon Polygon
+			if polygon, err = p.polygon(); err != nil {
+				break
+			}
+			g.Polygons = append(g.Polygons, polygon)
+			if p.peek() != ',' {
+				err = p.expect(')')
+				break
+			}
+			p.pos++
+		}
+	case "ENVELOPE":
+		g.Type = TypeEnvelope
+		var polygon Polygon
+		if polygon, err = p.envelope(); err == nil {
+			g.Polygons = []Polygon{polygon}
+		}
+	case "":
+		return nil, p.errorf("Geometrietyp erwartet")
+	default:
+		return nil, &SyntaxError{Format: FormatWKT, Offset: 0, Msg: "nicht unterstützter Geometrietyp " + keyword}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if p.peek() != 0 {
+		return nil, p.errorf("unerwartete Zeichen nach dem Ende der Geometrie")
+	}
+	return g, nil
+}
@@ -0,0 +1,188 @@
+// Package testutil holds helpers shared by the tests of the version packages
+package testutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// RoundTrip unmarshals every JSON document matching pattern into the value
+// returned by newmd, marshals it again and reports an error unless the result
+// equals the original document restricted to the members declared by the
+// type of the value. Documents which fail to unmarshal are reported as well.
+func RoundTrip(t *testing.T, pattern string, newmd func() interface{}) {
+	filenames, err := filepath.Glob(pattern)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filenames) == 0 {
+		t.Fatalf("TestRoundTrip: no documents match '%s'", pattern)
+	}
+
+	for _, filename := range filenames {
+		ogdjsonmd, err := ioutil.ReadFile(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		md := newmd()
+		if err := json.Unmarshal(ogdjsonmd, md); err != nil {
+			t.Errorf("TestRoundTrip (%s): Can't unmarshal: %s", filename, err)
+			continue
+		}
+
+		out, err := json.Marshal(md)
+		if err != nil {
+			t.Errorf("TestRoundTrip (%s): Can't marshal: %s", filename, err)
+			continue
+		}
+		var invalue, outvalue interface{}
+		if err := json.Unmarshal(ogdjsonmd, &invalue); err != nil {
+			t.Errorf("TestRoundTrip (%s): Can't unmarshal: %s", filename, err)
+			continue
+		}
+		if err := json.Unmarshal(out, &outvalue); err != nil {
+			t.Errorf("TestRoundTrip (%s): Can't unmarshal the marshalled metadata: %s", filename, err)
+			continue
+		}
+		invalue = declared(invalue, reflect.TypeOf(md))
+		if diff := jsondiff("", invalue, outvalue); diff != "" {
+			t.Errorf("TestRoundTrip (%s): metadata differs after round trip: %s", filename, diff)
+		}
+	}
+}
+
+var unmarshalertype = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// declared strips all object members from value which are not declared by
+// typ, as encoding/json ignores them when unmarshalling
+func declared(value interface{}, typ reflect.Type) interface{} {
+	for typ.Kind() == reflect.Ptr {
+		if typ.Implements(unmarshalertype) {
+			return value
+		}
+		typ = typ.Elem()
+	}
+	if reflect.PtrTo(typ).Implements(unmarshalertype) {
+		return value
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if typ.Kind() != reflect.Struct {
+			return value
+		}
+		fields := make(map[string]reflect.Type)
+		jsonfields(typ, fields)
+		members := make(map[string]interface{})
+		for key, val := range v {
+			if fieldtyp, ok := fields[key]; ok {
+				members[key] = declared(val, fieldtyp)
+			}
+		}
+		return members
+	case []interface{}:
+		if typ.Kind() != reflect.Slice && typ.Kind() != reflect.Array {
+			return value
+		}
+		elems := make([]interface{}, len(v))
+		for idx := range v {
+			elems[idx] = declared(v[idx], typ.Elem())
+		}
+		return elems
+	}
+	return value
+}
+
+// jsonfields collects the JSON member names of the struct typ together with
+// the types of their fields, promoting the fields of untagged embedded structs
+func jsonfields(typ reflect.Type, fields map[string]reflect.Type) {
+	for idx := 0; idx < typ.NumField(); idx++ {
+		field := typ.Field(idx)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				jsonfields(embedded, fields)
+				continue
+			}
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = field.Type
+	}
+}
+
+// jsondiff describes the first difference between the decoded JSON values in
+// and out, empty if there is none. Members holding null, an empty array or
+// an empty object count as absent, as omitempty drops them and the embedded
+// structs are marshalled even when empty.
+func jsondiff(path string, in, out interface{}) string {
+	switch i := in.(type) {
+	case map[string]interface{}:
+		o, ok := out.(map[string]interface{})
+		if !ok {
+			return fmt.Sprintf("%s: %v <-> %v", path, in, out)
+		}
+		for key, val := range i {
+			outval, ok := o[key]
+			if !ok {
+				if isempty(val) {
+					continue
+				}
+				return fmt.Sprintf("%s/%s: missing after round trip", path, key)
+			}
+			if diff := jsondiff(path+"/"+key, val, outval); diff != "" {
+				return diff
+			}
+		}
+		for key, val := range o {
+			if _, ok := i[key]; !ok && !isempty(val) {
+				return fmt.Sprintf("%s/%s: added by round trip", path, key)
+			}
+		}
+		return ""
+	case []interface{}:
+		o, ok := out.([]interface{})
+		if !ok || len(i) != len(o) {
+			return fmt.Sprintf("%s: %v <-> %v", path, in, out)
+		}
+		for idx := range i {
+			if diff := jsondiff(fmt.Sprintf("%s[%d]", path, idx), i[idx], o[idx]); diff != "" {
+				return diff
+			}
+		}
+		return ""
+	}
+	if !reflect.DeepEqual(in, out) {
+		return fmt.Sprintf("%s: %v <-> %v", path, in, out)
+	}
+	return ""
+}
+
+func isempty(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		return len(v) == 0
+	}
+	return false
+}
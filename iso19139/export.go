@@ -0,0 +1,552 @@
+// Package iso19139 converts OGD metadata to ISO 19139 XML, the encoding of
+// ISO 19115 metadata used by INSPIRE catalogues. The elements written for a
+// field are chosen by the ISO 19115 short names given in the specification.
+package iso19139
+
+import (
+	"fmt"
+	"github.com/the42/ogdat"
+	"github.com/the42/ogdat/geometry"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// element writes the value of the field named by the OGD_Kurzname field
+type element func(e *exporter, field string, value interface{})
+
+// elements maps the ISO 19115 short names of the specification, in lower case,
+// to the elements written. If a field names more than one short name, the
+// first one found here is used.
+var elements = map[string]element{
+	"mdfileid":     (*exporter).fileidentifier,
+	"mddatest":     (*exporter).datestamp,
+	"mdstanname":   (*exporter).standardname,
+	"mdlang":       (*exporter).metadatalanguage,
+	"mdc":          (*exporter).metadatacharacterset,
+	"restitle":     (*exporter).title,
+	"idabs":        (*exporter).abstract,
+	"keyword":      (*exporter).keywords,
+	"linkage":      (*exporter).linkage,
+	"formatname":   (*exporter).format,
+	"rpindname":    (*exporter).party,
+	"emailadd":     (*exporter).email,
+	"othconsts":    (*exporter).license,
+	"accessconsts": (*exporter).licensecitation,
+	"extemp":       (*exporter).temporal,
+	"membername":   (*exporter).attributes,
+	"refdate":      (*exporter).referencedate,
+	"identcode":    (*exporter).toponym,
+	"westbl":       (*exporter).bbox,
+	"maintfreq":    (*exporter).frequency,
+	"statement":    (*exporter).lineage,
+	"datalang":     (*exporter).language,
+	"datachar":     (*exporter).characterset,
+}
+
+// short names as "resTitle (360)"
+var regexpshortname = regexp.MustCompile(`([A-Za-z]+) \(\d`)
+
+// elementfor returns the element for the ISO 19115 column of the specification
+func elementfor(iso19115 string) (element, bool) {
+	for _, match := range regexpshortname.FindAllStringSubmatch(iso19115, -1) {
+		if el, ok := elements[strings.ToLower(match[1])]; ok {
+			return el, true
+		}
+	}
+	return nil, false
+}
+
+// specfor returns the specification of the version md was parsed for
+func specfor(md ogdat.Metadater) (*ogdat.OGDSet, error) {
+	for _, f := range ogdat.MetadataFactories() {
+		if f.New != nil && reflect.TypeOf(f.New()) == reflect.TypeOf(md) {
+			return f.Spec, nil
+		}
+	}
+	return nil, fmt.Errorf("Keine Spezifikation für Metadaten vom Typ %T registriert", md)
+}
+
+// OGD ID of metadata_modified, identical in all versions of the specification
+const idMetadataModified = 5
+
+type exporter struct {
+	root      *Element
+	resource  int // index of the resource a resource field is written for
+	transfers []*Element
+	dates     map[string]bool
+}
+
+// Export converts md into an ISO 19139 gmd:MD_Metadata document. Resources
+// become the transfer options of the distribution, the resource dates the
+// dates of the citation of the dataset.
+func Export(md ogdat.Metadater) (*Element, error) {
+	spec, err := specfor(md)
+	if err != nil {
+		return nil, err
+	}
+	root := newelement("gmd:MD_Metadata").
+		attr("xmlns:gmd", gmdns).
+		attr("xmlns:gco", gcons).
+		attr("xmlns:gml", gmlns)
+	e := &exporter{root: root, dates: make(map[string]bool)}
+	e.codelist(root.path("gmd:hierarchyLevel"), "MD_ScopeCode", "dataset")
+
+	res := resources(md)
+	for _, b := range spec.Beschreibung {
+		el, ok := elementfor(b.ISO19115)
+		if !ok {
+			continue
+		}
+		if !strings.HasPrefix(b.CKAN_Feld, "resources:") {
+			if value, ok := ogdat.FieldByID(md, b.ID); ok {
+				el(e, b.OGD_Kurzname, value)
+			}
+			continue
+		}
+		for idx, r := range res {
+			e.resource = idx
+			if value, ok := ogdat.FieldByID(r, b.ID); ok {
+				el(e, b.OGD_Kurzname, value)
+			}
+		}
+	}
+
+	identification := e.identification()
+	// the citation of the dataset requires a date
+	if len(e.dates) == 0 {
+		value, _ := ogdat.FieldByID(md, idMetadataModified)
+		if t, ok := e.date(value); ok {
+			e.citationdate(t, "revision")
+		}
+	}
+	if contact := root.path("gmd:contact"); len(contact.children) > 0 {
+		party := contact.children[0].clone()
+		identification.children = append([]*Element{{name: "gmd:pointOfContact", children: []*Element{party}}}, identification.children...)
+	}
+	identification.path("gmd:citation/gmd:CI_Citation/gmd:title")
+	return root, nil
+}
+
+// text returns the field as given in the document, "" if not present
+func text(value interface{}) string {
+	switch val := value.(type) {
+	case *string:
+		if val != nil {
+			return strings.TrimSpace(*val)
+		}
+	case *ogdat.ResourceSpecifier:
+		if val != nil {
+			return strings.TrimSpace(string(*val))
+		}
+	case *ogdat.Url:
+		if val != nil {
+			return val.Raw
+		}
+	case *ogdat.Identifier:
+		if val != nil {
+			return val.Raw
+		}
+	case *ogdat.Time:
+		if val != nil {
+			return val.Raw
+		}
+	}
+	return ""
+}
+
+func (e *exporter) identification() *Element {
+	return e.root.path("gmd:identificationInfo/gmd:MD_DataIdentification")
+}
+
+func (e *exporter) citation() *Element {
+	return e.identification().path("gmd:citation/gmd:CI_Citation")
+}
+
+func (e *exporter) distribution() *Element {
+	return e.root.path("gmd:distributionInfo/gmd:MD_Distribution")
+}
+
+// transfer returns the transfer options of the current resource
+func (e *exporter) transfer() *Element {
+	for len(e.transfers) <= e.resource {
+		e.transfers = append(e.transfers, e.distribution().add("gmd:transferOptions").path("gmd:MD_DigitalTransferOptions"))
+	}
+	return e.transfers[e.resource]
+}
+
+func characterstring(el *Element, s string) {
+	el.child("gco:CharacterString").text = s
+}
+
+func (e *exporter) codelist(el *Element, list, value string) {
+	el.child("gmd:"+list).
+		attr("codeList", codelists+list).
+		attr("codeListValue", value).text = value
+}
+
+// date returns the field as time, if present
+func (e *exporter) date(value interface{}) (*ogdat.Time, bool) {
+	t, ok := value.(*ogdat.Time)
+	return t, ok && t != nil && t.Raw != ""
+}
+
+// settime writes t as gco:Date, gco:DateTime if given with time of day
+func settime(el *Element, t *ogdat.Time) {
+	switch t.Format {
+	case ogdat.CustomTimeSpecifier2:
+		el.child("gco:Date").text = t.Raw
+	case "":
+		characterstring(el, t.Raw)
+	default:
+		el.child("gco:DateTime").text = t.Time.Format("2006-01-02T15:04:05")
+	}
+}
+
+func (e *exporter) fileidentifier(field string, value interface{}) {
+	if id := text(value); id != "" {
+		characterstring(e.root.path("gmd:fileIdentifier"), id)
+	}
+}
+
+func (e *exporter) datestamp(field string, value interface{}) {
+	if t, ok := e.date(value); ok {
+		settime(e.root.path("gmd:dateStamp"), t)
+	}
+}
+
+func (e *exporter) standardname(field string, value interface{}) {
+	if name := text(value); name != "" {
+		characterstring(e.root.path("gmd:metadataStandardName"), name)
+		if number := ogdat.OGDVersionfromString(name); number != "" {
+			characterstring(e.root.path("gmd:metadataStandardVersion"), number)
+		}
+	}
+}
+
+func (e *exporter) metadatalanguage(field string, value interface{}) {
+	if lang := strings.ToLower(text(value)); lang != "" {
+		e.codelist(e.root.path("gmd:language"), "LanguageCode", lang)
+	}
+}
+
+func (e *exporter) metadatacharacterset(field string, value interface{}) {
+	if cs := strings.ToLower(text(value)); cs != "" {
+		e.codelist(e.root.path("gmd:characterSet"), "MD_CharacterSetCode", cs)
+	}
+}
+
+func (e *exporter) title(field string, value interface{}) {
+	title := text(value)
+	if title == "" {
+		return
+	}
+	if field == "en_title_and_desc" {
+		characterstring(e.citation().add("gmd:alternateTitle"), title)
+		return
+	}
+	characterstring(e.citation().path("gmd:title"), title)
+}
+
+func (e *exporter) abstract(field string, value interface{}) {
+	if abstract := text(value); abstract != "" {
+		characterstring(e.identification().path("gmd:abstract"), abstract)
+	}
+}
+
+// keywords writes the tags as keywords, the categories as keywords of the
+// thesaurus of the OGD categories
+func (e *exporter) keywords(field string, value interface{}) {
+	var words []string
+	thesaurus := ""
+	switch val := value.(type) {
+	case []ogdat.Tags:
+		for _, tag := range val {
+			words = append(words, string(tag))
+		}
+	case *ogdat.MetaDataKategorie:
+		if val == nil {
+			return
+		}
+		for _, cat := range val.Kategorie {
+			words = append(words, cat.PrettyName)
+		}
+		thesaurus = "OGD Austria Kategorien"
+	}
+	if len(words) == 0 {
+		return
+	}
+	keywords := e.identification().add("gmd:descriptiveKeywords").path("gmd:MD_Keywords")
+	for _, word := range words {
+		characterstring(keywords.add("gmd:keyword"), word)
+	}
+	if thesaurus != "" {
+		e.codelist(keywords.path("gmd:type"), "MD_KeywordTypeCode", "theme")
+		citation := keywords.path("gmd:thesaurusName/gmd:CI_Citation")
+		characterstring(citation.path("gmd:title"), thesaurus)
+		citation.path("gmd:date").attr("gco:nilReason", "unknown")
+	}
+}
+
+func onlineresource(el *Element, link, function string) *Element {
+	resource := el.path("gmd:CI_OnlineResource")
+	resource.path("gmd:linkage/gmd:URL").text = link
+	if function != "" {
+		el := resource.path("gmd:function")
+		el.child("gmd:CI_OnLineFunctionCode").
+			attr("codeList", codelists+"CI_OnLineFunctionCode").
+			attr("codeListValue", function).text = function
+	}
+	return resource
+}
+
+// linkage writes the URL of a resource, the maintainer, the metadata or the original portal
+func (e *exporter) linkage(field string, value interface{}) {
+	switch field {
+	case "resource_url":
+		if link := text(value); link != "" {
+			onlineresource(e.transfer().path("gmd:onLine"), link, "download")
+		}
+	case "maintainer_link":
+		if link := text(value); link != "" {
+			onlineresource(e.contact("pointOfContact").path("gmd:contactInfo/gmd:CI_Contact/gmd:onlineResource"), link, "")
+		}
+	case "metadata_linkage":
+		if linkage, ok := value.(*ogdat.MetaDataLinkage); ok && linkage != nil {
+			for _, u := range linkage.Url {
+				if u.Raw != "" {
+					onlineresource(e.distribution().add("gmd:transferOptions").path("gmd:MD_DigitalTransferOptions/gmd:onLine"), u.Raw, "information")
+				}
+			}
+		}
+	case "metadata_original_portal":
+		if link := text(value); link != "" {
+			onlineresource(e.distribution().add("gmd:transferOptions").path("gmd:MD_DigitalTransferOptions/gmd:onLine"), link, "search")
+		}
+	}
+}
+
+// format writes the format of a resource, its name as name of the online resource
+func (e *exporter) format(field string, value interface{}) {
+	switch field {
+	case "resource_format":
+		format := strings.TrimLeft(text(value), ".")
+		if format == "" {
+			return
+		}
+		distributionformat := e.distribution().add("gmd:distributionFormat").path("gmd:MD_Format")
+		characterstring(distributionformat.path("gmd:name"), format)
+		distributionformat.path("gmd:version").attr("gco:nilReason", "unknown")
+	}
+}
+
+// contact returns the responsible party with role. The point of contact
+// is the contact of the metadata too.
+func (e *exporter) contact(role string) *Element {
+	var parent *Element
+	if role == "pointOfContact" {
+		parent = e.root.path("gmd:contact")
+	} else {
+		parent = e.identification().add("gmd:pointOfContact")
+	}
+	party := parent.path("gmd:CI_ResponsibleParty")
+	if len(party.path("gmd:role").children) == 0 {
+		e.codelist(party.path("gmd:role"), "CI_RoleCode", role)
+	}
+	return party
+}
+
+// party writes maintainer and publisher as organisation of the point of contact and the publisher
+func (e *exporter) party(field string, value interface{}) {
+	name := text(value)
+	if name == "" {
+		return
+	}
+	role := "pointOfContact"
+	if field == "publisher" {
+		role = "publisher"
+	}
+	characterstring(e.contact(role).path("gmd:organisationName"), name)
+}
+
+func (e *exporter) email(field string, value interface{}) {
+	if email := text(value); email != "" {
+		characterstring(e.contact("pointOfContact").path("gmd:contactInfo/gmd:CI_Contact/gmd:address/gmd:CI_Address/gmd:electronicMailAddress"), email)
+	}
+}
+
+func (e *exporter) constraints() *Element {
+	return e.identification().path("gmd:resourceConstraints/gmd:MD_LegalConstraints")
+}
+
+func (e *exporter) license(field string, value interface{}) {
+	if license := text(value); license != "" {
+		constraints := e.constraints()
+		e.codelist(constraints.path("gmd:useConstraints"), "MD_RestrictionCode", "otherRestrictions")
+		characterstring(constraints.add("gmd:otherConstraints"), license)
+	}
+}
+
+// licensecitation writes the required citation of the licence as a limitation of use
+func (e *exporter) licensecitation(field string, value interface{}) {
+	if citation := text(value); citation != "" {
+		characterstring(e.constraints().add("gmd:useLimitation"), citation)
+	}
+}
+
+func (e *exporter) extent() *Element {
+	return e.identification().path("gmd:extent/gmd:EX_Extent")
+}
+
+func (e *exporter) temporal(field string, value interface{}) {
+	t, ok := e.date(value)
+	if !ok {
+		return
+	}
+	period := e.extent().path("gmd:temporalElement/gmd:EX_TemporalExtent/gmd:extent/gml:TimePeriod")
+	if len(period.attrs) == 0 {
+		period.attr("gml:id", "extent")
+	}
+	position := "gml:beginPosition"
+	if field == "end_datetime" {
+		position = "gml:endPosition"
+	}
+	period.path(position).text = t.Raw
+}
+
+// attributes writes the description of the attributes as supplemental information
+func (e *exporter) attributes(field string, value interface{}) {
+	if desc := text(value); desc != "" {
+		characterstring(e.identification().path("gmd:supplementalInformation"), desc)
+	}
+}
+
+// referencedate writes the date a resource was created or last modified as
+// date of the citation, each date and type only once
+func (e *exporter) referencedate(field string, value interface{}) {
+	t, ok := e.date(value)
+	if !ok {
+		return
+	}
+	typ := "creation"
+	if field == "resource_lastmodified" {
+		typ = "revision"
+	}
+	e.citationdate(t, typ)
+}
+
+func (e *exporter) citationdate(t *ogdat.Time, typ string) {
+	if e.dates[typ+t.Raw] {
+		return
+	}
+	e.dates[typ+t.Raw] = true
+	date := e.citation().add("gmd:date").path("gmd:CI_Date")
+	settime(date.path("gmd:date"), t)
+	e.codelist(date.path("gmd:dateType"), "CI_DateTypeCode", typ)
+}
+
+func (e *exporter) toponym(field string, value interface{}) {
+	if toponym := text(value); toponym != "" {
+		characterstring(e.extent().add("gmd:geographicElement").path("gmd:EX_GeographicDescription/gmd:geographicIdentifier/gmd:MD_Identifier/gmd:code"), toponym)
+	}
+}
+
+// bbox writes the bounding box enclosing the geographic extent
+func (e *exporter) bbox(field string, value interface{}) {
+	west, east, south, north, ok := parsebbox(text(value))
+	if !ok {
+		return
+	}
+	box := e.extent().add("gmd:geographicElement").path("gmd:EX_GeographicBoundingBox")
+	for _, bound := range []struct {
+		name  string
+		value float64
+	}{{"gmd:westBoundLongitude", west}, {"gmd:eastBoundLongitude", east}, {"gmd:southBoundLatitude", south}, {"gmd:northBoundLatitude", north}} {
+		box.path(bound.name + "/gco:Decimal").text = strconv.FormatFloat(bound.value, 'f', -1, 64)
+	}
+}
+
+// parsebbox returns the bounds of the geographic extent, given as WKT or
+// GeoJSON polygon or envelope
+func parsebbox(str string) (west, east, south, north float64, ok bool) {
+	g, err := geometry.Parse(str)
+	if err != nil || g.Type == geometry.TypePoint {
+		return
+	}
+	b := g.BBox()
+	return b.West, b.East, b.South, b.North, true
+}
+
+// frequency writes the cycle as MD_MaintenanceFrequencyCode
+func (e *exporter) frequency(field string, value interface{}) {
+	cyc, ok := value.(*ogdat.Cycle)
+	if !ok || cyc == nil || cyc.MD_MaintenanceFrequencyCode == "" {
+		return
+	}
+	e.codelist(e.identification().path("gmd:resourceMaintenance/gmd:MD_MaintenanceInformation/gmd:maintenanceAndUpdateFrequency"), "MD_MaintenanceFrequencyCode", cyc.MD_MaintenanceFrequencyCode)
+}
+
+func (e *exporter) lineage(field string, value interface{}) {
+	statement := text(value)
+	if statement == "" {
+		return
+	}
+	quality := e.root.path("gmd:dataQualityInfo/gmd:DQ_DataQuality")
+	e.codelist(quality.path("gmd:scope/gmd:DQ_Scope/gmd:level"), "MD_ScopeCode", "dataset")
+	characterstring(quality.path("gmd:lineage/gmd:LI_Lineage/gmd:statement"), statement)
+}
+
+// language writes the language of a resource as language of the dataset, each language only once
+func (e *exporter) language(field string, value interface{}) {
+	lang := strings.ToLower(text(value))
+	if lang == "" {
+		return
+	}
+	identification := e.identification()
+	for _, c := range identification.children {
+		if c.name == "gmd:language" && c.children[0].text == lang {
+			return
+		}
+	}
+	e.codelist(identification.add("gmd:language"), "LanguageCode", lang)
+}
+
+// characterset writes the encoding of a resource as character set of the dataset, each only once
+func (e *exporter) characterset(field string, value interface{}) {
+	cs := strings.ToLower(text(value))
+	if cs == "" {
+		return
+	}
+	identification := e.identification()
+	for _, c := range identification.children {
+		if c.name == "gmd:characterSet" && c.children[0].text == cs {
+			return
+		}
+	}
+	e.codelist(identification.add("gmd:characterSet"), "MD_CharacterSetCode", cs)
+}
+
+// resources returns the resources of md, the elements of the field tagged as json:"resources"
+func resources(md interface{}) (res []interface{}) {
+	val := reflect.ValueOf(md)
+	for val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			return nil
+		}
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Struct {
+		return nil
+	}
+	for i := 0; i < val.NumField(); i++ {
+		f := val.Type().Field(i)
+		if strings.Split(f.Tag.Get("json"), ",")[0] != "resources" || f.Type.Kind() != reflect.Slice {
+			continue
+		}
+		for j := 0; j < val.Field(i).Len(); j++ {
+			res = append(res, val.Field(i).Index(j).Addr().Interface())
+		}
+	}
+	return res
+}
@@ -0,0 +1,96 @@
+package iso19139
+
+import (
+	"bytes"
+	"encoding/xml"
+	"github.com/the42/ogdat/ogdatv23"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func exportfile(t *testing.T, filename string) *Element {
+	file, err := os.Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	md, err := ogdatv23.MetadatafromJSONStream(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := Export(md)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+// find returns the text of the first element along path, "" if there is none
+func find(el *Element, path string) string {
+	for _, name := range strings.Split(path, "/") {
+		var next *Element
+		for _, c := range el.children {
+			if c.name == name {
+				next = c
+				break
+			}
+		}
+		if next == nil {
+			return ""
+		}
+		el = next
+	}
+	return el.text
+}
+
+func TestExport(t *testing.T) {
+	doc := exportfile(t, "../ogdatv23/testfiles/fullandok.json")
+
+	identification := "gmd:identificationInfo/gmd:MD_DataIdentification/"
+	expected := map[string]string{
+		"gmd:fileIdentifier/gco:CharacterString": "0045692c-00e7-4e46-8bfc-336a92bd51e9",
+		"gmd:dateStamp/gco:Date":                 "2012-10-17",
+		identification + "gmd:citation/gmd:CI_Citation/gmd:title/gco:CharacterString":                                                                                          "Informationen über ACME county",
+		identification + "gmd:resourceMaintenance/gmd:MD_MaintenanceInformation/gmd:maintenanceAndUpdateFrequency/gmd:MD_MaintenanceFrequencyCode":                             "monthly",
+		identification + "gmd:extent/gmd:EX_Extent/gmd:geographicElement/gmd:EX_GeographicDescription/gmd:geographicIdentifier/gmd:MD_Identifier/gmd:code/gco:CharacterString": "geographic_toponym: ACME country",
+		"gmd:distributionInfo/gmd:MD_Distribution/gmd:distributionFormat/gmd:MD_Format/gmd:name/gco:CharacterString":                                                           "csv",
+	}
+	for path, value := range expected {
+		if text := find(doc, path); text != value {
+			t.Errorf("TestExport: expected '%s' at %s, got '%s'", value, path, text)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := doc.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "<gmd:westBoundLongitude>\n") {
+		t.Errorf("TestExport: missing bounding box:\n%s", buf.String())
+	}
+	// the children of MD_Metadata must be written in the order of the schema
+	if strings.Index(buf.String(), "<gmd:fileIdentifier>") > strings.Index(buf.String(), "<gmd:hierarchyLevel>") {
+		t.Errorf("TestExport: children of gmd:MD_Metadata not in schema order")
+	}
+	dec := xml.NewDecoder(&buf)
+	for {
+		if _, err := dec.Token(); err != nil {
+			if err != io.EOF {
+				t.Errorf("TestExport: invalid XML: %s", err)
+			}
+			break
+		}
+	}
+}
+
+func TestParseBBox(t *testing.T) {
+	west, east, south, north, ok := parsebbox("POLYGON ((16.18 48.11, 16.58 48.11, 16.58 48.32, 16.18 48.32, 16.18 48.11))")
+	if !ok || west != 16.18 || east != 16.58 || south != 48.11 || north != 48.32 {
+		t.Errorf("TestParseBBox: unexpected bounds %v %v %v %v", west, east, south, north)
+	}
+	if _, _, _, _, ok := parsebbox("POINT (16.18 48.11)"); ok {
+		t.Errorf("TestParseBBox: expected POINT to be rejected")
+	}
+}
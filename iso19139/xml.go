@@ -0,0 +1,149 @@
+package iso19139
+
+import (
+	"encoding/xml"
+	"io"
+	"sort"
+	"strings"
+)
+
+const (
+	gmdns = "http://www.isotc211.org/2005/gmd"
+	gcons = "http://www.isotc211.org/2005/gco"
+	gmlns = "http://www.opengis.net/gml"
+)
+
+const codelists = "http://standards.iso.org/iso/19139/resources/gmxCodelists.xml#"
+
+// Element is an element of an ISO 19139 document. Elements are named by their
+// qualified name, e.g. "gmd:title".
+type Element struct {
+	name     string
+	attrs    []xml.Attr
+	text     string
+	children []*Element
+}
+
+func newelement(name string) *Element {
+	return &Element{name: name}
+}
+
+// child returns the first child named name, which is created if missing
+func (el *Element) child(name string) *Element {
+	for _, c := range el.children {
+		if c.name == name {
+			return c
+		}
+	}
+	return el.add(name)
+}
+
+// add appends a new child named name
+func (el *Element) add(name string) *Element {
+	c := newelement(name)
+	el.children = append(el.children, c)
+	return c
+}
+
+// path follows child along the names separated by "/"
+func (el *Element) path(path string) *Element {
+	for _, name := range strings.Split(path, "/") {
+		el = el.child(name)
+	}
+	return el
+}
+
+func (el *Element) attr(name, value string) *Element {
+	el.attrs = append(el.attrs, xml.Attr{Name: xml.Name{Local: name}, Value: value})
+	return el
+}
+
+// clone returns a deep copy of el
+func (el *Element) clone() *Element {
+	c := &Element{name: el.name, attrs: append([]xml.Attr(nil), el.attrs...), text: el.text}
+	for _, child := range el.children {
+		c.children = append(c.children, child.clone())
+	}
+	return c
+}
+
+// sequences lists the children of the ISO 19139 types used in the order the
+// schema requires. Children are sorted accordingly when written.
+var sequences = map[string][]string{
+	"gmd:MD_Metadata": {"gmd:fileIdentifier", "gmd:language", "gmd:characterSet", "gmd:hierarchyLevel",
+		"gmd:contact", "gmd:dateStamp", "gmd:metadataStandardName", "gmd:metadataStandardVersion",
+		"gmd:identificationInfo", "gmd:contentInfo", "gmd:distributionInfo", "gmd:dataQualityInfo"},
+	"gmd:MD_DataIdentification": {"gmd:citation", "gmd:abstract", "gmd:pointOfContact", "gmd:resourceMaintenance",
+		"gmd:descriptiveKeywords", "gmd:resourceConstraints", "gmd:language", "gmd:characterSet",
+		"gmd:extent", "gmd:supplementalInformation"},
+	"gmd:CI_Citation":               {"gmd:title", "gmd:alternateTitle", "gmd:date", "gmd:identifier"},
+	"gmd:CI_ResponsibleParty":       {"gmd:individualName", "gmd:organisationName", "gmd:contactInfo", "gmd:role"},
+	"gmd:CI_Contact":                {"gmd:address", "gmd:onlineResource"},
+	"gmd:MD_Keywords":               {"gmd:keyword", "gmd:type", "gmd:thesaurusName"},
+	"gmd:MD_LegalConstraints":       {"gmd:useLimitation", "gmd:accessConstraints", "gmd:useConstraints", "gmd:otherConstraints"},
+	"gmd:EX_Extent":                 {"gmd:description", "gmd:geographicElement", "gmd:temporalElement"},
+	"gmd:MD_Distribution":           {"gmd:distributionFormat", "gmd:transferOptions"},
+	"gmd:MD_Format":                 {"gmd:name", "gmd:version"},
+	"gmd:MD_DigitalTransferOptions": {"gmd:transferSize", "gmd:onLine"},
+	"gmd:CI_OnlineResource":         {"gmd:linkage", "gmd:protocol", "gmd:name", "gmd:description", "gmd:function"},
+	"gmd:DQ_DataQuality":            {"gmd:scope", "gmd:lineage"},
+	"gmd:EX_GeographicBoundingBox": {"gmd:westBoundLongitude", "gmd:eastBoundLongitude",
+		"gmd:southBoundLatitude", "gmd:northBoundLatitude"},
+	"gml:TimePeriod": {"gml:beginPosition", "gml:endPosition"},
+}
+
+func (el *Element) sort() {
+	seq, ok := sequences[el.name]
+	if ok {
+		rank := func(name string) int {
+			for idx, n := range seq {
+				if n == name {
+					return idx
+				}
+			}
+			return len(seq)
+		}
+		sort.SliceStable(el.children, func(i, j int) bool {
+			return rank(el.children[i].name) < rank(el.children[j].name)
+		})
+	}
+	for _, c := range el.children {
+		c.sort()
+	}
+}
+
+func (el *Element) encode(enc *xml.Encoder) error {
+	start := xml.StartElement{Name: xml.Name{Local: el.name}, Attr: el.attrs}
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+	if el.text != "" {
+		if err := enc.EncodeToken(xml.CharData(el.text)); err != nil {
+			return err
+		}
+	}
+	for _, c := range el.children {
+		if err := c.encode(enc); err != nil {
+			return err
+		}
+	}
+	return enc.EncodeToken(start.End())
+}
+
+// Write writes the document el is the root element of as indented XML
+func (el *Element) Write(w io.Writer) error {
+	el.sort()
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := el.encode(enc); err != nil {
+		return err
+	}
+	if err := enc.Flush(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,125 @@
+package ogdat
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// JSONSchemaDraft is the version of JSON Schema generated by JSONSchema
+const JSONSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+type jsonschema map[string]interface{}
+
+// schemaproperties refine the schema of fields, identified by OGD_Kurzname,
+// beyond the structure given by the specification
+var schemaproperties = map[string]func() jsonschema{
+	"metadata_identifier": func() jsonschema { return jsonschema{"format": "uuid"} },
+	"categorization": func() jsonschema {
+		ids := make([]string, len(Categories))
+		for idx, cat := range Categories {
+			ids[idx] = cat.ID
+		}
+		return jsonschema{"minItems": 1, "items": jsonschema{"type": "string", "enum": ids}}
+	},
+	"update_frequency": func() jsonschema {
+		codes := make([]string, len(cycles))
+		for idx, cyc := range cycles {
+			codes[idx] = cyc.MD_MaintenanceFrequencyCode
+		}
+		return jsonschema{"enum": codes}
+	},
+	"metadata_linkage":         func() jsonschema { return jsonschema{"items": jsonschema{"type": "string", "format": "uri"}} },
+	"resource_url":             func() jsonschema { return jsonschema{"format": "uri"} },
+	"maintainer_link":          func() jsonschema { return jsonschema{"format": "uri"} },
+	"metadata_original_portal": func() jsonschema { return jsonschema{"format": "uri"} },
+	"maintainer_email":         func() jsonschema { return jsonschema{"format": "email"} },
+	"resource_language":        func() jsonschema { return jsonschema{"pattern": "^[a-z]{3}$"} },
+}
+
+var timepatternreplacer = strings.NewReplacer("2006", `\d{4}`, "01", `\d{2}`, "02", `\d{2}`, "15", `\d{2}`, "04", `\d{2}`, "05", `\d{2}`)
+
+// timepattern returns a regular expression matching times in format, one of TimeFormat
+func timepattern(format string) string {
+	return "^" + timepatternreplacer.Replace(regexp.QuoteMeta(format)) + "$"
+}
+
+// schemafield returns the JSON object ("", "extras" or "resources") and the key
+// of a CKAN field as given in the specification, e.g. "extras:categorization[“…“,“…“]"
+func schemafield(field string) (object, key string) {
+	field = normalizeCKANField(field)
+	if idx := strings.IndexRune(field, ':'); idx > -1 {
+		return field[:idx], field[idx+1:]
+	}
+	return "", field
+}
+
+// JSONSchema returns a JSON Schema (draft 2020-12) of the CKAN package holding
+// the metadata according to the specification set. Fields are described by
+// their name and definition, required fields are listed as required, optional
+// fields may be null. Fields with cardinality (Anzahl) N are arrays, unless part
+// of a resource. Categories and update frequencies are restricted to the values
+// of the specification, dates and times to the format the specification
+// requires for the field.
+func JSONSchema(set *OGDSet) ([]byte, error) {
+	objects := map[string]jsonschema{"": {}, "extras": {}, "resources": {}}
+	required := make(map[string][]string)
+	if set != nil {
+		for _, desc := range set.Beschreibung {
+			object, key := schemafield(desc.CKAN_Feld)
+			if _, ok := objects[object]; !ok {
+				continue
+			}
+			property := jsonschema{"title": desc.Bezeichner, "description": desc.Definition_DE, "type": "string"}
+			if object != "resources" && desc.Anzahl == "N" {
+				property["type"], property["items"] = "array", jsonschema{"type": "string"}
+			}
+			if timeformat, ok := timeformats[desc.OGD_Kurzname]; ok {
+				property["pattern"] = timepattern(timeformat.format)
+			}
+			if refine, ok := schemaproperties[desc.OGD_Kurzname]; ok {
+				for k, v := range refine() {
+					property[k] = v
+				}
+			}
+			if desc.IsRequired() {
+				required[object] = append(required[object], key)
+			} else {
+				// CKAN gives optional fields without value as null
+				property["type"] = []string{property["type"].(string), "null"}
+				if enum, ok := property["enum"].([]string); ok {
+					values := make([]interface{}, 0, len(enum)+1)
+					for _, v := range enum {
+						values = append(values, v)
+					}
+					property["enum"] = append(values, nil)
+				}
+			}
+			objects[object][key] = property
+		}
+	}
+
+	objectschema := func(object string) jsonschema {
+		s := jsonschema{"type": "object", "properties": objects[object]}
+		if len(required[object]) > 0 {
+			s["required"] = required[object]
+		}
+		return s
+	}
+	objects[""]["extras"] = objectschema("extras")
+	resources := jsonschema{"type": "array", "items": objectschema("resources")}
+	if len(required["resources"]) > 0 {
+		resources["minItems"] = 1
+	}
+	objects[""]["resources"] = resources
+	for _, object := range []string{"extras", "resources"} {
+		if len(required[object]) > 0 {
+			required[""] = append(required[""], object)
+		}
+	}
+
+	root := objectschema("")
+	root["$schema"] = JSONSchemaDraft
+	root["title"] = specversion(set)
+	return json.MarshalIndent(root, "", "  ")
+}
@@ -0,0 +1,209 @@
+package ogdat
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"sync"
+)
+
+// Language is an entry of the ISO 639-2 code list as published by the Library
+// of Congress at http://www.loc.gov/standards/iso639-2/ISO-639-2_utf-8.txt
+type Language struct {
+	Bibliographic string // ISO 639-2/B, e.g. "ger"
+	Terminology   string // ISO 639-2/T, e.g. "deu"; empty if equal to Bibliographic
+	Alpha2        string // ISO 639-1, e.g. "de"; empty if there is none
+	English       string // English name, e.g. "German"
+	French        string // French name, e.g. "allemand"
+}
+
+// LanguageRegistry resolves ISO 639-1 and ISO 639-2 codes to languages.
+// A LanguageRegistry is read-only after creation and thus safe for concurrent use.
+type LanguageRegistry struct {
+	languages []*Language
+	byalpha3  map[string]*Language
+	byalpha2  map[string]*Language
+	reserved  [2]string // range of codes reserved for local use, e.g. "qaa" - "qtz"
+	local     *Language
+}
+
+// NewLanguageRegistry reads the ISO 639-2 code list in the pipe separated
+// format of the Library of Congress from reader
+func NewLanguageRegistry(reader io.Reader) (*LanguageRegistry, error) {
+	reg := &LanguageRegistry{byalpha3: make(map[string]*Language), byalpha2: make(map[string]*Language)}
+
+	csvreader := csv.NewReader(reader)
+	csvreader.Comma = '|'
+	csvreader.FieldsPerRecord = 5
+
+	for record, err := csvreader.Read(); err != io.EOF; record, err = csvreader.Read() {
+		if err != nil {
+			return nil, err
+		}
+		lang := &Language{
+			Bibliographic: strings.TrimPrefix(record[0], "\ufeff"),
+			Terminology:   record[1],
+			Alpha2:        record[2],
+			English:       record[3],
+			French:        record[4]}
+
+		if codes := strings.Split(lang.Bibliographic, "-"); len(codes) == 2 {
+			reg.reserved = [2]string{codes[0], codes[1]}
+			reg.local = lang
+			continue
+		}
+		reg.languages = append(reg.languages, lang)
+		reg.byalpha3[lang.Bibliographic] = lang
+		if lang.Terminology != "" {
+			reg.byalpha3[lang.Terminology] = lang
+		}
+		if lang.Alpha2 != "" {
+			reg.byalpha2[lang.Alpha2] = lang
+		}
+	}
+	return reg, nil
+}
+
+// Len returns the number of languages known to the registry
+func (reg *LanguageRegistry) Len() int {
+	return len(reg.languages)
+}
+
+// Lookup returns the language for the three letter ISO 639-2 code, either
+// bibliographic (B) or terminology (T). Codes reserved for local use resolve
+// to the respective entry of the code list.
+func (reg *LanguageRegistry) Lookup(code string) (*Language, bool) {
+	if lang, ok := reg.byalpha3[code]; ok {
+		return lang, true
+	}
+	if reg.local != nil && len(code) == 3 && code >= reg.reserved[0] && code <= reg.reserved[1] {
+		return reg.local, true
+	}
+	return nil, false
+}
+
+// LookupISO6391 returns the language for the two letter ISO 639-1 code
+func (reg *LanguageRegistry) LookupISO6391(code string) (*Language, bool) {
+	lang, ok := reg.byalpha2[code]
+	return lang, ok
+}
+
+// Name returns the English name of the language given as ISO 639-1 or ISO 639-2
+// code, or code itself if it is unknown
+func (reg *LanguageRegistry) Name(code string) string {
+	lookup := reg.Lookup
+	if len(code) == 2 {
+		lookup = reg.LookupISO6391
+	}
+	if lang, ok := lookup(code); ok {
+		return lang.English
+	}
+	return code
+}
+
+// EncodingRegistry holds the names and aliases of the character sets registered
+// at IANA, cf. http://www.iana.org/assignments/character-sets/character-sets.xml
+// An EncodingRegistry is read-only after creation and thus safe for concurrent use.
+type EncodingRegistry struct {
+	names map[string]struct{}
+}
+
+// NewEncodingRegistry reads the character set names from reader, one name per line
+func NewEncodingRegistry(reader io.Reader) (*EncodingRegistry, error) {
+	reg := &EncodingRegistry{names: make(map[string]struct{})}
+
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		// normalize by lower casing
+		if line := strings.ToLower(strings.TrimSpace(scanner.Text())); line != "" {
+			reg.names[line] = struct{}{}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return reg, nil
+}
+
+// Len returns the number of character set names known to the registry
+func (reg *EncodingRegistry) Len() int {
+	return len(reg.names)
+}
+
+// Contains reports whether enc is a registered character set name. The comparison
+// is case insensitive, a second attempt is made with all "-" removed from enc.
+func (reg *EncodingRegistry) Contains(enc string) bool {
+	enc = strings.ToLower(enc)
+	_, ok := reg.names[enc]
+	if !ok {
+		_, ok = reg.names[strings.Replace(enc, "-", "", -1)]
+	}
+	return ok
+}
+
+// the default registries are loaded on first use, and again after the data directory changed
+var referenceregistries = struct {
+	sync.Mutex
+	languages *LanguageRegistry
+	encodings *EncodingRegistry
+	gazetteer *Gazetteer
+	licenses  *LicenseRegistry
+	formats   *FormatRegistry
+}{}
+
+func resetreferenceregistries() {
+	referenceregistries.Lock()
+	defer referenceregistries.Unlock()
+	referenceregistries.languages = nil
+	referenceregistries.encodings = nil
+	referenceregistries.gazetteer = nil
+	referenceregistries.licenses = nil
+	referenceregistries.formats = nil
+}
+
+// Languages returns the registry of ISO 639 languages as embedded into the
+// library, or as found in DataDir
+func Languages() (*LanguageRegistry, error) {
+	referenceregistries.Lock()
+	defer referenceregistries.Unlock()
+
+	if referenceregistries.languages == nil {
+		reader, err := opendatafile(referencefs, iso639file)
+		if err != nil {
+			return nil, fmt.Errorf("Can not load ISO language file '%s': %s", iso639file, err)
+		}
+		defer reader.Close()
+		reg, err := NewLanguageRegistry(reader)
+		if err != nil {
+			return nil, fmt.Errorf("Can not load ISO language file '%s': %s", iso639file, err)
+		}
+		log.Printf("Info: Read %d ISO language records", reg.Len())
+		referenceregistries.languages = reg
+	}
+	return referenceregistries.languages, nil
+}
+
+// Encodings returns the registry of IANA character sets as embedded into the
+// library, or as found in DataDir
+func Encodings() (*EncodingRegistry, error) {
+	referenceregistries.Lock()
+	defer referenceregistries.Unlock()
+
+	if referenceregistries.encodings == nil {
+		reader, err := opendatafile(referencefs, ianaencfile)
+		if err != nil {
+			return nil, fmt.Errorf("Can not load IANA encoding definition file '%s': %s", ianaencfile, err)
+		}
+		defer reader.Close()
+		reg, err := NewEncodingRegistry(reader)
+		if err != nil {
+			return nil, fmt.Errorf("Can not load IANA encoding definition file '%s': %s", ianaencfile, err)
+		}
+		log.Printf("Info: Read %d IANA encoding names", reg.Len())
+		referenceregistries.encodings = reg
+	}
+	return referenceregistries.encodings, nil
+}
@@ -0,0 +1,145 @@
+package ogdat
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"strings"
+	"unicode"
+)
+
+// License is a license open data is commonly published under
+type License struct {
+	ID          string   // short identifier, e.g. "CC-BY-3.0-AT"
+	Name        string   // the name of the license, e.g. "Creative Commons Namensnennung 3.0 Österreich"
+	URI         string   // the URI of the license document
+	Variants    []string // further names and URIs of the license
+	Attribution bool     // whether the license requires to name the originator, cf. license_citation
+}
+
+// LicenseRegistry recognises licenses by their URI, name or identifier.
+// A LicenseRegistry is read-only after creation and thus safe for concurrent use.
+type LicenseRegistry struct {
+	licenses []*License
+	byname   map[string]*License
+	byuri    map[string]*License
+}
+
+// NewLicenseRegistry reads the licenses from reader, one license per line given
+// as ID|Name|URI|Varianten|Namensnennung with the variants separated by ';' and
+// Namensnennung being "ja" or "nein". Lines starting with '#' are comments.
+func NewLicenseRegistry(reader io.Reader) (*LicenseRegistry, error) {
+	reg := &LicenseRegistry{byname: make(map[string]*License), byuri: make(map[string]*License)}
+
+	csvreader := csv.NewReader(reader)
+	csvreader.Comma = '|'
+	csvreader.Comment = '#'
+	csvreader.FieldsPerRecord = 5
+
+	for record, err := csvreader.Read(); err != io.EOF; record, err = csvreader.Read() {
+		if err != nil {
+			return nil, err
+		}
+		license := &License{ID: strings.TrimPrefix(record[0], "\ufeff"), Name: record[1], URI: record[2], Attribution: record[4] == "ja"}
+		if record[3] != "" {
+			license.Variants = strings.Split(record[3], ";")
+		}
+		reg.licenses = append(reg.licenses, license)
+		for _, name := range append([]string{license.ID, license.Name, license.URI}, license.Variants...) {
+			if key, ok := licenseurikey(name); ok {
+				reg.byuri[key] = license
+			} else {
+				reg.byname[licensenamekey(name)] = license
+			}
+		}
+	}
+	return reg, nil
+}
+
+// Len returns the number of licenses known to the registry
+func (reg *LicenseRegistry) Len() int {
+	return len(reg.licenses)
+}
+
+// Lookup returns the license given by its URI, name or identifier. URIs are
+// compared regardless of scheme, "www.", a trailing "/" and the language
+// specific pages "deed.xx" and "legalcode"; names regardless of case,
+// punctuation and the spelling of umlauts.
+func (reg *LicenseRegistry) Lookup(value string) (*License, bool) {
+	if key, ok := licenseurikey(value); ok {
+		license, ok := reg.byuri[key]
+		return license, ok
+	}
+	license, ok := reg.byname[licensenamekey(value)]
+	return license, ok
+}
+
+// IsLicenseURI reports whether value is an absolute http or https URI
+func IsLicenseURI(value string) bool {
+	_, ok := licenseurikey(value)
+	return ok
+}
+
+// licenseurikey returns the URI value reduced to host and path, ok is false if
+// value is no absolute http or https URI
+func licenseurikey(value string) (key string, ok bool) {
+	u, err := url.Parse(strings.TrimSpace(value))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", false
+	}
+	path := strings.TrimRight(u.Path, "/")
+	if idx := strings.LastIndex(path, "/"); idx > -1 {
+		if last := path[idx+1:]; strings.HasPrefix(last, "deed.") || strings.HasPrefix(last, "legalcode") {
+			path = path[:idx]
+		}
+	}
+	return strings.ToLower(strings.TrimPrefix(u.Host, "www.") + path), true
+}
+
+// licensenamekey lower cases name, spells out umlauts and drops everything
+// but letters and digits, thus "CC BY 3.0 AT" and "CC-BY-3.0-AT" are equal
+func licensenamekey(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, umlauts.Replace(strings.ToLower(name)))
+}
+
+// NormalizeLicense returns the identifier of the license given by value, or
+// value itself, without surrounding white space, if the license is unknown
+func NormalizeLicense(value string) (string, error) {
+	reg, err := Licenses()
+	if err != nil {
+		return "", err
+	}
+	if license, ok := reg.Lookup(value); ok {
+		return license.ID, nil
+	}
+	return strings.TrimSpace(value), nil
+}
+
+// Licenses returns the registry of licenses as embedded into the library, or
+// as found in DataDir
+func Licenses() (*LicenseRegistry, error) {
+	referenceregistries.Lock()
+	defer referenceregistries.Unlock()
+
+	if referenceregistries.licenses == nil {
+		reader, err := opendatafile(referencefs, licensefile)
+		if err != nil {
+			return nil, fmt.Errorf("Can not load license file '%s': %s", licensefile, err)
+		}
+		defer reader.Close()
+		reg, err := NewLicenseRegistry(reader)
+		if err != nil {
+			return nil, fmt.Errorf("Can not load license file '%s': %s", licensefile, err)
+		}
+		log.Printf("Info: Read %d license records", reg.Len())
+		referenceregistries.licenses = reg
+	}
+	return referenceregistries.licenses, nil
+}
@@ -0,0 +1,387 @@
+package ogdat
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Languages in which check messages can be rendered
+const (
+	LangDE = "de"
+	LangEN = "en"
+)
+
+// Stable, machine readable codes of the check messages
+const (
+	CodeSchemaMissing       = "OGD-SCHEMA-MISSING"
+	CodeSchemaNoVersion     = "OGD-SCHEMA-NO-VERSION"
+	CodeVersionUnsupported  = "OGD-VERSION-UNSUPPORTED"
+	CodeNoResources         = "OGD-NO-RESOURCES"
+	CodeRequiredMissing     = "OGD-REQUIRED-MISSING"
+	CodeEmptyValue          = "OGD-EMPTY-VALUE"
+	CodeTextInvalidUTF8     = "OGD-TEXT-INVALID-UTF8"
+	CodeTextInvalidUnicode  = "OGD-TEXT-INVALID-UNICODE"
+	CodeTextHTML            = "OGD-TEXT-HTML"
+	CodeTextHTMLEscape      = "OGD-TEXT-HTML-ESCAPE"
+	CodeTextURLEscape       = "OGD-TEXT-URL-ESCAPE"
+	CodeTextPosixEscape     = "OGD-TEXT-POSIX-ESCAPE"
+	CodeDateTimeFormat      = "OGD-DATETIME-FORMAT"
+	CodeDateFormat          = "OGD-DATE-FORMAT"
+	CodeLinkInvalid         = "OGD-LINK-INVALID"
+	CodeLinkEmpty           = "OGD-LINK-EMPTY"
+	CodeLinkSuspicious      = "OGD-LINK-SUSPICIOUS"
+	CodeURLFetchable        = "OGD-URL-FETCHABLE"
+	CodeURLReachable        = "OGD-URL-REACHABLE"
+	CodeURLUnreachable      = "OGD-URL-UNREACHABLE"
+	CodeURLStatus           = "OGD-URL-STATUS"
+	CodePortalURLConvention = "OGD-PORTAL-URL-CONVENTION"
+	CodeFormatInvalidChar   = "OGD-FORMAT-INVALID-CHAR"
+	CodeFormatLowerCase     = "OGD-FORMAT-LOWERCASE"
+	CodeFormatUnknown       = "OGD-FORMAT-UNKNOWN"
+	CodeFormatSynonym       = "OGD-FORMAT-SYNONYM"
+	CodeFormatContentType   = "OGD-FORMAT-CONTENT-TYPE"
+	CodeFormatContent       = "OGD-FORMAT-CONTENT"
+	CodeSizeNotNumeric      = "OGD-SIZE-NOT-NUMERIC"
+	CodeSizeMismatch        = "OGD-SIZE-MISMATCH"
+	CodeLastModifiedStale   = "OGD-LASTMODIFIED-STALE"
+	CodeLastModifiedAhead   = "OGD-LASTMODIFIED-AHEAD"
+	CodeLanguageInvalid     = "OGD-LANGUAGE-INVALID"
+	CodeEncodingNotInSpec   = "OGD-ENCODING-NOT-IN-SPEC"
+	CodeEncodingUnknown     = "OGD-ENCODING-UNKNOWN"
+	CodeUUIDInvalid         = "OGD-UUID-INVALID"
+	CodeCategoryMissing     = "OGD-CATEGORY-MISSING"
+	CodeCategoryNotArray    = "OGD-CATEGORY-NOT-ARRAY"
+	CodeCategoryUnknown     = "OGD-CATEGORY-UNKNOWN"
+	CodeKeywordsMissing     = "OGD-KEYWORDS-MISSING"
+	CodeSchemaNameVersion   = "OGD-SCHEMA-NAME-VERSION"
+	CodeSchemaLanguage      = "OGD-SCHEMA-LANGUAGE"
+	CodeSchemaCharacterset  = "OGD-SCHEMA-CHARACTERSET"
+	CodeLinkageNotArray     = "OGD-LINKAGE-NOT-ARRAY"
+	CodeDescriptionShort    = "OGD-DESCRIPTION-SHORT"
+	CodeBBoxEncoding        = "OGD-BBOX-ENCODING"
+	CodeBBoxInvalid         = "OGD-BBOX-INVALID"
+	CodeBBoxNotClosed       = "OGD-BBOX-NOT-CLOSED"
+	CodeBBoxRange           = "OGD-BBOX-RANGE"
+	CodeBBoxWinding         = "OGD-BBOX-WINDING"
+	CodeBBoxSwapped         = "OGD-BBOX-SWAPPED"
+	CodeBBoxOutsideAustria  = "OGD-BBOX-OUTSIDE-AUSTRIA"
+	CodeToponymUnknown      = "OGD-TOPONYM-UNKNOWN"
+	CodeToponymSpelling     = "OGD-TOPONYM-SPELLING"
+	CodeToponymBBox         = "OGD-TOPONYM-BBOX"
+	CodeLicenseNotURI       = "OGD-LICENSE-NOT-URI"
+	CodeLicenseName         = "OGD-LICENSE-NAME"
+	CodeLicenseUnknown      = "OGD-LICENSE-UNKNOWN"
+	CodeCitationMissing     = "OGD-CITATION-MISSING"
+	CodeCitationNotRequired = "OGD-CITATION-NOT-REQUIRED"
+	CodeFrequencyInvalid    = "OGD-FREQUENCY-INVALID"
+	CodeValueNotAllowed     = "OGD-VALUE-NOT-ALLOWED"
+)
+
+const invalidcharsDE = "Zeichenfolge enthält potentiell ungeeignete Zeichen ab Position {position}: "
+const invalidcharsEN = "String contains potentially unsuitable characters from position {position} on: "
+const invalidwktDE = "Zeichenfolge enthält keinen gültigen WKT für die örtliche Begrenzung (Boundingbox): "
+const invalidwktEN = "String contains no valid WKT for the geographic extent (bounding box): "
+
+// catalogue holds the message templates by code and language. Within a template,
+// {value}, {position} and {resource} are replaced by the respective MessageParams,
+// any other {name} by the argument of that name.
+var catalogue = struct {
+	sync.RWMutex
+	templates map[string]map[string]string
+}{templates: map[string]map[string]string{
+	CodeSchemaMissing: {
+		LangDE: "Kein Schema spezifiziert, Metadaten können nicht überprüft werden",
+		LangEN: "No schema specified, metadata can not be checked"},
+	CodeSchemaNoVersion: {
+		LangDE: "Schemabezeichnung '{value}' enthält keine Versionsnummer, Metadaten können nicht überprüft werden",
+		LangEN: "Schema name '{value}' contains no version number, metadata can not be checked"},
+	CodeVersionUnsupported: {
+		LangDE: "Für die Metadatenversion {value} ist keine Überprüfung implementiert",
+		LangEN: "No check is implemented for metadata version {value}"},
+	CodeNoResources: {
+		LangDE: "Die Metadatenbeschreibung enthält keine Ressourcen",
+		LangEN: "The metadata description contains no resources"},
+	CodeRequiredMissing: {
+		LangDE: "Pflichtfeld nicht gesetzt",
+		LangEN: "Required field is missing"},
+	CodeEmptyValue: {
+		LangDE: "Zeichenkette mit Länge 0 an dieser Stelle nicht sinnvoll",
+		LangEN: "A string of length 0 makes no sense here"},
+	CodeTextInvalidUTF8: {
+		LangDE: invalidcharsDE + "Zeichenfolge ist nicht durchgängig gültig als UTF8 kodiert",
+		LangEN: invalidcharsEN + "String is not entirely valid UTF8"},
+	CodeTextInvalidUnicode: {
+		LangDE: invalidcharsDE + "Ungültige Unicode-Sequenz: '{char}' (Bereich '{excerpt}')",
+		LangEN: invalidcharsEN + "Invalid unicode sequence: '{char}' (range '{excerpt}')"},
+	CodeTextHTML: {
+		LangDE: invalidcharsDE + "Mögliche HTML-Sequenz: '{excerpt}'",
+		LangEN: invalidcharsEN + "Possible HTML sequence: '{excerpt}'"},
+	CodeTextHTMLEscape: {
+		LangDE: invalidcharsDE + "Mögliche HTML-Escapes: '{excerpt}'",
+		LangEN: invalidcharsEN + "Possible HTML escapes: '{excerpt}'"},
+	CodeTextURLEscape: {
+		LangDE: invalidcharsDE + "Mögliche Url-Escapes: '{excerpt}'",
+		LangEN: invalidcharsEN + "Possible URL escapes: '{excerpt}'"},
+	CodeTextPosixEscape: {
+		LangDE: invalidcharsDE + "Mögliche Posix-Escapes: '{excerpt}'",
+		LangEN: invalidcharsEN + "Possible POSIX escapes: '{excerpt}'"},
+	CodeDateTimeFormat: {
+		LangDE: "Feldwert vom Typ ÖNORM ISO 8601 TM_Primitive 'YYYY-MM-DDThh:mm:ss' erwartet, Wert entspricht aber nicht diesem Typ: '{value}'",
+		LangEN: "Value of type ÖNORM ISO 8601 TM_Primitive 'YYYY-MM-DDThh:mm:ss' expected, but got: '{value}'"},
+	CodeDateFormat: {
+		LangDE: "Feldwert vom Typ ÖNORM ISO 8601 'YYYY-MM-DD' erwartet, Wert entspricht aber nicht diesem Typ: '{value}'",
+		LangEN: "Value of type ÖNORM ISO 8601 'YYYY-MM-DD' expected, but got: '{value}'"},
+	CodeLinkInvalid: {
+		LangDE: "Gültigen Verweis (Link) erwartet, der Wert '{value}' stellt keinen gültigen Link dar",
+		LangEN: "Valid link expected, but the value '{value}' is not a valid link"},
+	CodeLinkEmpty: {
+		LangDE: "kein Wert für Link angegeben (Länge 0)",
+		LangEN: "no value given for link (length 0)"},
+	CodeLinkSuspicious: {
+		LangDE: "vermutlich keine gültige Web- oder E-Mail Adresse: '{excerpt}' (Auszug)",
+		LangEN: "presumably not a valid web or e-mail address: '{excerpt}' (excerpt)"},
+	CodeURLFetchable: {
+		LangDE: "Link kann auf Erreichbarkeit überprüft werden: {value}",
+		LangEN: "Link can be checked for availability: {value}"},
+	CodeURLReachable: {
+		LangDE: "Link ist erreichbar: {value}",
+		LangEN: "Link is reachable: {value}"},
+	CodeURLUnreachable: {
+		LangDE: "{value} URL kann nicht aufgelöst werden: {error} ({method})",
+		LangEN: "{value} URL can not be resolved: {error} ({method})"},
+	CodeURLStatus: {
+		LangDE: "{value} liefert nicht-OK Status-Code '{status}' ({method})",
+		LangEN: "{value} returns non-OK status code '{status}' ({method})"},
+	CodePortalURLConvention: {
+		LangDE: "Link zum ursprünglichen Datenportal folgt nicht der Konvention ress://data.[....]: {excerpt} (Auszug)",
+		LangEN: "Link to the original data portal does not follow the convention ress://data.[....]: {excerpt} (excerpt)"},
+	CodeFormatInvalidChar: {
+		LangDE: "Ungültiges Zeichen '{char}' (Index {position})",
+		LangEN: "Invalid character '{char}' (index {position})"},
+	CodeFormatLowerCase: {
+		LangDE: "Format darf nur in Kleinbuchstaben angegeben werden",
+		LangEN: "Format must be given in lower case only"},
+	CodeFormatUnknown: {
+		LangDE: "Unbekanntes Format: {value}",
+		LangEN: "Unknown format: {value}"},
+	CodeFormatSynonym: {
+		LangDE: "Das Format {value} wird üblicherweise als {format} angegeben",
+		LangEN: "The format {value} is usually given as {format}"},
+	CodeFormatContentType: {
+		LangDE: "Als Format ist {value} angegeben, der Server liefert jedoch {contenttype}: {url}",
+		LangEN: "Declared {value} but the server returns {contenttype}: {url}"},
+	CodeFormatContent: {
+		LangDE: "Als Format ist {value} angegeben, der Inhalt ist jedoch {content}: {url}",
+		LangEN: "Declared {value} but the content is {content}: {url}"},
+	CodeSizeNotNumeric: {
+		LangDE: "Nur Zahlenangaben erlaubt, Zeichenkette enthält aber nicht-Zahlenzeichen: '{value}'",
+		LangEN: "Only numbers allowed, but the string contains non-numeric characters: '{value}'"},
+	CodeSizeMismatch: {
+		LangDE: "Als Größe sind {value} Bytes angegeben, der Server meldet jedoch {contentlength} Bytes: {url}",
+		LangEN: "Declared a size of {value} bytes but the server reports {contentlength} bytes: {url}"},
+	CodeLastModifiedStale: {
+		LangDE: "Als Änderungsdatum ist {value} angegeben, der Server meldet jedoch eine Änderung am {lastmodified}: {url}",
+		LangEN: "Declared last modified on {value} but the server reports a modification on {lastmodified}: {url}"},
+	CodeLastModifiedAhead: {
+		LangDE: "Als Änderungsdatum ist {value} angegeben, der Server meldet jedoch die letzte Änderung am {lastmodified}: {url}",
+		LangEN: "Declared last modified on {value} but the server reports the last modification on {lastmodified}: {url}"},
+	CodeLanguageInvalid: {
+		LangDE: "'{value}' ist kein gültiger dreistelliger Sprachcode nach  ISO 639-2",
+		LangEN: "'{value}' is not a valid three letter ISO 639-2 language code"},
+	CodeEncodingNotInSpec: {
+		LangDE: "'{value}' ist kein gültiges Encoding nach Spezifiaktion, aber registiert bei IANA",
+		LangEN: "'{value}' is not a valid encoding according to the specification, but registered at IANA"},
+	CodeEncodingUnknown: {
+		LangDE: "'{value}' ist kein bekanntes Encoding für Daten",
+		LangEN: "'{value}' is not a known encoding for data"},
+	CodeUUIDInvalid: {
+		LangDE: "Feldwert vom Typ UUID erwartet, Wert ist aber keine UUID: '{value}'",
+		LangEN: "Value of type UUID expected, but the value is no UUID: '{value}'"},
+	CodeCategoryMissing: {
+		LangDE: "Die Kategorisierung darf zwar mit Kardinalität 'N' optional auftreten, jedoch sollte zumindest eine Zuordnung getroffen werden",
+		LangEN: "The categorization is optional due to cardinality 'N', however at least one category should be assigned"},
+	CodeCategoryNotArray: {
+		LangDE: "Kategorisierung muss als Array übergeben werden, ist aber als string spezifiziert",
+		LangEN: "Categorization has to be given as an array, but is specified as a string"},
+	CodeCategoryUnknown: {
+		LangDE: "Die Kategorie '{value}' ist keine normierte OGD-Kategorie",
+		LangEN: "The category '{value}' is not a standardised OGD category"},
+	CodeKeywordsMissing: {
+		LangDE: "Schlagworte dürfen zwar mit Kardinalität 'N' optional auftreten, die Angabe von Schlagwörtern wäre aber wünschenswert",
+		LangEN: "Keywords are optional due to cardinality 'N', however keywords should be given"},
+	CodeSchemaNameVersion: {
+		LangDE: "Schemabezeichnung vorhanden, enthält keine Referenz auf Version {version}: '{value}'",
+		LangEN: "Schema name present, but it contains no reference to version {version}: '{value}'"},
+	CodeSchemaLanguage: {
+		LangDE: "Schemasprache als '{expected}' erwartet, der Wert ist aber '{value}'",
+		LangEN: "Schema language '{expected}' expected, but the value is '{value}'"},
+	CodeSchemaCharacterset: {
+		LangDE: "Characterset des Schemas als '{expected}' erwartet, der Wert ist aber '{value}'",
+		LangEN: "Schema characterset '{expected}' expected, but the value is '{value}'"},
+	CodeLinkageNotArray: {
+		LangDE: "JSON vom Typ 'Array of String' erwartet, es wurde jedoch ein einzelner Wert geliefert",
+		LangEN: "JSON of type 'Array of String' expected, but a single value was given"},
+	CodeDescriptionShort: {
+		LangDE: "Beschreibung enthält weniger als {minlength} Zeichen (sinnvolle Beschreibung?)",
+		LangEN: "Description contains less than {minlength} characters (meaningful description?)"},
+	CodeBBoxEncoding: {
+		LangDE: invalidwktDE + "Zeichenfolge ist nicht durchgängig gültig als UTF8 kodiert",
+		LangEN: invalidwktEN + "String is not entirely valid UTF8"},
+	CodeBBoxInvalid: {
+		LangDE: invalidwktDE + "Keine gültige WKT- oder GeoJSON-Angabe ({reason}): {value}",
+		LangEN: invalidwktEN + "No valid WKT or GeoJSON ({reason}): {value}"},
+	CodeBBoxNotClosed: {
+		LangDE: invalidwktDE + "Beginn und Ende des Polygons ergeben kein geschlossenes Polygon: {value}",
+		LangEN: invalidwktEN + "Start and end of the polygon do not form a closed polygon: {value}"},
+	CodeBBoxRange: {
+		LangDE: "Koordinaten der örtlichen Begrenzung außerhalb des gültigen Bereichs für Länge (-180 bis 180) und Breite (-90 bis 90): {value}",
+		LangEN: "Coordinates of the geographic extent outside the valid range of longitude (-180 to 180) and latitude (-90 to 90): {value}"},
+	CodeBBoxWinding: {
+		LangDE: "Die Ringe der örtlichen Begrenzung folgen nicht der Rechte-Hand-Regel (äußerer Ring gegen den Uhrzeigersinn): {value}",
+		LangEN: "The rings of the geographic extent do not follow the right-hand rule (exterior ring counterclockwise): {value}"},
+	CodeBBoxSwapped: {
+		LangDE: "Länge und Breite der örtlichen Begrenzung sind vermutlich vertauscht: {value}",
+		LangEN: "Longitude and latitude of the geographic extent are probably swapped: {value}"},
+	CodeBBoxOutsideAustria: {
+		LangDE: "Die örtliche Begrenzung liegt außerhalb Österreichs: {value}",
+		LangEN: "The geographic extent lies outside of Austria: {value}"},
+	CodeToponymUnknown: {
+		LangDE: "Die Ortsbezeichnung ist im Verzeichnis der österreichischen Gebietskörperschaften nicht enthalten: {value}",
+		LangEN: "The toponym is not listed in the gazetteer of Austrian administrative units: {value}"},
+	CodeToponymSpelling: {
+		LangDE: "Die Ortsbezeichnung {value} lautet amtlich {name}",
+		LangEN: "The official spelling of the toponym {value} is {name}"},
+	CodeToponymBBox: {
+		LangDE: "Die örtliche Begrenzung passt nicht zur Ortsbezeichnung {value}, deren Gebiet ungefähr {bbox} umfasst",
+		LangEN: "The geographic extent does not match the toponym {value}, which covers approximately {bbox}"},
+	CodeLicenseNotURI: {
+		LangDE: "Die Lizenz sollte als URI des Lizenzdokuments angegeben werden: {value}",
+		LangEN: "The license should be given as URI of the license document: {value}"},
+	CodeLicenseName: {
+		LangDE: "Die Lizenz {value} sollte als URI des Lizenzdokuments angegeben werden: {uri}",
+		LangEN: "The license {value} should be given as URI of the license document: {uri}"},
+	CodeLicenseUnknown: {
+		LangDE: "Unbekannte Lizenz: {value}",
+		LangEN: "Unknown license: {value}"},
+	CodeCitationMissing: {
+		LangDE: "Die Lizenz {value} verlangt eine Namensnennung, license_citation fehlt",
+		LangEN: "The license {value} requires attribution, license_citation is missing"},
+	CodeCitationNotRequired: {
+		LangDE: "Die Lizenz {value} verlangt keine Namensnennung, license_citation ist dennoch angegeben",
+		LangEN: "The license {value} does not require attribution, license_citation is given nonetheless"},
+	CodeFrequencyInvalid: {
+		LangDE: "Feldwert in Anlehnung an ON/EN/ISO 19115:2003 erwartet (gültige Werte sind in der OGD Spezifikation definiert), Wert entspricht aber nicht diesem Typ: '{value}'",
+		LangEN: "Value according to ON/EN/ISO 19115:2003 expected (valid values are defined in the OGD specification), but got: '{value}'"},
+	CodeValueNotAllowed: {
+		LangDE: "Wert '{value}' ist nicht zulässig, erlaubt sind: {allowed}",
+		LangEN: "Value '{value}' is not allowed, allowed are: {allowed}"},
+}}
+
+// RegisterMessage adds or replaces the templates of the message code. templates
+// maps languages (LangDE, LangEN, ...) to the message template.
+func RegisterMessage(code string, templates map[string]string) {
+	catalogue.Lock()
+	defer catalogue.Unlock()
+	catalogue.templates[code] = templates
+}
+
+// MessageTemplate returns the template of the message code in lang, falling back to German
+func MessageTemplate(code, lang string) (string, bool) {
+	catalogue.RLock()
+	defer catalogue.RUnlock()
+	templates, ok := catalogue.templates[code]
+	if !ok {
+		return "", false
+	}
+	if template, ok := templates[lang]; ok {
+		return template, true
+	}
+	template, ok := templates[LangDE]
+	return template, ok
+}
+
+// MessageParams are the structured parameters of a check message
+type MessageParams struct {
+	Value    string            // the offending value, if any
+	Position int               // position of the offending part within the value, -1 if not applicable
+	Resource int               // index of the resource the message refers to, -1 if not applicable
+	Args     map[string]string // further arguments, referenced by name in the message templates
+}
+
+// NoParams are the parameters of messages which refer to neither a value nor a resource
+var NoParams = MessageParams{Position: -1, Resource: -1}
+
+// ValueParams returns the parameters for the offending value and further
+// arguments given as name, value pairs
+func ValueParams(value string, args ...string) MessageParams {
+	params := MessageParams{Value: value, Position: -1, Resource: -1}
+	if len(args) > 1 {
+		params.Args = make(map[string]string, len(args)/2)
+		for i := 0; i+1 < len(args); i += 2 {
+			params.Args[args[i]] = args[i+1]
+		}
+	}
+	return params
+}
+
+// AtResource returns a copy of params referring to the resource with index resource
+func (params MessageParams) AtResource(resource int) MessageParams {
+	params.Resource = resource
+	return params
+}
+
+// AtPosition returns a copy of params referring to position within the value
+func (params MessageParams) AtPosition(position int) MessageParams {
+	params.Position = position
+	return params
+}
+
+// NewCheckMessage creates a message of type typ with code for the OGD field ogdid.
+// Text is rendered in German and prefixed by the resource index, if any.
+func NewCheckMessage(typ Status, ogdid int, code string, params MessageParams) CheckMessage {
+	msg := CheckMessage{Type: typ, OGDID: ogdid, Code: code, Params: params}
+	// the text of link messages stays the bare link, the watcher and the
+	// analyser read it back from the database
+	if code == CodeURLFetchable || code == CodeURLReachable {
+		msg.Text = params.Value
+		return msg
+	}
+	msg.Text = msg.Render(LangDE)
+	if params.Resource > -1 {
+		msg.Text = fmt.Sprintf("R%4d: ", params.Resource) + msg.Text
+	}
+	return msg
+}
+
+// Render returns the message text in lang, falling back to German if there is no
+// template in that language. Messages without code or with an unknown code are
+// rendered as Text.
+func (msg *CheckMessage) Render(lang string) string {
+	template, ok := MessageTemplate(msg.Code, lang)
+	if !ok {
+		return msg.Text
+	}
+	replace := []string{
+		"{value}", msg.Params.Value,
+		"{position}", strconv.Itoa(msg.Params.Position),
+		"{resource}", strconv.Itoa(msg.Params.Resource)}
+	for name, value := range msg.Params.Args {
+		replace = append(replace, "{"+name+"}", value)
+	}
+	return strings.NewReplacer(replace...).Replace(template)
+}
+
+// Message turns the result of a check into a message for the OGD field ogdid
+// and the resource with index resource, -1 if it does not refer to a resource
+func (c *CheckInfo) Message(ogdid, resource int) CheckMessage {
+	if c.Code == "" {
+		return CheckMessage{Type: c.Status, OGDID: ogdid, Text: c.Context}
+	}
+	params := ValueParams(c.Value).AtPosition(c.Position).AtResource(resource)
+	params.Args = c.Args
+	msg := NewCheckMessage(c.Status, ogdid, c.Code, params)
+	msg.Head = c.Head
+	return msg
+}
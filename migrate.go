@@ -0,0 +1,291 @@
+package ogdat
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// MigrationIssue names a field of a metadata document which could not be
+// migrated automatically and has to be looked at by the data provider
+type MigrationIssue struct {
+	Field    string `json:"field"`    // CKAN field as named in the specification
+	Resource int    `json:"resource"` // index of the resource, -1 for fields of the metadata set
+	Reason   string `json:"reason"`
+}
+
+func (i MigrationIssue) String() string {
+	field := i.Field
+	if i.Resource > -1 {
+		field = fmt.Sprintf("%s[%d]", field, i.Resource)
+	}
+	return fmt.Sprintf("%s: %s", field, i.Reason)
+}
+
+// Migration is the result of migrating a metadata document from one version
+// of the specification to another
+type Migration struct {
+	From     string           `json:"from"` // versions of the specification, e.g. "OGD Austria Metadata 2.1"
+	To       string           `json:"to"`
+	Data     []byte           `json:"-"` // the migrated CKAN JSON document
+	Changes  []Change         `json:"changes,omitempty"`
+	Issues   []MigrationIssue `json:"issues,omitempty"`
+	Messages []CheckMessage   `json:"-"` // the result of checking Data according to To
+}
+
+// a fieldmigration converts a field whose format changed with version since,
+// by upgrade for target versions since then, by downgrade for older ones
+var fieldmigrations = []struct {
+	field              string
+	since              string
+	upgrade, downgrade fieldfix
+}{
+	{"extras:geographic_bbox", "2.2", migrateclosedbbox, migratetwopointbbox},
+}
+
+// Migrate converts the CKAN JSON metadata document bytedata from version from
+// to version to of the specification. Versions are given as accepted by
+// GetMetadataFactory; if from is empty, the version is detected by
+// DetectMetadataVersion. schema_name is set to the target version, fields whose
+// format changed are converted and fields which can not be filled or are not part
+// of the target version are reported as issues. Finally the migrated document is
+// checked according to the target version.
+func Migrate(bytedata []byte, from, to string) (*Migration, error) {
+	var source *MetadataFactory
+	var err error
+	if from == "" {
+		source, _, err = DetectMetadataVersion(bytedata)
+	} else {
+		source, err = GetMetadataFactory(from)
+	}
+	if err != nil {
+		return nil, err
+	}
+	target, err := GetMetadataFactory(to)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(bytedata))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("Metadaten können nicht gelesen werden: %s", err)
+	}
+	extras, ok := doc["extras"].(map[string]interface{})
+	if !ok {
+		extras = make(map[string]interface{})
+		doc["extras"] = extras
+	}
+
+	m := &Migration{From: source.Version, To: target.Version}
+	set := func(obj map[string]interface{}, key, field string, value interface{}, reason string) {
+		m.Changes = append(m.Changes, Change{Field: field, Resource: -1, Old: jsonstring(obj[key]), New: jsonstring(value), Reason: reason})
+		obj[key] = value
+	}
+
+	if extras["schema_name"] != target.Version {
+		set(extras, "schema_name", "extras:schema_name", target.Version, fmt.Sprintf("Version der Spezifikation auf %s gesetzt", target.Version))
+	}
+
+	targetnumber := OGDVersionfromString(target.Version)
+	for _, fm := range fieldmigrations {
+		migrate := fm.upgrade
+		if versionless(targetnumber, fm.since) {
+			migrate = fm.downgrade
+		}
+		for idx, obj := range objectsforfield(doc, fm.field) {
+			key := fieldkey(fm.field)
+			value, ok := obj[key]
+			if !ok || value == nil {
+				continue
+			}
+			fixed, code, reason, ok := migrate(value)
+			if !ok {
+				continue
+			}
+			obj[key] = fixed
+			m.Changes = append(m.Changes, Change{Field: fm.field, Resource: idx, Code: code, Old: jsonstring(value), New: jsonstring(fixed), Reason: reason})
+		}
+	}
+
+	// maintainer_email, new as of 2.2, can be taken from a mailto: link to the maintainer
+	if target.Spec.hasCKANField("maintainer_email") && !source.Spec.hasCKANField("maintainer_email") && isempty(doc["maintainer_email"]) {
+		if link, ok := extras["maintainer_link"].(string); ok && strings.HasPrefix(strings.ToLower(link), "mailto:") {
+			set(doc, "maintainer_email", "maintainer_email", link[len("mailto:"):], "E-Mail-Adresse aus maintainer_link übernommen")
+		}
+	}
+
+	for _, desc := range target.Spec.Beschreibung {
+		field := normalizeCKANField(desc.CKAN_Feld)
+		sourcedesc := source.Spec.beschreibungforCKANField(field)
+		for idx, obj := range objectsforfield(doc, field) {
+			if !isempty(obj[fieldkey(field)]) {
+				continue
+			}
+			switch {
+			case desc.IsRequired() && (sourcedesc == nil || !sourcedesc.IsRequired()):
+				m.Issues = append(m.Issues, MigrationIssue{Field: field, Resource: idx, Reason: fmt.Sprintf("Pflichtfeld ab %s, kann nicht automatisch befüllt werden", target.Version)})
+			case sourcedesc == nil:
+				m.Issues = append(m.Issues, MigrationIssue{Field: field, Resource: idx, Reason: fmt.Sprintf("Neu in %s, kann ergänzt werden", target.Version)})
+			}
+		}
+	}
+	for _, desc := range source.Spec.Beschreibung {
+		field := normalizeCKANField(desc.CKAN_Feld)
+		if target.Spec.hasCKANField(field) {
+			continue
+		}
+		for idx, obj := range objectsforfield(doc, field) {
+			if !isempty(obj[fieldkey(field)]) {
+				m.Issues = append(m.Issues, MigrationIssue{Field: field, Resource: idx, Reason: fmt.Sprintf("In %s nicht vorgesehen, wird nicht überprüft", target.Version)})
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	m.Data = buf.Bytes()
+
+	md, err := target.parse(m.Data)
+	if err != nil {
+		return m, fmt.Errorf("Migrierte Metadaten können nicht gelesen werden: %s", err)
+	}
+	if m.Messages, err = md.Check(false); err != nil {
+		return m, err
+	}
+	return m, nil
+}
+
+func (set *OGDSet) beschreibungforCKANField(field string) *Beschreibung {
+	if set == nil {
+		return nil
+	}
+	for _, desc := range set.Beschreibung {
+		if normalizeCKANField(desc.CKAN_Feld) == field {
+			return desc
+		}
+	}
+	return nil
+}
+
+// objectsforfield returns the JSON objects of doc holding the CKAN field: the
+// document, its extras or its resources, indexed by resource for resource fields
+// and by -1 otherwise
+func objectsforfield(doc map[string]interface{}, field string) map[int]map[string]interface{} {
+	objects := make(map[int]map[string]interface{})
+	switch {
+	case strings.HasPrefix(field, "extras:"):
+		if extras, ok := doc["extras"].(map[string]interface{}); ok {
+			objects[-1] = extras
+		}
+	case strings.HasPrefix(field, "resources:"):
+		resources, _ := doc["resources"].([]interface{})
+		for idx, res := range resources {
+			if resource, ok := res.(map[string]interface{}); ok {
+				objects[idx] = resource
+			}
+		}
+	default:
+		objects[-1] = doc
+	}
+	return objects
+}
+
+// fieldkey returns the key of the CKAN field within its JSON object
+func fieldkey(field string) string {
+	if idx := strings.IndexRune(field, ':'); idx > -1 {
+		return field[idx+1:]
+	}
+	return field
+}
+
+func isempty(value interface{}) bool {
+	switch val := value.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(val) == ""
+	case []interface{}:
+		return len(val) == 0
+	}
+	return false
+}
+
+var regexpwktnumber = regexp.MustCompile(`[-+]?\d*\.?\d+`)
+
+// polygonbounds returns the minimal and maximal coordinates of a WKT POLYGON
+// as written in the document, ok is false if str is no polygon
+func polygonbounds(str string) (minx, miny, maxx, maxy string, points int, ok bool) {
+	str = strings.TrimSpace(str)
+	if !strings.HasPrefix(str, "POLYGON") {
+		return
+	}
+	numbers := regexpwktnumber.FindAllString(str, -1)
+	if len(numbers) < 4 || len(numbers)%2 != 0 {
+		return
+	}
+	var bounds [4]float64
+	for idx, number := range numbers {
+		f, err := strconv.ParseFloat(number, 64)
+		if err != nil {
+			return
+		}
+		lower, upper := idx%2, idx%2+2
+		if idx < 2 || f < bounds[lower] {
+			bounds[lower] = f
+			if lower == 0 {
+				minx = number
+			} else {
+				miny = number
+			}
+		}
+		if idx < 2 || f > bounds[upper] {
+			bounds[upper] = f
+			if upper == 2 {
+				maxx = number
+			} else {
+				maxy = number
+			}
+		}
+	}
+	return minx, miny, maxx, maxy, len(numbers) / 2, true
+}
+
+// migrateclosedbbox converts the bounding box given by two corners, as
+// accepted by version 2.1, into the closed polygon required as of version 2.2
+func migrateclosedbbox(value interface{}) (interface{}, string, string, bool) {
+	str, ok := value.(string)
+	if !ok {
+		return nil, "", "", false
+	}
+	minx, miny, maxx, maxy, points, ok := polygonbounds(str)
+	if !ok || points != 2 {
+		return nil, "", "", false
+	}
+	bbox := fmt.Sprintf("POLYGON ((%s %s, %s %s, %s %s, %s %s, %s %s))", minx, miny, maxx, miny, maxx, maxy, minx, maxy, minx, miny)
+	return bbox, CodeBBoxInvalid, "Boundingbox aus zwei Eckpunkten in geschlossenes Polygon umgewandelt", true
+}
+
+// migratetwopointbbox converts a polygon into the bounding box given by two
+// corners, as required by version 2.1
+func migratetwopointbbox(value interface{}) (interface{}, string, string, bool) {
+	str, ok := value.(string)
+	if !ok {
+		return nil, "", "", false
+	}
+	minx, miny, maxx, maxy, points, ok := polygonbounds(str)
+	if !ok || points == 2 {
+		return nil, "", "", false
+	}
+	bbox := fmt.Sprintf("POLYGON ((%s %s, %s %s))", minx, miny, maxx, maxy)
+	return bbox, CodeBBoxInvalid, "Polygon in Boundingbox aus zwei Eckpunkten umgewandelt", true
+}
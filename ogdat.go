@@ -1,9 +1,11 @@
 package ogdat
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"github.com/the42/ogdat/Godeps/_workspace/src/code.google.com/p/go-uuid/uuid"
+	"github.com/the42/ogdat/geometry"
 	"io"
 	"io/ioutil"
 	"net/url"
@@ -35,7 +37,7 @@ type Kategorie struct {
 	NumID       int `json:"-"`
 	ID          string
 	PrettyName  string `json:"-"`
-	RDFProperty string `json:"-"`
+	RDFProperty string `json:"-"` // the theme of the EU data theme vocabulary the category is mapped to by DCAT-AP.at
 }
 
 func (kat *Kategorie) String() string {
@@ -43,20 +45,20 @@ func (kat *Kategorie) String() string {
 }
 
 var (
-	Arbeit           = Kategorie{NumID: 1, ID: "arbeit", PrettyName: "Arbeit", RDFProperty: ""}
-	Bevoelkerung     = Kategorie{NumID: 2, ID: "bevölkerung", PrettyName: "Bevölkerung", RDFProperty: ""}
-	BildungForschung = Kategorie{NumID: 3, ID: "bildung-und-forschung", PrettyName: "Bildung und Forschung", RDFProperty: ""}
-	FinanzRW         = Kategorie{NumID: 4, ID: "finanzen-und-rechnungswesen", PrettyName: "Finanzen und Rechnungswesen", RDFProperty: ""}
-	GeographPlanung  = Kategorie{NumID: 5, ID: "geographie-und-planung", PrettyName: "Geographie und Planung", RDFProperty: ""}
-	GesellSoziales   = Kategorie{NumID: 6, ID: "gesellschaft-und-soziales", PrettyName: "Gesellschaft und Soziales", RDFProperty: ""}
-	Gesundheit       = Kategorie{NumID: 7, ID: "gesundheit", PrettyName: "Gesundheit", RDFProperty: ""}
-	KunstKultur      = Kategorie{NumID: 8, ID: "kunst-und-kultur", PrettyName: "Kunst und Kultur", RDFProperty: ""}
-	LandFW           = Kategorie{NumID: 9, ID: "land-und-forstwirtschaft", PrettyName: "Land und Forstwirtschaft", RDFProperty: ""}
-	SportFZ          = Kategorie{NumID: 10, ID: "sport-und-freizeit", PrettyName: "Sport und Freizeit", RDFProperty: ""}
-	Umwelt           = Kategorie{NumID: 11, ID: "umwelt", PrettyName: "Umwelt", RDFProperty: ""}
-	VerkehrTechnik   = Kategorie{NumID: 12, ID: "verkehr-und-technik", PrettyName: "Verkehr und Technik", RDFProperty: ""}
-	VerwaltPol       = Kategorie{NumID: 13, ID: "verwaltung-und-politik", PrettyName: "Verwaltung und Politik", RDFProperty: ""}
-	WirtTourism      = Kategorie{NumID: 14, ID: "wirtschaft-und-tourismus", PrettyName: "Wirtschaft und Tourismus", RDFProperty: ""}
+	Arbeit           = Kategorie{NumID: 1, ID: "arbeit", PrettyName: "Arbeit", RDFProperty: "http://publications.europa.eu/resource/authority/data-theme/ECON"}
+	Bevoelkerung     = Kategorie{NumID: 2, ID: "bevölkerung", PrettyName: "Bevölkerung", RDFProperty: "http://publications.europa.eu/resource/authority/data-theme/SOCI"}
+	BildungForschung = Kategorie{NumID: 3, ID: "bildung-und-forschung", PrettyName: "Bildung und Forschung", RDFProperty: "http://publications.europa.eu/resource/authority/data-theme/EDUC"}
+	FinanzRW         = Kategorie{NumID: 4, ID: "finanzen-und-rechnungswesen", PrettyName: "Finanzen und Rechnungswesen", RDFProperty: "http://publications.europa.eu/resource/authority/data-theme/ECON"}
+	GeographPlanung  = Kategorie{NumID: 5, ID: "geographie-und-planung", PrettyName: "Geographie und Planung", RDFProperty: "http://publications.europa.eu/resource/authority/data-theme/REGI"}
+	GesellSoziales   = Kategorie{NumID: 6, ID: "gesellschaft-und-soziales", PrettyName: "Gesellschaft und Soziales", RDFProperty: "http://publications.europa.eu/resource/authority/data-theme/SOCI"}
+	Gesundheit       = Kategorie{NumID: 7, ID: "gesundheit", PrettyName: "Gesundheit", RDFProperty: "http://publications.europa.eu/resource/authority/data-theme/HEAL"}
+	KunstKultur      = Kategorie{NumID: 8, ID: "kunst-und-kultur", PrettyName: "Kunst und Kultur", RDFProperty: "http://publications.europa.eu/resource/authority/data-theme/EDUC"}
+	LandFW           = Kategorie{NumID: 9, ID: "land-und-forstwirtschaft", PrettyName: "Land und Forstwirtschaft", RDFProperty: "http://publications.europa.eu/resource/authority/data-theme/AGRI"}
+	SportFZ          = Kategorie{NumID: 10, ID: "sport-und-freizeit", PrettyName: "Sport und Freizeit", RDFProperty: "http://publications.europa.eu/resource/authority/data-theme/EDUC"}
+	Umwelt           = Kategorie{NumID: 11, ID: "umwelt", PrettyName: "Umwelt", RDFProperty: "http://publications.europa.eu/resource/authority/data-theme/ENVI"}
+	VerkehrTechnik   = Kategorie{NumID: 12, ID: "verkehr-und-technik", PrettyName: "Verkehr und Technik", RDFProperty: "http://publications.europa.eu/resource/authority/data-theme/TRAN"}
+	VerwaltPol       = Kategorie{NumID: 13, ID: "verwaltung-und-politik", PrettyName: "Verwaltung und Politik", RDFProperty: "http://publications.europa.eu/resource/authority/data-theme/GOVE"}
+	WirtTourism      = Kategorie{NumID: 14, ID: "wirtschaft-und-tourismus", PrettyName: "Wirtschaft und Tourismus", RDFProperty: "http://publications.europa.eu/resource/authority/data-theme/ECON"}
 )
 
 var Categories = []Kategorie{
@@ -124,14 +126,23 @@ type Url struct {
 	Raw string
 }
 
+// NewUrl returns raw as Url. If raw can not be parsed, URL is nil.
+func NewUrl(raw string) *Url {
+	u, _ := url.Parse(raw)
+	return &Url{URL: u, Raw: raw}
+}
+
 type MetaDataLinkage struct {
 	Url     []Url
 	IsArray bool
 }
 
 type MetaDataKategorie struct {
-	Kategorie []Kategorie
-	IsString  bool
+	Kategorie  []Kategorie
+	IsString   bool
+	IsEmbedded bool // the array was given embedded in a string
+
+	embedded string
 }
 
 type Identifier struct {
@@ -139,6 +150,12 @@ type Identifier struct {
 	Raw string
 }
 
+// NewIdentifier returns a new identifier holding a random (version 4) UUID
+func NewIdentifier() *Identifier {
+	id := uuid.NewRandom()
+	return &Identifier{UUID: &id, Raw: id.String()}
+}
+
 func (id *Identifier) String() string {
 	return id.Raw
 }
@@ -149,6 +166,11 @@ type Time struct {
 	Format string
 }
 
+// NewTime returns t as a Time given in format, one of TimeFormat
+func NewTime(t time.Time, format string) *Time {
+	return &Time{Time: t, Raw: t.Format(format), Format: format}
+}
+
 func (time *Time) String() string {
 	return time.Raw
 }
@@ -275,11 +297,13 @@ func (kat *MetaDataKategorie) UnmarshalJSON(data []byte) error {
 		// Some specify the category as an embedded array ...
 		if data[0] == '"' && data[1] == '[' {
 			kat.IsString = true
+			kat.IsEmbedded = true
 			var embeddedarray string
 			e := json.Unmarshal(data, &embeddedarray)
 			if e != nil {
 				return e
 			}
+			kat.embedded = embeddedarray
 			data = []byte(embeddedarray)
 		}
 		// .. that's how it should be ...
@@ -301,6 +325,78 @@ func (kat *MetaDataKategorie) UnmarshalJSON(data []byte) error {
 	return errors.New("MetaDataKategorie: Unknow structure to unmarshal")
 }
 
+// The MarshalJSON methods reproduce the values as read by UnmarshalJSON from
+// Raw, so parsed metadata can be written back unaltered. If Raw is empty, as
+// for values constructed programmatically, the parsed value is written.
+
+func (cyc Cycle) MarshalJSON() ([]byte, error) {
+	if cyc.Raw != "" || cyc.NumID < 1 {
+		return json.Marshal(cyc.Raw)
+	}
+	return json.Marshal(cyc.MD_MaintenanceFrequencyCode)
+}
+
+func (ogdtime Time) MarshalJSON() ([]byte, error) {
+	if ogdtime.Raw != "" || ogdtime.Time.IsZero() {
+		return json.Marshal(ogdtime.Raw)
+	}
+	format := ogdtime.Format
+	if format == "" {
+		format = time.RFC3339
+	}
+	return json.Marshal(ogdtime.Time.Format(format))
+}
+
+func (u Url) MarshalJSON() ([]byte, error) {
+	if u.Raw != "" || u.URL == nil {
+		return json.Marshal(u.Raw)
+	}
+	return json.Marshal(u.URL.String())
+}
+
+func (u MetaDataLinkage) MarshalJSON() ([]byte, error) {
+	if !u.IsArray && len(u.Url) == 1 {
+		return json.Marshal(u.Url[0])
+	}
+	if u.Url == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(u.Url)
+}
+
+func (id Identifier) MarshalJSON() ([]byte, error) {
+	if id.Raw != "" || id.UUID == nil {
+		return json.Marshal(id.Raw)
+	}
+	return json.Marshal(id.UUID.String())
+}
+
+func (kat Kategorie) MarshalJSON() ([]byte, error) {
+	return json.Marshal(kat.ID)
+}
+
+func (kat MetaDataKategorie) MarshalJSON() ([]byte, error) {
+	if kat.IsString && !kat.IsEmbedded && len(kat.Kategorie) == 1 {
+		return json.Marshal(kat.Kategorie[0])
+	}
+	cats := kat.Kategorie
+	if cats == nil {
+		cats = []Kategorie{}
+	}
+	data, err := json.Marshal(cats)
+	if err != nil || !kat.IsEmbedded {
+		return data, err
+	}
+	// keep the embedded array as given unless the categories were changed
+	var embedded MetaDataKategorie
+	if kat.embedded != "" && json.Unmarshal([]byte(kat.embedded), &embedded) == nil {
+		if given, _ := json.Marshal(embedded.Kategorie); bytes.Equal(given, data) {
+			data = []byte(kat.embedded)
+		}
+	}
+	return json.Marshal(string(data))
+}
+
 var specification = make(map[string]*OGDSet)
 
 type Occurrence int
@@ -397,11 +493,24 @@ type Extras struct {
 	Geographic_BBox     *string            `json:"geographic_bbox"`
 	Geographich_Toponym *string            `json:"geographic_toponym"`
 	Categorization      *MetaDataKategorie `json:"categorization"`
+	License_Citation    *string            `json:"license_citation"`
+}
+
+// MinimalResource is the link to, the format, the size and the date of last
+// modification of a resource
+type MinimalResource struct {
+	URL          *string `json:"url"`
+	Format       *string `json:"format"`
+	Size         *string `json:"size"`
+	LastModified *Time   `json:"last_modified"`
 }
 
 type MinimalMetaData struct {
 	Description *string `json:"notes"`
+	License     *string `json:"license"`
 	Extras      `json:"extras"`
+	Resources   []MinimalResource `json:"resources"`
+	BBox        *geometry.BBox    `json:"-"` // the bounding box of Geographic_BBox, nil if not given or invalid
 }
 
 type Metadater interface {
@@ -422,6 +531,7 @@ func MinimalMetaDataforJSONStream(jsondata io.Reader) (*MinimalMetaData, error)
 		}
 		return nil, err
 	}
+	data.BBox = GeographicBBox(data.Geographic_BBox)
 	return data, nil
 }
 
@@ -1,13 +1,25 @@
 package ogdat
 
 import (
+	"encoding/json"
 	"fmt"
+	"github.com/the42/ogdat/Godeps/_workspace/src/code.google.com/p/go-uuid/uuid"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
+	"path/filepath"
 	"reflect"
+	"regexp"
+	"strings"
+	"sync"
 	"testing"
+	"testing/fstest"
+	"time"
 )
 
-const ogdatv21specfile = "ogdat_spec-2.1.csv"
+const ogdatv21specfile = "ogdatv21/ogdat_spec-2.1.csv"
 
 func TestLoadOGDATSpecFile(t *testing.T) {
 	if spec, _ := Loadogdatspec("v21", ogdatv21specfile); spec != nil {
@@ -35,7 +47,11 @@ var checkIANAEncodingTests = []checkIANAEncodingTest{
 
 func TestIANACheck(t *testing.T) {
 	for idx, test := range checkIANAEncodingTests {
-		if result := CheckIANAEncoding(test.in); result != test.out {
+		result, err := CheckIANAEncoding(test.in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result != test.out {
 			t.Errorf("CheckIANAEncoding-[%d]: Encoding '%s': expected '%v' but got '%v'", idx, test.in, test.out, result)
 		}
 	}
@@ -112,7 +128,7 @@ func stringifyminimalmetadata(mmd *MinimalMetaData) (ms string) {
 	if mmd.Geographich_Toponym != nil {
 		ms += fmt.Sprintf("Toponym: %s\n", *mmd.Geographich_Toponym)
 	}
-	ms += fmt.Sprintf("Categorization: %s\n", mmd.Categorization)
+	ms += fmt.Sprintf("Categorization: %v\n", mmd.Categorization)
 	return
 }
 
@@ -163,3 +179,861 @@ func TestOGDVersionfromString(t *testing.T) {
 		}
 	}
 }
+
+func TestDataDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ogdat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	const spec = "ID|Bezeichner|OGD-Kurzname|CKAN Feld|Anzahl|Definition|Erläuterung|Beispiel|ON A 2270:2010|ON/EN/ISO 19115:2003|RDF property|Definition Englisch|Occurence\n" +
+		"1|Eindeutiger Identifikator|metadata_identifier|extras:metadata_identifier|1||||||||R\n"
+	set, err := RegisterFromFS("TestDataDir", fstest.MapFS{"spec.csv": &fstest.MapFile{Data: []byte(spec)}}, "spec.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(set.Beschreibung); n != 1 {
+		t.Fatalf("TestDataDir: embedded specification should contain 1 record but found %d", n)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "character-sets.txt"), []byte("abacab\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "spec.csv"), []byte(spec+"5|Datum des Metadatensatzes|metadata_modified|extras:metadata_modified|1||||||||R\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetDataDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer SetDataDir("")
+
+	if ok, err := CheckIANAEncoding("abacab"); !ok || err != nil {
+		t.Errorf("TestDataDir: encoding from data directory not found: %v, %v", ok, err)
+	}
+	if n := len(GetOGDSetForVersion("TestDataDir").Beschreibung); n != 2 {
+		t.Errorf("TestDataDir: specification from data directory should contain 2 records but found %d", n)
+	}
+	// files missing in the data directory are taken from the embedded ones
+	if ok, err := CheckISOLanguage("ger"); !ok || err != nil {
+		t.Errorf("TestDataDir: embedded language file not used: %v, %v", ok, err)
+	}
+}
+
+type languageRegistryTest struct {
+	code, name string
+	ok         bool
+}
+
+var languageRegistryTests = []languageRegistryTest{
+	{"ger", "German", true},
+	{"deu", "German", true},
+	{"de", "German", true},
+	{"aar", "Afar", true}, // first entry of the file, preceded by a BOM
+	{"qab", "Reserved for local use", true},
+	{"xyz", "xyz", false},
+	{"xy", "xy", false},
+}
+
+func TestLanguageRegistry(t *testing.T) {
+	// concurrent first use must not race
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := CheckISOLanguage("ger"); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	reg, err := Languages()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for idx, test := range languageRegistryTests {
+		if name := reg.Name(test.code); name != test.name {
+			t.Errorf("LanguageRegistry-[%d]: Name('%s'): expected '%s' but got '%s'", idx, test.code, test.name, name)
+		}
+		lookup := reg.Lookup
+		if len(test.code) == 2 {
+			lookup = reg.LookupISO6391
+		}
+		if _, ok := lookup(test.code); ok != test.ok {
+			t.Errorf("LanguageRegistry-[%d]: Lookup('%s'): expected '%v' but got '%v'", idx, test.code, test.ok, ok)
+		}
+	}
+}
+
+var checkMessageTests = []struct {
+	msg    CheckMessage
+	de, en string
+}{
+	{NewCheckMessage(Info, -1, CodeSchemaMissing, NoParams),
+		"Kein Schema spezifiziert, Metadaten können nicht überprüft werden",
+		"No schema specified, metadata can not be checked"},
+	{NewCheckMessage(Info, -1, CodeSchemaNoVersion, ValueParams("OGD Austria Metadata")),
+		"Schemabezeichnung 'OGD Austria Metadata' enthält keine Versionsnummer, Metadaten können nicht überprüft werden",
+		"Schema name 'OGD Austria Metadata' contains no version number, metadata can not be checked"},
+	{NewCheckMessage(Info, -1, CodeVersionUnsupported, ValueParams("1.0")),
+		"Für die Metadatenversion 1.0 ist keine Überprüfung implementiert",
+		"No check is implemented for metadata version 1.0"},
+	{NewCheckMessage(Error, 1, CodeURLStatus, ValueParams("http://example.com", "status", "404", "method", "Get").AtResource(2)),
+		"R   2: http://example.com liefert nicht-OK Status-Code '404' (Get)",
+		"http://example.com returns non-OK status code '404' (Get)"},
+	{CheckMessage{Type: Warning, OGDID: 1, Text: "ohne Code"},
+		"ohne Code",
+		"ohne Code"},
+}
+
+func TestCheckMessageRender(t *testing.T) {
+	for idx, test := range checkMessageTests {
+		if test.msg.Text != test.de {
+			t.Errorf("CheckMessage-[%d]: expected Text '%s' but got '%s'", idx, test.de, test.msg.Text)
+		}
+		if text := test.msg.Render(LangEN); text != test.en {
+			t.Errorf("CheckMessage-[%d]: expected English text '%s' but got '%s'", idx, test.en, text)
+		}
+	}
+	if err := (&UnknownVersionError{}).Error(); err != checkMessageTests[0].de {
+		t.Errorf("UnknownVersionError: expected '%s' but got '%s'", checkMessageTests[0].de, err)
+	}
+	if err := (&UnknownVersionError{SchemaName: "OGD Austria Metadata"}).Error(); err != checkMessageTests[1].de {
+		t.Errorf("UnknownVersionError: expected '%s' but got '%s'", checkMessageTests[1].de, err)
+	}
+}
+
+var linkMessageTests = []struct {
+	msg    CheckMessage
+	de, en string
+}{
+	{NewCheckMessage(Info|FetchableUrl, 14, CodeURLFetchable, ValueParams("http://example.com").AtResource(2)),
+		"Link kann auf Erreichbarkeit überprüft werden: http://example.com",
+		"Link can be checked for availability: http://example.com"},
+	{NewCheckMessage(Info|FetchableUrl|FetchSuccess, 13, CodeURLReachable, ValueParams("http://example.com")),
+		"Link ist erreichbar: http://example.com",
+		"Link is reachable: http://example.com"},
+}
+
+func TestLinkMessageText(t *testing.T) {
+	for idx, test := range linkMessageTests {
+		if test.msg.Text != "http://example.com" {
+			t.Errorf("LinkMessage-[%d]: expected the bare link as Text but got '%s'", idx, test.msg.Text)
+		}
+		if text := test.msg.Render(LangDE); text != test.de {
+			t.Errorf("LinkMessage-[%d]: expected German text '%s' but got '%s'", idx, test.de, text)
+		}
+		if text := test.msg.Render(LangEN); text != test.en {
+			t.Errorf("LinkMessage-[%d]: expected English text '%s' but got '%s'", idx, test.en, text)
+		}
+	}
+}
+
+func TestMetadataFactoryError(t *testing.T) {
+	loaderr := fmt.Errorf("Can not load specification 'ogdat_spec-0.9.csv' for OGD Austria Metadata 0.9: file does not exist")
+	RegisterMetadataFactoryError("OGD Austria Metadata 0.9", []string{"0.9"}, loaderr)
+
+	for _, version := range []string{"OGD Austria Metadata 0.9", "0.9"} {
+		if f, err := GetMetadataFactory(version); f != nil || err != loaderr {
+			t.Errorf("GetMetadataFactory('%s'): expected the load error, got %v, %v", version, f, err)
+		}
+	}
+	if _, err := ParseMetadata(strings.NewReader(`{"extras": {"schema_name": "OGD Austria Metadata 0.9"}}`)); err != loaderr {
+		t.Errorf("ParseMetadata: expected the load error, got %v", err)
+	}
+	for _, f := range MetadataFactories() {
+		if f.Version == "OGD Austria Metadata 0.9" {
+			t.Errorf("MetadataFactories: expected no factory for a version failing to load")
+		}
+	}
+}
+
+var statusTests = []struct {
+	status Status
+	str    string
+}{
+	{0, "0"},
+	{Info, "Info"},
+	{Info | FetchableUrl, "Info|FetchableUrl"},
+	{Error | FetchableUrl | NoDataatUrlError, "Error|FetchableUrl|NoDataatUrlError"},
+	{Info | EmptyData, "Info|EmptyData"},
+	{Warning | 0x100, "Warning|0x100"},
+}
+
+func TestStatus(t *testing.T) {
+	var all Status
+	for _, flag := range statusnames {
+		if all&flag.flag != 0 {
+			t.Errorf("Status: %s overlaps other flags", flag.name)
+		}
+		all |= flag.flag
+	}
+
+	for idx, test := range statusTests {
+		if str := test.status.String(); str != test.str {
+			t.Errorf("Status-[%d]: expected '%s' but got '%s'", idx, test.str, str)
+		}
+		if status, err := ParseStatus(test.str); err != nil || status != test.status {
+			t.Errorf("Status-[%d]: ParseStatus('%s'): expected %d but got %d (%v)", idx, test.str, test.status, status, err)
+		}
+		data, err := json.Marshal(test.status)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var status Status
+		if err := json.Unmarshal(data, &status); err != nil || status != test.status {
+			t.Errorf("Status-[%d]: JSON round trip of %s: expected %d but got %d (%v)", idx, data, test.status, status, err)
+		}
+	}
+
+	var status Status
+	if err := json.Unmarshal([]byte("8193"), &status); err != nil || status != Info|FetchableUrl {
+		t.Errorf("Status: expected numeric JSON to be decoded as %d but got %d (%v)", Info|FetchableUrl, status, err)
+	}
+	if !(Error | StructuralError).IsError() || (Info | FetchableUrl).IsError() || !(Info | FetchableUrl).HasFlag(FetchableUrl) {
+		t.Error("Status: IsError or HasFlag report wrong results")
+	}
+	if severity := (Warning | Error | EmptyData).Severity(); severity != Error {
+		t.Errorf("Status: expected severity Error but got %s", severity)
+	}
+}
+
+type ruleTestExtras struct {
+	Publisher *string `json:"publisher" ogdat:"ID:20"`
+}
+
+type ruleTestMetadata struct {
+	Title *string `json:"title" ogdat:"ID:8"`
+	ruleTestExtras
+}
+
+func TestFieldByID(t *testing.T) {
+	title, publisher := "Titel", "Stadt Wien"
+	md := &ruleTestMetadata{Title: &title, ruleTestExtras: ruleTestExtras{Publisher: &publisher}}
+
+	if val, ok := FieldByID(md, 8); !ok || val.(*string) != &title {
+		t.Errorf("FieldByID: expected title but got %v (%v)", val, ok)
+	}
+	if val, ok := FieldByID(md, 20); !ok || val.(*string) != &publisher {
+		t.Errorf("FieldByID: expected publisher of embedded struct but got %v (%v)", val, ok)
+	}
+	if val, ok := FieldByID(md, 99); ok {
+		t.Errorf("FieldByID: expected no field but got %v", val)
+	}
+}
+
+func TestRules(t *testing.T) {
+	const version = "Test Metadata 9.9"
+	RegisterRule(&Rule{
+		Name:     "test-allowlist",
+		Fields:   []string{"test_publisher"},
+		Versions: []string{"9.9"},
+		Options:  map[string]string{"allowed": "Stadt Wien"},
+		Check: func(ctx *RuleContext) ([]CheckMessage, error) {
+			if value, ok := ctx.StringValue(); ok && value != ctx.Options["allowed"] {
+				return []CheckMessage{ctx.NewMessage(Warning, CodeValueNotAllowed, ValueParams(value, "allowed", ctx.Options["allowed"]))}, nil
+			}
+			return nil, nil
+		}})
+	defer SetRuleConfig(nil)
+
+	publisher := "Land Tirol"
+	field := &Beschreibung{ID: 20, OGD_Kurzname: "test_publisher"}
+	apply := func() []CheckMessage {
+		msgs, err := ApplyRules(&RuleContext{Version: version, Field: field, Value: &publisher, Resource: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return msgs
+	}
+
+	if msgs := apply(); len(msgs) != 1 || msgs[0].Type != Warning || msgs[0].OGDID != 20 || msgs[0].Params.Resource != 1 {
+		t.Fatalf("Rules: expected one warning for field 20 of resource 1 but got %v", msgs)
+	}
+	if msgs, _ := ApplyRules(&RuleContext{Version: "Test Metadata 9.8", Field: field, Value: &publisher}); len(msgs) != 0 {
+		t.Errorf("Rules: expected rule not to apply to other versions but got %v", msgs)
+	}
+
+	var configTests = []struct {
+		config string
+		num    int
+		typ    Status
+	}{
+		{`{"rules": {"test-allowlist": {"enabled": false}}}`, 0, 0},
+		{`{"rules": {"test-allowlist": {"severity": "Error"}}}`, 1, Error},
+		{`{"rules": {"test-allowlist": {"options": {"allowed": "Land Tirol"}}}}`, 0, 0},
+		{`{"rules": {"test-allowlist": {"fields": ["other_field"]}}}`, 0, 0},
+	}
+	for idx, test := range configTests {
+		cfg, err := LoadRuleConfig(strings.NewReader(test.config))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := SetRuleConfig(cfg); err != nil {
+			t.Fatalf("Rules-[%d]: %s", idx, err)
+		}
+		msgs := apply()
+		if len(msgs) != test.num || (test.num > 0 && msgs[0].Type != test.typ) {
+			t.Errorf("Rules-[%d]: expected %d messages of type %s but got %v", idx, test.num, test.typ, msgs)
+		}
+	}
+
+	for idx, config := range []string{`{"rules": {"no-such-rule": {}}}`, `{"rules": {"test-allowlist": {"severity": "Error|FetchableUrl"}}}`} {
+		cfg, err := LoadRuleConfig(strings.NewReader(config))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := SetRuleConfig(cfg); err == nil {
+			t.Errorf("Rules-[%d]: expected configuration '%s' to be rejected", idx, config)
+		}
+	}
+}
+
+func TestFix(t *testing.T) {
+	in := `{"title": "Stra&szlig;en", "notes": "<b>fett</b> &amp; mehr", "extras": {"categorization": "verkehr", "update_frequency": "monatlich", "metadata_modified": "2013-05-01T10:00:00", "begin_datetime": "2013-05-01", "unknown": "&auml;"}, "resources": [{"format": ".CSV", "name": "x"}, {"format": "json"}]}`
+	out, changes, err := Fix([]byte(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"title":                    `"Straßen"`,
+		"extras:metadata_modified": `"2013-05-01"`,
+		"extras:categorization":    `["verkehr"]`,
+		"extras:begin_datetime":    `"2013-05-01T00:00:00"`,
+		"extras:update_frequency":  `"monthly"`,
+		"resources:format":         `"csv"`,
+	}
+	if len(changes) != len(expected) {
+		t.Errorf("Fix: expected %d changes but got %d: %v", len(expected), len(changes), changes)
+	}
+	for _, change := range changes {
+		if change.New != expected[change.Field] {
+			t.Errorf("Fix: %s: expected %s but got %s", change.Field, expected[change.Field], change.New)
+		}
+		if change.Field == "resources:format" && (change.Resource != 0 || change.Code != CodeFormatInvalidChar) {
+			t.Errorf("Fix: unexpected change of resource format: %v", change)
+		}
+	}
+
+	var doc struct {
+		Notes  string                 `json:"notes"`
+		Extras map[string]interface{} `json:"extras"`
+	}
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Notes != "<b>fett</b> &amp; mehr" || doc.Extras["unknown"] != "&auml;" {
+		t.Errorf("Fix: expected fields with HTML or not in the specification to be retained, got %s", out)
+	}
+
+	if out, changes, err := Fix(out); err != nil || len(changes) != 0 {
+		t.Errorf("Fix: expected fixed document to need no more changes, got %v, %v: %s", changes, err, out)
+	}
+}
+
+func TestMarshalJSON(t *testing.T) {
+	u, _ := url.Parse("http://example.com/data")
+	id := uuid.Parse("0045692c-00e7-4e46-8bfc-336a92bd51e9")
+	tests := []struct {
+		value    interface{}
+		expected string
+	}{
+		{Time{Raw: "2012-10-17T10:00"}, `"2012-10-17T10:00"`},
+		{Time{Time: time.Date(2012, 10, 17, 0, 0, 0, 0, time.UTC), Format: CustomTimeSpecifier2}, `"2012-10-17"`},
+		{Url{URL: u}, `"http://example.com/data"`},
+		{Identifier{UUID: &id}, `"0045692c-00e7-4e46-8bfc-336a92bd51e9"`},
+		{CycMonthly, `"monthly"`},
+		{Cycle{NumID: -1, Raw: "ab und zu"}, `"ab und zu"`},
+		{MetaDataKategorie{Kategorie: []Kategorie{Umwelt}, IsString: true}, `"umwelt"`},
+		{MetaDataKategorie{Kategorie: []Kategorie{Umwelt, {NumID: -1, ID: "blah"}}}, `["umwelt","blah"]`},
+		{MetaDataKategorie{Kategorie: []Kategorie{Umwelt}, IsString: true, IsEmbedded: true}, `"[\"umwelt\"]"`},
+		{MetaDataLinkage{Url: []Url{{Raw: "http://example.com"}}}, `"http://example.com"`},
+		{MetaDataLinkage{Url: []Url{{Raw: "http://example.com"}}, IsArray: true}, `["http://example.com"]`},
+	}
+	for _, test := range tests {
+		data, err := json.Marshal(test.value)
+		if err != nil || string(data) != test.expected {
+			t.Errorf("MarshalJSON(%#v): expected %s but got %s (%v)", test.value, test.expected, data, err)
+		}
+	}
+
+	var kat MetaDataKategorie
+	if err := json.Unmarshal([]byte(`"[ \"umwelt\", \"blah\" ]"`), &kat); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := json.Marshal(kat); string(data) != `"[ \"umwelt\", \"blah\" ]"` {
+		t.Errorf("MarshalJSON: expected embedded array as given but got %s", data)
+	}
+	kat.Kategorie = kat.Kategorie[:1]
+	if data, _ := json.Marshal(kat); string(data) != `"[\"umwelt\"]"` {
+		t.Errorf("MarshalJSON: expected changed embedded array but got %s", data)
+	}
+}
+
+func TestDiffSpec(t *testing.T) {
+	v21, err := Loadogdatspec("2.1", ogdatv21specfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v23, err := Loadogdatspec("2.3", "ogdatv23/ogdat_spec-2.3.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diff := DiffSpec(v21, v23)
+	if diff.Old != "2.1" || diff.New != "2.3" {
+		t.Errorf("TestDiffSpec: unexpected versions %s, %s", diff.Old, diff.New)
+	}
+	added := diff.Kind(FieldAdded)
+	if len(added) != 2 || added[0].Name != "metadata_original_portal" || added[1].Name != "maintainer_email" {
+		t.Errorf("TestDiffSpec: expected metadata_original_portal and maintainer_email to be added, got %v", added)
+	}
+	if removed := diff.Kind(FieldRemoved); len(removed) != 0 {
+		t.Errorf("TestDiffSpec: expected no removed fields, got %v", removed)
+	}
+	publisher := false
+	for _, f := range diff.Kind(FieldChanged) {
+		for _, change := range f.Changes {
+			if f.Name == "publisher" && change.Property == "Occurrence" && change.Old == "O" && change.New == "R" {
+				publisher = true
+			}
+		}
+	}
+	if !publisher {
+		t.Errorf("TestDiffSpec: expected publisher to become required")
+	}
+
+	if removed := DiffSpec(v23, v21).Kind(FieldRemoved); len(removed) != 2 {
+		t.Errorf("TestDiffSpec: expected two removed fields, got %v", removed)
+	}
+	if same := DiffSpec(v23, v23); len(same.Fields) != 0 {
+		t.Errorf("TestDiffSpec: expected no differences, got %v", same.Fields)
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	v23, err := Loadogdatspec("OGD Austria Metadata 2.3", "ogdatv23/ogdat_spec-2.3.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := JSONSchema(v23)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type schema struct {
+		Schema     string             `json:"$schema"`
+		Title      string             `json:"title"`
+		Type       interface{}        `json:"type"`
+		Required   []string           `json:"required"`
+		Properties map[string]*schema `json:"properties"`
+		Items      *schema            `json:"items"`
+		Enum       []interface{}      `json:"enum"`
+		Pattern    string             `json:"pattern"`
+	}
+	var root schema
+	if err := json.Unmarshal(data, &root); err != nil {
+		t.Fatalf("TestJSONSchema: invalid JSON: %s", err)
+	}
+	if root.Schema != JSONSchemaDraft || root.Title != "OGD Austria Metadata 2.3" {
+		t.Errorf("TestJSONSchema: unexpected $schema '%s' or title '%s'", root.Schema, root.Title)
+	}
+	contains := func(list interface{}, s interface{}) bool {
+		v := reflect.ValueOf(list)
+		for idx := 0; v.Kind() == reflect.Slice && idx < v.Len(); idx++ {
+			if v.Index(idx).Interface() == s {
+				return true
+			}
+		}
+		return false
+	}
+	for _, field := range []string{"title", "notes", "license", "extras", "resources"} {
+		if !contains(root.Required, field) {
+			t.Errorf("TestJSONSchema: expected %s to be required, got %v", field, root.Required)
+		}
+	}
+
+	extras := root.Properties["extras"]
+	if extras == nil || !contains(extras.Required, "metadata_identifier") || !contains(extras.Required, "publisher") || contains(extras.Required, "schema_name") {
+		t.Fatalf("TestJSONSchema: unexpected required extras %v", extras)
+	}
+	if cat := extras.Properties["categorization"]; cat == nil || cat.Type != "array" || cat.Items == nil || !contains(cat.Items.Enum, Umwelt.ID) {
+		t.Errorf("TestJSONSchema: expected categorization to be an array of categories, got %v", cat)
+	}
+	if freq := extras.Properties["update_frequency"]; freq == nil || !contains(freq.Enum, CycDaily.MD_MaintenanceFrequencyCode) || !contains(freq.Enum, nil) {
+		t.Errorf("TestJSONSchema: expected update_frequency to enumerate the frequency codes or null, got %v", freq)
+	}
+
+	patterns := []struct {
+		field, valid, invalid string
+	}{
+		{"metadata_modified", "2012-12-24", "24.12.2012"},
+		{"begin_datetime", "2012-12-24T18:00:00", "2012-12-24"},
+	}
+	for _, p := range patterns {
+		property := extras.Properties[p.field]
+		if property == nil || property.Pattern == "" {
+			t.Errorf("TestJSONSchema: expected a pattern for %s", p.field)
+			continue
+		}
+		r := regexp.MustCompile(property.Pattern)
+		if !r.MatchString(p.valid) || r.MatchString(p.invalid) {
+			t.Errorf("TestJSONSchema: pattern %s of %s does not match '%s' only", property.Pattern, p.field, p.valid)
+		}
+	}
+
+	resources := root.Properties["resources"]
+	if resources == nil || resources.Type != "array" || resources.Items == nil || !contains(resources.Items.Required, "url") {
+		t.Errorf("TestJSONSchema: expected resources to be an array requiring url, got %v", resources)
+	} else if created := resources.Items.Properties["created"]; created == nil || !contains(created.Type, "string") || !contains(created.Type, "null") {
+		t.Errorf("TestJSONSchema: expected optional resource field created to be a string or null, got %v", created)
+	}
+}
+
+func TestCheckGeographicExtent(t *testing.T) {
+	tests := []struct {
+		bbox   string
+		closed bool
+		codes  []string
+	}{
+		{"POLYGON ((16.18 48.11, 16.58 48.11, 16.58 48.32, 16.18 48.32, 16.18 48.11))", true, nil},
+		{`{"type": "Polygon", "coordinates": [[[16.18, 48.11], [16.58, 48.11], [16.58, 48.32], [16.18, 48.32], [16.18, 48.11]]]}`, true, nil},
+		{"POLYGON (-180.00 -90.00, 180.00 90.00)", false, nil},
+		{"POLYGON (-180.00 -90.00, 180.00 90.00)", true, []string{CodeBBoxNotClosed}},
+		{"POLYGON ((16.18 48.11, 16.58 48.11, 16.58 48.32, 16.18 48.32))", false, []string{CodeBBoxNotClosed}},
+		{"POLYGON ((16.18 48.11, 16.18 48.32, 16.58 48.32, 16.58 48.11, 16.18 48.11))", true, []string{CodeBBoxWinding}},
+		{"POLYGON ((48.11 16.18, 48.11 16.58, 48.32 16.58, 48.32 16.18, 48.11 16.18))", true, []string{CodeBBoxWinding, CodeBBoxSwapped}},
+		{"POLYGON ((48.11 100, 48.32 100, 48.32 101, 48.11 101, 48.11 100))", true, []string{CodeBBoxRange, CodeBBoxOutsideAustria}},
+		{"POINT (2.35 48.86)", true, []string{CodeBBoxOutsideAustria}},
+		{"POLYGON ((16,18 48,11))", true, []string{CodeBBoxInvalid}},
+	}
+	for idx, test := range tests {
+		infos := CheckGeographicExtent(test.bbox, test.closed)
+		if len(infos) != len(test.codes) {
+			t.Errorf("TestCheckGeographicExtent [%d]: expected %v, got %d messages", idx, test.codes, len(infos))
+			continue
+		}
+		for iidx, info := range infos {
+			if info.Code != test.codes[iidx] {
+				t.Errorf("TestCheckGeographicExtent [%d]: expected %s, got %s", idx, test.codes[iidx], info.Code)
+			}
+		}
+	}
+
+	if b := GeographicBBox(pstr("ENVELOPE(16.18, 16.58, 48.32, 48.11)")); b == nil || b.West != 16.18 || b.North != 48.32 {
+		t.Errorf("TestCheckGeographicExtent: unexpected bounding box %v", b)
+	}
+	if b := GeographicBBox(pstr("POLYGON")); b != nil {
+		t.Errorf("TestCheckGeographicExtent: expected no bounding box for an invalid extent, got %v", b)
+	}
+}
+
+func TestGazetteer(t *testing.T) {
+	g, err := Toponyms()
+	if err != nil {
+		t.Fatal(err)
+	}
+	lookups := []struct {
+		name, gkz string
+	}{
+		{"Wien", "9"},
+		{"Vienna", "9"},
+		{"Land Oberösterreich", "4"},
+		{"Oberoesterreich", "4"},
+		{"Sankt Pölten", "30201"},
+		{"ST. PÖLTEN", "30201"},
+		{"Bezirk Baden", "306"},
+		{"Graz-Stadt", "601"},
+		{"Rudolfsheim-Fünfhaus", "91501"},
+		{"ACME country", ""},
+	}
+	for _, test := range lookups {
+		places := g.Lookup(test.name)
+		switch {
+		case test.gkz == "" && places != nil:
+			t.Errorf("TestGazetteer: expected '%s' to be unknown, got %s", test.name, places[0].GKZ)
+		case test.gkz != "" && (places == nil || places[0].GKZ != test.gkz):
+			t.Errorf("TestGazetteer: expected '%s' to resolve to %s, got %v", test.name, test.gkz, places)
+		}
+	}
+
+	// places without own bounding box inherit the one of the Bundesland
+	baden, _ := g.LookupGKZ("306")
+	noe, _ := g.LookupGKZ("3")
+	if baden == nil || baden.BBox == nil || baden.BBox != noe.BBox {
+		t.Errorf("TestGazetteer: expected Baden to inherit the bounding box of Niederösterreich")
+	}
+
+	names, err := NormalizeToponym("Wien, Niederoesterreich und Entenhausen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(names, "|") != "Wien|Niederösterreich|Entenhausen" {
+		t.Errorf("TestGazetteer: unexpected normalized toponyms %v", names)
+	}
+
+	md := stubmetadata{&MinimalMetaData{BBox: GeographicBBox(pstr("POLYGON ((16.18 48.11, 16.58 48.11, 16.58 48.32, 16.18 48.32, 16.18 48.11))"))}}
+	checks := []struct {
+		toponym string
+		codes   []string
+	}{
+		{"Wien", nil},
+		{"Salzburg", []string{CodeToponymBBox}},
+		{"Vienna", []string{CodeToponymSpelling}},
+		{"Wien; Entenhausen", []string{CodeToponymUnknown}},
+	}
+	for idx, test := range checks {
+		ctx := &RuleContext{Metadata: md, Field: &Beschreibung{ID: 22, OGD_Kurzname: "geographic_toponym"}, Value: pstr(test.toponym), Resource: -1}
+		msgs, err := checktoponym(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(msgs) != len(test.codes) {
+			t.Errorf("TestGazetteer [%d]: expected %v, got %v", idx, test.codes, msgs)
+			continue
+		}
+		for midx, msg := range msgs {
+			if msg.Code != test.codes[midx] {
+				t.Errorf("TestGazetteer [%d]: expected %s, got %s", idx, test.codes[midx], msg.Code)
+			}
+		}
+	}
+}
+
+// stubmetadata provides the minimal metadata, as the bounding box, to rules
+// checking other fields
+type stubmetadata struct {
+	md *MinimalMetaData
+}
+
+func (b stubmetadata) Check(bool) ([]CheckMessage, error) { return nil, nil }
+func (b stubmetadata) MinimalMetadata() *MinimalMetaData  { return b.md }
+
+func TestLicenses(t *testing.T) {
+	reg, err := Licenses()
+	if err != nil {
+		t.Fatal(err)
+	}
+	lookups := []struct {
+		value, id string
+	}{
+		{"https://creativecommons.org/licenses/by/3.0/at/", "CC-BY-3.0-AT"},
+		{"http://creativecommons.org/licenses/by/3.0/at/deed.de", "CC-BY-3.0-AT"},
+		{"http://www.creativecommons.org/licenses/by/4.0/legalcode", "CC-BY-4.0"},
+		{"Creative Commons Namensnennung 3.0 Österreich", "CC-BY-3.0-AT"},
+		{"CC BY 4.0", "CC-BY-4.0"},
+		{"cc0", "CC0-1.0"},
+		{"dl-de/by-2-0", "DL-DE-BY-2.0"},
+		{"https://www.govdata.de/dl-de/by-2-0", "DL-DE-BY-2.0"},
+		{"https://example.com/licenses/by/3.0/at/", ""},
+		{"Alle Rechte vorbehalten", ""},
+	}
+	for _, test := range lookups {
+		license, ok := reg.Lookup(test.value)
+		switch {
+		case test.id == "" && ok:
+			t.Errorf("TestLicenses: expected '%s' to be unknown, got %s", test.value, license.ID)
+		case test.id != "" && (!ok || license.ID != test.id):
+			t.Errorf("TestLicenses: expected '%s' to be %s, got %v", test.value, test.id, license)
+		}
+	}
+
+	checks := []struct {
+		license  string
+		citation *string
+		codes    []string
+	}{
+		{"https://creativecommons.org/licenses/by/4.0/", pstr("Stadt Wien"), nil},
+		{"https://creativecommons.org/licenses/by/4.0/", nil, []string{CodeCitationMissing}},
+		{"https://creativecommons.org/licenses/by/4.0/", pstr(" "), []string{CodeCitationMissing}},
+		{"CC BY 4.0", pstr("Stadt Wien"), []string{CodeLicenseName}},
+		{"Alle Rechte vorbehalten", nil, []string{CodeLicenseNotURI}},
+		{"https://example.com/lizenz", nil, []string{CodeLicenseUnknown}},
+		{"https://creativecommons.org/publicdomain/zero/1.0/", pstr("Stadt Wien"), []string{CodeCitationNotRequired}},
+	}
+	for idx, test := range checks {
+		md := stubmetadata{&MinimalMetaData{License: pstr(test.license), Extras: Extras{License_Citation: test.citation}}}
+		ctx := &RuleContext{Metadata: md, Field: &Beschreibung{ID: 21, OGD_Kurzname: "license"}, Value: pstr(test.license), Resource: -1}
+		msgs, err := checklicense(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(msgs) != len(test.codes) {
+			t.Errorf("TestLicenses [%d]: expected %v, got %v", idx, test.codes, msgs)
+			continue
+		}
+		for midx, msg := range msgs {
+			if msg.Code != test.codes[midx] {
+				t.Errorf("TestLicenses [%d]: expected %s, got %s", idx, test.codes[midx], msg.Code)
+			}
+		}
+	}
+}
+
+func TestFormats(t *testing.T) {
+	reg, err := Formats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	lookups := []struct {
+		value, id string
+	}{
+		{"csv", "csv"},
+		{".CSV", "csv"},
+		{"text/csv; charset=utf-8", "csv"},
+		{"Shapefile", "shp"},
+		{"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", "xlsx"},
+		{"GeoTIFF", "tif"},
+		{"CSV:xml", ""},
+	}
+	for _, test := range lookups {
+		format, ok := reg.Lookup(test.value)
+		switch {
+		case test.id == "" && ok:
+			t.Errorf("TestFormats: expected '%s' to be unknown, got %s", test.value, format.ID)
+		case test.id != "" && (!ok || format.ID != test.id):
+			t.Errorf("TestFormats: expected '%s' to be %s, got %v", test.value, test.id, format)
+		}
+	}
+
+	known := []struct {
+		format, code string
+	}{
+		{"csv", ""},
+		{".CSV", ""},
+		{"Shapefile", CodeFormatSynonym},
+		{"CSV:xml", CodeFormatUnknown},
+	}
+	for idx, test := range known {
+		spec := ResourceSpecifier(test.format)
+		ctx := &RuleContext{Field: &Beschreibung{ID: 15, OGD_Kurzname: "resource_format"}, Value: &spec, Resource: 0}
+		msgs, err := checkresourceformatknown(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch {
+		case test.code == "" && len(msgs) > 0:
+			t.Errorf("TestFormats known [%d]: expected no message, got %v", idx, msgs)
+		case test.code != "" && (len(msgs) != 1 || msgs[0].Code != test.code):
+			t.Errorf("TestFormats known [%d]: expected %s, got %v", idx, test.code, msgs)
+		}
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/data.csv":
+			w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+			fmt.Fprint(w, "Bezirk;Einwohner\nInnere Stadt;16047\n")
+		case "/download":
+			w.Header().Set("Content-Type", "application/octet-stream")
+			fmt.Fprint(w, "Bezirk;Einwohner\nInnere Stadt;16047\n")
+		case "/portal":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			fmt.Fprint(w, "<!DOCTYPE html><html><body>Datenportal</body></html>")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	checks := []struct {
+		format, path string
+		codes        []string
+	}{
+		{"csv", "/data.csv", nil},
+		{"CSV", "/data.csv", nil},
+		{"csv", "/download", nil},
+		{"csv", "/portal", []string{CodeFormatContentType, CodeFormatContent}},
+		{"pdf", "/download", []string{CodeFormatContent}},
+		{"csv", "/missing", nil},
+		{"CSV:xml", "/portal", nil},
+	}
+	for idx, test := range checks {
+		spec := ResourceSpecifier(test.format)
+		md := stubmetadata{&MinimalMetaData{Resources: []MinimalResource{{URL: pstr(server.URL + test.path), Format: pstr(test.format)}}}}
+		ctx := &RuleContext{Metadata: md, Field: &Beschreibung{ID: 15, OGD_Kurzname: "resource_format"}, Value: &spec, Resource: 0, FollowHTTPLinks: true}
+		msgs, err := checkresourcecontent(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(msgs) != len(test.codes) {
+			t.Errorf("TestFormats [%d]: expected %v, got %v", idx, test.codes, msgs)
+			continue
+		}
+		for midx, msg := range msgs {
+			if msg.Code != test.codes[midx] {
+				t.Errorf("TestFormats [%d]: expected %s, got %s", idx, test.codes[midx], msg.Code)
+			} else if msg.Params.Resource != 0 {
+				t.Errorf("TestFormats [%d]: expected resource 0, got %d", idx, msg.Params.Resource)
+			}
+		}
+	}
+}
+
+func TestResourceHead(t *testing.T) {
+	lastmodified := time.Date(2024, 3, 14, 9, 30, 0, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/data.csv" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Last-Modified", lastmodified.Format(http.TimeFormat))
+		w.Header().Set("ETag", `"5a1c-3f"`)
+		w.Header().Set("Content-Length", "2048")
+	}))
+	defer server.Close()
+
+	head, info := FetchResourceHead(server.URL + "/data.csv")
+	if head == nil {
+		t.Fatalf("TestResourceHead: expected a head, got %v", info)
+	}
+	if head.ContentLength != 2048 || head.LastModified == nil || !head.LastModified.Equal(lastmodified) || head.ETag != `"5a1c-3f"` {
+		t.Errorf("TestResourceHead: unexpected head %+v", head)
+	}
+	if info.Code != CodeURLReachable || info.Args["contentlength"] != "2048" || info.Args["etag"] != `"5a1c-3f"` {
+		t.Errorf("TestResourceHead: unexpected check info %+v", info)
+	}
+	if msg := info.Message(14, 0); msg.Head != head {
+		t.Errorf("TestResourceHead: expected the message to carry the head, got %+v", msg.Head)
+	}
+	if head, info = FetchResourceHead(server.URL + "/missing"); head != nil || info.Code != CodeURLStatus {
+		t.Errorf("TestResourceHead: expected status error, got %v, %+v", head, info)
+	}
+
+	checks := []struct {
+		path, size, lastmodified string
+		codes                    []string
+	}{
+		{"/data.csv", "2048", "2024-03-14", []string{CodeURLFetchable, CodeURLReachable}},
+		{"/data.csv", "899652", "2024-03-14", []string{CodeURLFetchable, CodeURLReachable, CodeSizeMismatch}},
+		{"/data.csv", "2048", "2024-01-15", []string{CodeURLFetchable, CodeURLReachable, CodeLastModifiedStale}},
+		{"/data.csv", "2048", "2024-04-01", []string{CodeURLFetchable, CodeURLReachable, CodeLastModifiedAhead}},
+		{"/data.csv", "2 MB", "15.01.2024", []string{CodeURLFetchable, CodeURLReachable}},
+		{"/missing", "899652", "2024-01-15", []string{CodeURLFetchable, CodeURLStatus}},
+	}
+	for idx, test := range checks {
+		link, _ := url.Parse(server.URL + test.path)
+		var modified Time
+		if err := json.Unmarshal([]byte(`"`+test.lastmodified+`"`), &modified); err != nil {
+			t.Fatal(err)
+		}
+		md := stubmetadata{&MinimalMetaData{Resources: []MinimalResource{{URL: pstr(link.String()), Size: pstr(test.size), LastModified: &modified}}}}
+		ctx := &RuleContext{Metadata: md, Field: &Beschreibung{ID: 14, OGD_Kurzname: "resource_url"}, Value: &Url{URL: link, Raw: link.String()}, Resource: 0, FollowHTTPLinks: true}
+		msgs, err := checklink(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(msgs) != len(test.codes) {
+			t.Errorf("TestResourceHead [%d]: expected %v, got %v", idx, test.codes, msgs)
+			continue
+		}
+		for midx, msg := range msgs {
+			if msg.Code != test.codes[midx] {
+				t.Errorf("TestResourceHead [%d]: expected %s, got %s", idx, test.codes[midx], msg.Code)
+			}
+		}
+	}
+}
@@ -1,52 +1,30 @@
 package ogdat
 
 import (
-	"bufio"
 	"encoding/csv"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"log"
 	"net/http"
 	"os"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 	"unicode"
 	"unicode/utf8"
 )
 
-const (
-	Info    = 1 << iota
-	Warning = 1 << iota
-	Error   = 1 << iota
-)
-
-const (
-	FetchSuccess = 0x1000
-	FetchableUrl = 0x2000
-
-	NoDataatUrlError = 0x4000
-	StructuralError  = 0x8000
-
-	EmptyData = 0x16000
-)
-
-var isolangfilemap map[string]*ISO6392Lang = nil
-
-type ISO6392Lang struct {
-	Code, Identifier string
-}
-
-func CheckISOLanguage(lang string) bool {
-	const iso639file = "ISO-639-2_utf-8.txt"
-	if isolangfilemap == nil {
-		var err error
-		if isolangfilemap, err = loadisolanguagefile(iso639file); err != nil {
-			panic(fmt.Sprintf("Can not load ISO language file '%s'", iso639file))
-		}
+// CheckISOLanguage reports whether lang is a valid ISO 639-2 language code.
+// The language table gets loaded on first use, an error is returned if it can not be loaded.
+func CheckISOLanguage(lang string) (bool, error) {
+	reg, err := Languages()
+	if err != nil {
+		return false, err
 	}
-	_, ok := isolangfilemap[lang]
-	return ok
+	_, ok := reg.Lookup(lang)
+	return ok, nil
 }
 
 // copmpare input against a slice of check strings.
@@ -63,75 +41,17 @@ func CheckEncodingString(input string, check []string) bool {
 	return false
 }
 
-func loadisolanguagefile(filename string) (isolangfilemap map[string]*ISO6392Lang, _ error) {
-
-	reader, err := os.Open(filename)
-	if err != nil {
-		return nil, err
-	}
-
-	defer reader.Close()
-	isolangfilemap = make(map[string]*ISO6392Lang)
-	csvreader := csv.NewReader(reader)
-	csvreader.Comma = '|'
-
-	for record, err := csvreader.Read(); err != io.EOF; record, err = csvreader.Read() {
-		isorecord := &ISO6392Lang{Code: record[0], Identifier: record[3]}
-		isolangfilemap[isorecord.Code] = isorecord
-		if len(record[1]) > 0 {
-			isorecord = &ISO6392Lang{Code: record[1], Identifier: record[3]}
-			isolangfilemap[record[1]] = isorecord
-		}
-	}
-	log.Printf("Info: Read %d ISO language records", len(isolangfilemap))
-
-	return
-}
-
-var ianaencmap map[string]struct{} = nil
-
 // CheckIANAEncoding will try to match and input of enc against the specified encodings found at http://www.iana.org/assignments/character-sets/character-sets.xml
 // The file at http://www.iana.org/assignments/character-sets/character-sets.xml is retrieved by a shell scripte,
 // converted to all-lower case and sorted for unique entries. Thus the encoding enc against which will be checked,
-// is converted to lower case and then compared to the IANA-encodings
-func CheckIANAEncoding(enc string) bool {
-	const ianaencfile = "character-sets.txt"
-	if ianaencmap == nil {
-		var err error
-		if ianaencmap, err = loadianaencodingfile(ianaencfile); err != nil {
-			panic(fmt.Sprintf("Can not load IANA encoding definition file '%s': %s", ianaencfile, err))
-		}
-	}
-	enc = strings.ToLower(enc)
-	_, ok := ianaencmap[enc]
-	if !ok {
-		enc = strings.Replace(enc, "-", "", -1)
-		_, ok = ianaencmap[enc]
-	}
-	return ok
-}
-
-func loadianaencodingfile(filename string) (ianamap map[string]struct{}, _ error) {
-	reader, err := os.Open(filename)
+// is converted to lower case and then compared to the IANA-encodings.
+// An error is returned if the encoding definitions can not be loaded.
+func CheckIANAEncoding(enc string) (bool, error) {
+	reg, err := Encodings()
 	if err != nil {
-		return nil, err
-	}
-	defer reader.Close()
-
-	ianamap = make(map[string]struct{})
-	bufreader := bufio.NewReader(reader)
-	delim := byte('\n')
-
-	for line, err := bufreader.ReadString(delim); err != io.EOF; line, err = bufreader.ReadString(delim) {
-		// ReadString includes the delimeter, get rid of it
-		line = line[:len(line)-1]
-		// normalize by lower casing
-		line = strings.ToLower(line)
-
-		ianamap[line] = struct{}{}
+		return false, err
 	}
-	log.Printf("Info: Read %d IANA encoding names", len(ianamap))
-	return
+	return reg.Contains(enc), nil
 }
 
 func min(a, b int) int {
@@ -142,8 +62,13 @@ func min(a, b int) int {
 }
 
 type CheckInfo struct {
-	Status, Position int
-	Context          string
+	Status   Status
+	Position int
+	Context  string
+	Code     string            // stable code of the message, cf. messages.go
+	Value    string            // the checked value
+	Args     map[string]string // further arguments of the message
+	Head     *ResourceHead     // what the server reported for a reachable link, nil otherwise
 }
 
 func (c *CheckInfo) Error() string {
@@ -201,34 +126,71 @@ var regexpposixescape = regexp.MustCompile(`\\n|\\b|\\v|\\t|\\r`)
 // message: An error message describing the problem
 func CheckOGDTextStringForSaneCharacters(str string) (ok bool, _ error) {
 	if !utf8.ValidString(str) {
-		return false, &CheckInfo{Error, 0, "Zeichenfolge ist nicht durchgängig gültig als UTF8 kodiert"}
+		return false, &CheckInfo{Status: Error, Position: 0, Context: "Zeichenfolge ist nicht durchgängig gültig als UTF8 kodiert",
+			Code: CodeTextInvalidUTF8, Value: str}
 	}
 	for idx, val := range str {
 		if val == unicode.ReplacementChar {
-			return false, &CheckInfo{Error, idx, fmt.Sprintf("Ungültige Unicode-Sequenz: '0x%x' (Bereich '%s')", val, strrange(-20, 20, idx, str))}
+			char, excerpt := fmt.Sprintf("0x%x", val), strrange(-20, 20, idx, str)
+			return false, &CheckInfo{Status: Error, Position: idx, Context: fmt.Sprintf("Ungültige Unicode-Sequenz: '%s' (Bereich '%s')", char, excerpt),
+				Code: CodeTextInvalidUnicode, Value: str, Args: map[string]string{"char": char, "excerpt": excerpt}}
 		}
 	}
 
 	if idx := regexphtmlcodecheck.FindStringIndex(str); idx != nil {
-		return false, &CheckInfo{Warning, idx[0], fmt.Sprintf("Mögliche HTML-Sequenz: '%s'", strrange(-10, 10, idx[0], str))}
+		excerpt := strrange(-10, 10, idx[0], str)
+		return false, &CheckInfo{Status: Warning, Position: idx[0], Context: fmt.Sprintf("Mögliche HTML-Sequenz: '%s'", excerpt),
+			Code: CodeTextHTML, Value: str, Args: map[string]string{"excerpt": excerpt}}
 	}
 	if idx := regexphtmlescape.FindStringIndex(str); idx != nil {
-		return false, &CheckInfo{Warning, idx[0], fmt.Sprintf("Mögliche HTML-Escapes: '%s'", strrange(-8, 8, idx[0], str))}
+		excerpt := strrange(-8, 8, idx[0], str)
+		return false, &CheckInfo{Status: Warning, Position: idx[0], Context: fmt.Sprintf("Mögliche HTML-Escapes: '%s'", excerpt),
+			Code: CodeTextHTMLEscape, Value: str, Args: map[string]string{"excerpt": excerpt}}
 	}
 	if idx := regexpurlencode.FindStringIndex(str); idx != nil {
-		return false, &CheckInfo{Warning, idx[0], fmt.Sprintf("Mögliche Url-Escapes: '%s'", strrange(-6, 6, idx[0], str))}
+		excerpt := strrange(-6, 6, idx[0], str)
+		return false, &CheckInfo{Status: Warning, Position: idx[0], Context: fmt.Sprintf("Mögliche Url-Escapes: '%s'", excerpt),
+			Code: CodeTextURLEscape, Value: str, Args: map[string]string{"excerpt": excerpt}}
 	}
 	if idx := regexpposixescape.FindStringIndex(str); idx != nil {
-		return false, &CheckInfo{Warning, idx[0], fmt.Sprintf("Mögliche Posix-Escapes: '%s'", strrange(-6, 6, idx[0], str))}
+		excerpt := strrange(-6, 6, idx[0], str)
+		return false, &CheckInfo{Status: Warning, Position: idx[0], Context: fmt.Sprintf("Mögliche Posix-Escapes: '%s'", excerpt),
+			Code: CodeTextPosixEscape, Value: str, Args: map[string]string{"excerpt": excerpt}}
 	}
 	return true, nil
 }
 
 var regexpEMail = regexp.MustCompile(`(?i)^[A-Z0-9._%+-]+@[A-Z0-9.-]+\.[A-Z]{2,4}$`)
 
-func FetchHead(url string) (bool, CheckInfo) {
+// ResourceHead is what a server reports about a resource in the header of its response
+type ResourceHead struct {
+	URL           string
+	ContentLength int64      // -1 if not reported
+	LastModified  *time.Time // nil if not reported or invalid
+	ETag          string
+}
+
+// args returns the reported values as arguments of a message
+func (head *ResourceHead) args() map[string]string {
+	args := make(map[string]string)
+	if head.ContentLength > -1 {
+		args["contentlength"] = strconv.FormatInt(head.ContentLength, 10)
+	}
+	if head.LastModified != nil {
+		args["lastmodified"] = head.LastModified.Format(time.RFC3339)
+	}
+	if head.ETag != "" {
+		args["etag"] = head.ETag
+	}
+	return args
+}
+
+// FetchResourceHead requests url, first by HEAD, then by GET if that fails,
+// and returns the header values of the response. head is nil if url can not be
+// fetched. If it can, info carries head and the header values as arguments
+// "contentlength", "lastmodified" and "etag", if reported.
+func FetchResourceHead(url string) (head *ResourceHead, info CheckInfo) {
 
-	var info CheckInfo
 	var err error
 	var resp *http.Response
 	var s string
@@ -241,32 +203,129 @@ func FetchHead(url string) (bool, CheckInfo) {
 			resp, err = http.Get(url)
 			s = "Get"
 		}
-		if err == nil && resp.StatusCode == 200 {
-			break
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == 200 {
+				break
+			}
 		}
 	}
 
 	if err != nil {
-		info = CheckInfo{Status: Error | FetchableUrl | NoDataatUrlError, Position: -1, Context: fmt.Sprintf("%s URL kann nicht aufgelöst werden: %s (%s)", url, err, s)}
+		info = CheckInfo{Status: Error | FetchableUrl | NoDataatUrlError, Position: -1, Context: fmt.Sprintf("%s URL kann nicht aufgelöst werden: %s (%s)", url, err, s),
+			Code: CodeURLUnreachable, Value: url, Args: map[string]string{"error": err.Error(), "method": s}}
 	} else if sc := resp.StatusCode; sc != 200 {
-		info = CheckInfo{Status: Error | FetchableUrl | NoDataatUrlError, Position: -1, Context: fmt.Sprintf("%s liefert nicht-OK Status-Code '%d' (%s)", url, sc, s)}
+		info = CheckInfo{Status: Error | FetchableUrl | NoDataatUrlError, Position: -1, Context: fmt.Sprintf("%s liefert nicht-OK Status-Code '%d' (%s)", url, sc, s),
+			Code: CodeURLStatus, Value: url, Args: map[string]string{"status": strconv.Itoa(sc), "method": s}}
 	} else {
-		info = CheckInfo{Status: Info | FetchableUrl | FetchSuccess, Position: -1, Context: url}
+		head = &ResourceHead{URL: url, ContentLength: resp.ContentLength, ETag: resp.Header.Get("ETag")}
+		if lm, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+			head.LastModified = &lm
+		}
+		info = CheckInfo{Status: Info | FetchableUrl | FetchSuccess, Position: -1, Context: url,
+			Code: CodeURLReachable, Value: url, Args: head.args(), Head: head}
 	}
+	return head, info
+}
 
-	return (info.Status & (Info | FetchSuccess)) == (Info | FetchSuccess), info
+func FetchHead(url string) (bool, CheckInfo) {
+	head, info := FetchResourceHead(url)
+	return head != nil, info
+}
+
+// CompareResourceHead compares the size and the date of last modification
+// declared for a resource with the header values reported by the server. size
+// and lastmodified are nil if not declared. As resource_lastmodified mostly is
+// a date only, dates are compared by day in UTC.
+func CompareResourceHead(size *string, lastmodified *time.Time, head *ResourceHead) []CheckInfo {
+	var checkmessages []CheckInfo
+
+	// servers report 0 for content generated on request
+	if size != nil && head.ContentLength > 0 {
+		if declared, err := strconv.ParseInt(strings.TrimSpace(*size), 10, 64); err == nil && declared != head.ContentLength {
+			contentlength := strconv.FormatInt(head.ContentLength, 10)
+			checkmessages = append(checkmessages, CheckInfo{Status: Warning | FetchableUrl | FetchSuccess, Position: -1,
+				Context: fmt.Sprintf("Als Größe sind %s Bytes angegeben, der Server meldet jedoch %s Bytes: %s", *size, contentlength, head.URL),
+				Code:    CodeSizeMismatch, Value: *size, Args: map[string]string{"contentlength": contentlength, "url": head.URL}})
+		}
+	}
+
+	if lastmodified != nil && head.LastModified != nil {
+		const day = "2006-01-02"
+		declared, observed := lastmodified.UTC().Format(day), head.LastModified.UTC().Format(day)
+		switch {
+		case observed > declared:
+			checkmessages = append(checkmessages, CheckInfo{Status: Warning | FetchableUrl | FetchSuccess, Position: -1,
+				Context: fmt.Sprintf("Als Änderungsdatum ist %s angegeben, der Server meldet jedoch eine Änderung am %s: %s", declared, observed, head.URL),
+				Code:    CodeLastModifiedStale, Value: declared, Args: map[string]string{"lastmodified": observed, "url": head.URL}})
+		case observed < declared:
+			checkmessages = append(checkmessages, CheckInfo{Status: Info | FetchableUrl | FetchSuccess, Position: -1,
+				Context: fmt.Sprintf("Als Änderungsdatum ist %s angegeben, der Server meldet jedoch die letzte Änderung am %s: %s", declared, observed, head.URL),
+				Code:    CodeLastModifiedAhead, Value: declared, Args: map[string]string{"lastmodified": observed, "url": head.URL}})
+		}
+	}
+	return checkmessages
+}
+
+// FetchContent retrieves at most the first n bytes of the resource at url
+// together with the Content-Type the server delivers it with
+func FetchContent(url string, n int64) (contenttype string, data []byte, err error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return "", nil, err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=0-%d", n-1))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", nil, err
+	}
+	defer resp.Body.Close()
+
+	if sc := resp.StatusCode; sc != http.StatusOK && sc != http.StatusPartialContent {
+		return "", nil, fmt.Errorf("Status-Code '%d'", sc)
+	}
+	data, err = ioutil.ReadAll(io.LimitReader(resp.Body, n))
+	return resp.Header.Get("Content-Type"), data, err
+}
+
+// number of bytes fetched from a resource to recognise its content
+const contentsniffsize = 4096
+
+// CheckResourceContent compares the Content-Type and the first bytes of the
+// resource at url with format. Resources which can not be fetched yield no
+// results, as the check of the link reports them.
+func CheckResourceContent(url string, format *Format) []CheckInfo {
+	contenttype, data, err := FetchContent(url, contentsniffsize)
+	if err != nil {
+		return nil
+	}
+
+	var checkmessages []CheckInfo
+	// servers deliver anything as octet-stream, which says nothing about the format
+	if mt := mediatype(contenttype); mt != "" && mt != "application/octet-stream" && mt != "binary/octet-stream" && !format.AcceptsContentType(mt) {
+		checkmessages = append(checkmessages, CheckInfo{Status: Warning | FetchableUrl | FetchSuccess, Position: -1, Context: fmt.Sprintf("Als Format ist %s angegeben, der Server liefert jedoch %s: %s", format.ID, mt, url),
+			Code: CodeFormatContentType, Value: format.ID, Args: map[string]string{"contenttype": mt, "url": url}})
+	}
+	if len(data) > 0 {
+		if sniffed, ok := format.AcceptsContent(data); !ok {
+			checkmessages = append(checkmessages, CheckInfo{Status: Warning | FetchableUrl | FetchSuccess, Position: -1, Context: fmt.Sprintf("Als Format ist %s angegeben, der Inhalt ist jedoch %s: %s", format.ID, sniffed, url),
+				Code: CodeFormatContent, Value: format.ID, Args: map[string]string{"content": sniffed, "url": url}})
+		}
+	}
+	return checkmessages
 }
 
 func CheckUrl(url string, followhttplink bool) (bool, []CheckInfo) {
 	// it's a contact point if it's a http-link (starts with "http(s)" )
 	var checkmessages []CheckInfo
 	if len(url) >= 4 && url[:4] == "http" {
-		urlinfo := CheckInfo{Info | FetchableUrl, -1, url}
+		urlinfo := CheckInfo{Status: Info | FetchableUrl, Position: -1, Context: url,
+			Code: CodeURLFetchable, Value: url}
 		checkmessages = append(checkmessages, urlinfo)
 
 		ok := true
 		if followhttplink {
-			ok, urlinfo = FetchHead(urlinfo.Context)
+			ok, urlinfo = FetchHead(urlinfo.Value)
 			checkmessages = append(checkmessages, urlinfo)
 		}
 		return ok, checkmessages
@@ -277,11 +336,13 @@ func CheckUrl(url string, followhttplink bool) (bool, []CheckInfo) {
 	}
 
 	if len(url) == 0 {
-		checkmessages = append(checkmessages, CheckInfo{Status: Error, Position: -1, Context: "kein Wert für Link angegeben (Länge 0)"})
+		checkmessages = append(checkmessages, CheckInfo{Status: Error, Position: -1, Context: "kein Wert für Link angegeben (Länge 0)", Code: CodeLinkEmpty})
 		return false, checkmessages
 	}
 
-	checkmessages = append(checkmessages, CheckInfo{Status: Warning, Position: -1, Context: fmt.Sprintf("vermutlich keine gültige Web- oder E-Mail Adresse: '%s' (Auszug)", url[:min(20, len(url))])})
+	excerpt := url[:min(20, len(url))]
+	checkmessages = append(checkmessages, CheckInfo{Status: Warning, Position: -1, Context: fmt.Sprintf("vermutlich keine gültige Web- oder E-Mail Adresse: '%s' (Auszug)", excerpt),
+		Code: CodeLinkSuspicious, Value: url, Args: map[string]string{"excerpt": excerpt}})
 
 	return false, checkmessages
 }
@@ -292,36 +353,54 @@ func CheckDataPortalUrl(url string, followhttplink bool) (bool, []CheckInfo) {
 	ok1, checkmessages := CheckUrl(url, followhttplink)
 	ok2 := strings.Contains(url, checkDataPortalUrlstrpart)
 	if !ok2 {
-		checkmessages = append(checkmessages, CheckInfo{Status: Warning, Position: -1, Context: fmt.Sprintf("Link zum ursprünglichen Datenportal folgt nicht der Konvention ress://data.[....]: %s (Auszug)", url[:min(20, len(url))])})
+		excerpt := url[:min(20, len(url))]
+		checkmessages = append(checkmessages, CheckInfo{Status: Warning, Position: -1, Context: fmt.Sprintf("Link zum ursprünglichen Datenportal folgt nicht der Konvention ress://data.[....]: %s (Auszug)", excerpt),
+			Code: CodePortalURLConvention, Value: url, Args: map[string]string{"excerpt": excerpt}})
 	}
 
 	return ok1 == true && ok2 == true, checkmessages
 }
 
 type CheckMessage struct {
-	Type    int // 1 = Info, 2 = Warning, 4 = Error, ...
-	Text    string
+	Type    Status // severity and flags, cf. status.go
+	Text    string // German text of the message, use Render for other languages
 	OGDID   int
 	Context string
+	Code    string        // stable, machine readable code, e.g. "OGD-URL-UNREACHABLE"
+	Params  MessageParams // structured parameters, only meaningful if Code is set
+	Head    *ResourceHead // what the server reported for a reachable link, nil otherwise
 }
 
 func AppendcheckerrorTocheckmessage(msgs []CheckMessage, checkresults []CheckInfo, ID int, prepend string) []CheckMessage {
 	for _, result := range checkresults {
-		msgs = append(msgs, CheckMessage{
-			Type:  result.Status,
-			OGDID: ID,
-			Text:  prepend + result.Context})
+		msg := result.Message(ID, -1)
+		msg.Text = prepend + msg.Text
+		msgs = append(msgs, msg)
+	}
+	return msgs
+}
+
+// AppendCheckInfos appends the results of a check for the OGD field ID as messages
+// to msgs. resource is the index of the checked resource, -1 if none.
+func AppendCheckInfos(msgs []CheckMessage, checkresults []CheckInfo, ID, resource int) []CheckMessage {
+	for idx := range checkresults {
+		msgs = append(msgs, checkresults[idx].Message(ID, resource))
 	}
 	return msgs
 }
 
+// Loadogdatspec reads the specification of version from the CSV file filename
 func Loadogdatspec(version, filename string) (*OGDSet, error) {
 	reader, err := os.Open(filename)
 	if err != nil {
 		return nil, err
 	}
 	defer reader.Close()
+	return LoadogdatspecReader(version, reader)
+}
 
+// LoadogdatspecReader reads the specification of version in CSV format from reader
+func LoadogdatspecReader(version string, reader io.Reader) (*OGDSet, error) {
 	csvreader := csv.NewReader(reader)
 	csvreader.Comma = '|'
 	csvreader.LazyQuotes = true
@@ -336,6 +415,9 @@ func Loadogdatspec(version, filename string) (*OGDSet, error) {
 
 	spec := make([]*Beschreibung, 0)
 	for record, err = csvreader.Read(); err != io.EOF; record, err = csvreader.Read() {
+		if err != nil {
+			return nil, err
+		}
 		id, _ := strconv.Atoi(record[0])
 		var occ Occurrence
 		switch record[12][0] {
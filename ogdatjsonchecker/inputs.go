@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"github.com/the42/ogdat/dcat"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const stdinsource = "-"
+
+// ckanformat is the input format of CKAN JSON metadata documents, the other
+// input formats are the RDF formats of DCAT-AP.at
+const ckanformat = "ckan"
+
+func informats() []string {
+	formats := []string{ckanformat}
+	for _, format := range dcat.Formats {
+		formats = append(formats, string(format))
+	}
+	return formats
+}
+
+func isinformat(informat string) bool {
+	for _, format := range informats() {
+		if format == informat {
+			return true
+		}
+	}
+	return false
+}
+
+// document is a single, not yet parsed, JSON metadata document
+type document struct {
+	Source string
+	Data   []byte
+	Err    error // the document could not be loaded
+}
+
+func isurl(source string) bool {
+	return strings.Index(strings.TrimSpace(source), "http") == 0
+}
+
+func isjsonlines(filename string) bool {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".jsonl", ".ndjson":
+		return true
+	}
+	return false
+}
+
+func isjsonfile(filename string) bool {
+	return isjsonlines(filename) || strings.ToLower(filepath.Ext(filename)) == ".json"
+}
+
+// isinputfile reports whether a file found in a directory is read as input in informat
+func isinputfile(filename, informat string) bool {
+	if informat == ckanformat {
+		return isjsonfile(filename)
+	}
+	format, err := dcat.FormatForFilename(filename)
+	return err == nil && string(format) == informat
+}
+
+// readsource reads the whole content of source, which is either stdin,
+// a http(s) url or a local file
+func readsource(source string) ([]byte, error) {
+	// 1. if no source is given or source is empty, use stdin
+	if source == stdinsource {
+		return ioutil.ReadAll(os.Stdin)
+	}
+	// 2. if the data is available as http or https
+	if isurl(source) {
+		resp, err := http.Get(source)
+		if err != nil {
+			return nil, fmt.Errorf("Can't fetch from '%s': %s", source, err)
+		}
+		defer resp.Body.Close()
+		return ioutil.ReadAll(resp.Body)
+	}
+	// 3. else try to open it as a file
+	return ioutil.ReadFile(source)
+}
+
+// splitjsonlines splits a JSON-lines stream into documents, one per non-empty line
+func splitjsonlines(source string, data []byte) ([]document, error) {
+	var docs []document
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), len(data)+1)
+	for lineno := 1; scanner.Scan(); lineno++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		docs = append(docs, document{Source: fmt.Sprintf("%s:%d", source, lineno), Data: append([]byte(nil), line...)})
+	}
+	return docs, scanner.Err()
+}
+
+// splitrdf converts every dataset of a DCAT-AP.at RDF document into a document
+func splitrdf(source string, data []byte, format dcat.Format, base string) ([]document, error) {
+	g, err := dcat.Parse(bytes.NewReader(data), format, base)
+	if err != nil {
+		return nil, err
+	}
+	datasets, err := dcat.Import(g)
+	if err != nil {
+		return nil, err
+	}
+	docs := make([]document, len(datasets))
+	for idx, dataset := range datasets {
+		docs[idx] = document{Source: fmt.Sprintf("%s <%s>", source, dataset.Subject), Data: dataset.Data}
+	}
+	return docs, nil
+}
+
+func loadsource(source string, jsonlines bool, informat string) ([]document, error) {
+	data, err := readsource(source)
+	if err != nil {
+		return nil, err
+	}
+	name := source
+	if source == stdinsource {
+		name = "stdin"
+	}
+	if informat != ckanformat {
+		base := ""
+		if isurl(source) {
+			base = strings.TrimSpace(source)
+		}
+		return splitrdf(name, data, dcat.Format(informat), base)
+	}
+	if jsonlines || isjsonlines(source) {
+		return splitjsonlines(name, data)
+	}
+	return []document{{Source: name, Data: data}}, nil
+}
+
+// collectdocuments expands the given sources into the documents to check.
+// A source may be stdin ("-"), a http(s) url, a file, a directory, which is
+// searched recursively for *.json, *.jsonl and *.ndjson files, or a glob pattern.
+// Files with extension .jsonl or .ndjson, or every source if jsonlines is set,
+// are read as JSON-lines streams containing one document per line.
+// If informat is one of the RDF formats, directories are searched for files
+// of that format and every dataset of a source becomes a document.
+// A source which can't be loaded becomes a document carrying the load error,
+// the remaining sources are still collected.
+func collectdocuments(sources []string, jsonlines bool, informat string) []document {
+	var docs []document
+
+	fail := func(source string, err error) {
+		docs = append(docs, document{Source: source, Err: err})
+	}
+	add := func(source string) {
+		d, err := loadsource(source, jsonlines, informat)
+		// keep the documents read before a JSON-lines stream broke off
+		docs = append(docs, d...)
+		if err != nil {
+			fail(source, err)
+		}
+	}
+
+	for _, source := range sources {
+		if source == stdinsource || isurl(source) {
+			add(source)
+			continue
+		}
+
+		matches := []string{source}
+		if strings.ContainsAny(source, "*?[") {
+			var err error
+			if matches, err = filepath.Glob(source); err != nil {
+				fail(source, fmt.Errorf("Invalid pattern '%s': %s", source, err))
+				continue
+			}
+			if len(matches) == 0 {
+				fail(source, fmt.Errorf("Pattern '%s' does not match any file", source))
+				continue
+			}
+		}
+
+		for _, match := range matches {
+			fi, err := os.Stat(match)
+			if err != nil {
+				fail(match, err)
+				continue
+			}
+			if !fi.IsDir() {
+				add(match)
+				continue
+			}
+			filepath.Walk(match, func(path string, info os.FileInfo, err error) error {
+				if err != nil {
+					fail(path, err)
+					return nil
+				}
+				if info.IsDir() || !isinputfile(path, informat) {
+					return nil
+				}
+				add(path)
+				return nil
+			})
+		}
+	}
+	return docs
+}
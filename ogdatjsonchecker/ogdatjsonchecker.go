@@ -5,121 +5,252 @@ import (
 	"flag"
 	"fmt"
 	"github.com/the42/ogdat"
-	"github.com/the42/ogdat/ogdatv21"
-	"github.com/the42/ogdat/ogdatv22"
-	"github.com/the42/ogdat/ogdatv23"
-	"io"
+	"github.com/the42/ogdat/dcat"
+	"github.com/the42/ogdat/iso19139"
+	"github.com/the42/ogdat/schedule"
 	"io/ioutil"
 	"log"
-	"net/http"
 	"os"
+	"runtime"
 	"strings"
 )
 
-var mdsource = flag.String("if", "", "Einzelne, CKAN-compatible, JSON-Beschreibung eines Metadatensatzes. Kann eine lokale Datei sein, oder über http/https bezogen werden. Standard: stdin")
-var of = flag.String("of", "", "Dateiname, unter dem die bezogenen Metadaten 1:1 gespeichert werden sollen.")
+var mdsource = flag.String("if", "", "Einzelne, CKAN-compatible, JSON-Beschreibung eines Metadatensatzes. Kann eine lokale Datei sein, oder über http/https bezogen werden. Weitere Dateien, Verzeichnisse oder Muster können als Argumente angegeben werden. Standard: stdin")
+var of = flag.String("of", "", "Dateiname, unter dem die bezogenen Metadaten 1:1 gespeichert werden sollen. Mit -fix werden die korrigierten Metadaten gespeichert.")
 var ofs = flag.String("ofs", "", "Dateiname, unter dem nur die relevanten OGD-Metadaten des JSON-streams gespeichert werden sollen.")
+var odcat = flag.String("odcat", "", "Dateiname, unter dem die Metadaten als DCAT-AP.at gespeichert werden sollen. Das RDF-Format wird anhand der Dateiendung gewählt: {.ttl|.rdf|.jsonld}")
+var oiso = flag.String("oiso", "", "Dateiname, unter dem die Metadaten als ISO 19139 XML (INSPIRE) gespeichert werden sollen.")
 var followlinks = flag.Bool("follow", false, "Sollen http(s)-Links in den Metadaten auf Verfügbarkeit überprüft werden? Werte: {true|false}, Standard: false")
-var version = flag.String("version", "", "Version, nach der das OGD Metadatendokument überprüft werden soll. Werte: {V20|V21|V22|V23}")
+var version = flag.String("version", autoversion, "Version, nach der das OGD Metadatendokument überprüft werden soll. Bei 'auto' wird die Version anhand des Dokuments ermittelt. Werte: {"+strings.Join(versionflags(), "|")+"}")
+var jsonlines = flag.Bool("jsonl", false, "Eingaben als JSON-lines (ein Metadatendokument pro Zeile) lesen. Dateien mit Endung .jsonl oder .ndjson werden immer so gelesen")
+var informat = flag.String("informat", ckanformat, "Format der Eingaben. Bei RDF nach DCAT-AP.at wird jeder dcat:Dataset als eigenes Metadatendokument überprüft. Werte: {"+strings.Join(informats(), "|")+"}")
+var parallel = flag.Int("parallel", runtime.NumCPU(), "Anzahl parallel überprüfter Metadatendokumente")
+var format = flag.String("format", "text", "Ausgabeformat der Überprüfungsergebnisse. Werte: {text|json|junit|sarif}")
+var lang = flag.String("lang", ogdat.LangDE, "Sprache der Meldungen. Werte: {de|en}")
+var fix = flag.Bool("fix", false, "Automatisch behebbare Abweichungen von der Spezifikation korrigieren und die Änderungen ausgeben. Das korrigierte Dokument wird mit -of gespeichert")
+var rulesfile = flag.String("rules", "", "JSON-Datei mit der Konfiguration der Prüfregeln (aktivieren, deaktivieren, Schweregrad, Optionen)")
 
-var labels = map[int]string{
+var labels = map[ogdat.Status]string{
 	ogdat.Info:    "Info",
 	ogdat.Warning: "Warning",
 	ogdat.Error:   "Error",
 }
 
+// checkdocument parses and checks a single document. If factory is nil,
+// the version is detected from the document
+func checkdocument(doc document, factory *ogdat.MetadataFactory) (result checkresult) {
+	result.Source = doc.Source
+	if doc.Err != nil {
+		result.Err = fmt.Errorf("Can't read metadata: %s", doc.Err)
+		return
+	}
+
+	if *fix {
+		data, changes, err := ogdat.Fix(doc.Data)
+		if err != nil {
+			result.Err = fmt.Errorf("Can't fix metadata: %s", err)
+			return
+		}
+		doc.Data = data
+		result.Changes = changes
+		result.fixed = data
+	}
+
+	if factory == nil {
+		var err error
+		if factory, result.VersionReason, err = ogdat.DetectMetadataVersion(doc.Data); err != nil {
+			result.Err = fmt.Errorf("Can't detect metadata version: %s", err)
+			return
+		}
+		log.Printf("Info: %s: Überprüfung nach %s: %s\n", doc.Source, factory.Version, result.VersionReason)
+	}
+	result.Version = factory.Version
+	result.Set = factory.Spec
+
+	md := factory.New()
+	if err := json.Unmarshal(doc.Data, md); err != nil {
+		result.Err = fmt.Errorf("Can't unmarshall byte stream: %s", err)
+		return
+	}
+	result.metadata = md
+
+	msgs, err := md.Check(*followlinks)
+	if err != nil {
+		log.Printf("%s: Unexpected error from Check: %s", doc.Source, err)
+	}
+	result.Messages = msgs
+	return
+}
+
 func mymain() int {
 	flag.Parse()
-	var reader io.Reader
-	var set *ogdat.OGDSet
-	var md ogdat.Metadater
+	var factory *ogdat.MetadataFactory
 
-	if flag.NFlag() == 0 {
-		fmt.Println("Keine Kommandozeilenparamter angegeben. Verwendung:")
+	if flag.NFlag() == 0 && flag.NArg() == 0 {
+		fmt.Println("Keine Kommandozeilenparamter angegeben. Verwendung: ogdatjsonchecker [Optionen] [Datei|Verzeichnis|Muster ...]")
 		flag.PrintDefaults()
-		return 2
-	}
-
-	switch *version {
-	case "V20", "V21":
-		set = ogdat.GetOGDSetForVersion(ogdatv21.Version)
-		md = &ogdatv21.MetaData{}
-	case "V22":
-		set = ogdat.GetOGDSetForVersion(ogdatv22.Version)
-		md = &ogdatv22.MetaData{}
-	case "V23":
-		set = ogdat.GetOGDSetForVersion(ogdatv23.Version)
-		md = &ogdatv23.MetaData{}
-	default:
-		log.Printf("Nicht unterstützte OGD Version: '%s'\n", *version)
-		return 2
-	}
-
-	// 1. if no source is given or source is empty, use stdin
-	if *mdsource == "" {
-		reader = os.Stdin
-	} else {
-		// 2. if the data is available as http or https
-		if strings.Index(strings.TrimSpace(*mdsource), "http") == 0 {
-			resp, err := http.Get(*mdsource)
-			if err != nil {
-				log.Printf("Can't fetch from '%s': %s\n", *mdsource, err)
-				return 1
-			}
-			defer resp.Body.Close()
-			reader = resp.Body
-		} else {
-			// 3. else try to open it as a file
-			file, err := os.Open(*mdsource)
-			if err != nil {
-				log.Printf("Can't open '%s': %s\n", *mdsource, err)
-				return 1
-			}
-			defer file.Close()
-			reader = file
+		return exitUsage
+	}
+
+	writeoutput, ok := outputwriters[*format]
+	if !ok {
+		log.Printf("Nicht unterstütztes Ausgabeformat: '%s'\n", *format)
+		return exitUsage
+	}
+
+	if *lang != ogdat.LangDE && *lang != ogdat.LangEN {
+		log.Printf("Nicht unterstützte Sprache: '%s'\n", *lang)
+		return exitUsage
+	}
+
+	if !isinformat(*informat) {
+		log.Printf("Nicht unterstütztes Eingabeformat: '%s'\n", *informat)
+		return exitUsage
+	}
+
+	if *rulesfile != "" {
+		cfg, err := ogdat.LoadRuleConfigFile(*rulesfile)
+		if err == nil {
+			err = ogdat.SetRuleConfig(cfg)
+		}
+		if err != nil {
+			log.Println(err)
+			return exitUsage
 		}
 	}
 
-	ogdjsonmd, err := ioutil.ReadAll(reader)
-	if err != nil {
-		log.Printf("Can't read from stream: %s\n", err)
-		return 1
+	if *version != autoversion {
+		var err error
+		if factory, err = getfactory(*version); err != nil {
+			log.Println(err)
+			return exitUsage
+		}
+	}
+
+	if *parallel < 1 {
+		log.Printf("Anzahl paralleler Überprüfungen muss größer 0 sein: %d\n", *parallel)
+		return exitUsage
+	}
+
+	sources := flag.Args()
+	if *mdsource != "" {
+		sources = append([]string{*mdsource}, sources...)
+	}
+	if len(sources) == 0 {
+		sources = []string{stdinsource}
+	}
+
+	docs := collectdocuments(sources, *jsonlines, *informat)
+	if len(docs) == 0 {
+		log.Println("Keine Metadatendokumente gefunden")
+		return exitFailure
+	}
+	if (*of != "" || *ofs != "" || *odcat != "" || *oiso != "") && len(docs) > 1 {
+		log.Printf("-of, -ofs, -odcat und -oiso können nur mit einem einzelnen Metadatendokument verwendet werden, gefunden: %d\n", len(docs))
+		return exitUsage
+	}
+	var dcatformat dcat.Format
+	if *odcat != "" {
+		var err error
+		if dcatformat, err = dcat.FormatForFilename(*odcat); err != nil {
+			log.Println(err)
+			return exitUsage
+		}
+	}
+
+	if *of != "" && !*fix && docs[0].Err == nil {
+		ioutil.WriteFile(*of, docs[0].Data, 0666)
 	}
 
-	if *of != "" {
-		ioutil.WriteFile(*of, ogdjsonmd, 0666)
+	// Make sure the lookup tables used by the checks can be loaded
+	// before checking any document
+	if _, err := ogdat.CheckISOLanguage("ger"); err != nil {
+		log.Println(err)
+		return exitFailure
+	}
+	if _, err := ogdat.CheckIANAEncoding("utf8"); err != nil {
+		log.Println(err)
+		return exitFailure
+	}
+
+	results := make([]checkresult, len(docs))
+	queue := make([]interface{}, len(docs))
+	for idx := range docs {
+		queue[idx] = idx
+	}
+	f := func(slice []interface{}) error {
+		for _, idx := range slice {
+			i := idx.(int)
+			results[i] = checkdocument(docs[i], factory)
+		}
+		return nil
+	}
+	if workreply := <-schedule.New(*parallel).Schedule(f, queue); workreply.Err != nil {
+		log.Printf("Scheduler didn't return success: %s\n", workreply.Err)
+		return exitFailure
 	}
 
-	if err := json.Unmarshal(ogdjsonmd, md); err != nil {
-		log.Printf("Can't unmarshall byte stream: %s\n", err)
-		return 1
+	if *of != "" && *fix && results[0].fixed != nil {
+		ioutil.WriteFile(*of, results[0].fixed, 0666)
 	}
 
-	if *ofs != "" {
-		bytestream, err := json.Marshal(md)
+	if *ofs != "" && results[0].metadata != nil {
+		bytestream, err := json.Marshal(results[0].metadata)
 		if err != nil {
 			log.Printf("Can't serialize to JSON stream: %s\n", err)
 		}
 		ioutil.WriteFile(*ofs, bytestream, 0666)
 	}
 
-	msgs, err := md.Check(*followlinks)
-	if err != nil {
-		log.Printf("Unexpected error from Check: %s", err)
+	if *odcat != "" && results[0].metadata != nil {
+		if err := writedcat(*odcat, dcatformat, results[0].metadata); err != nil {
+			log.Printf("Can't export to DCAT-AP.at: %s\n", err)
+		}
 	}
 
-	if fmsgs := len(msgs); fmsgs > 0 {
-		fmt.Printf("%d Informationspunkte gefunden:\n", fmsgs)
-		for idx, val := range msgs {
-			_, fieldname := set.GetBeschreibungForID(val.OGDID)
-			// val.Type & 7 : filter higher informative flags
-			fmt.Printf("%d: %s:(%d) %s [%d]: %s\n", idx+1, labels[val.Type&7], val.Type, fieldname, val.OGDID, val.Text)
+	if *oiso != "" && results[0].metadata != nil {
+		if err := writeiso(*oiso, results[0].metadata); err != nil {
+			log.Printf("Can't export to ISO 19139: %s\n", err)
 		}
-		return 1
 	}
 
-	fmt.Println("Keine Fehler gefunden")
-	return 0
+	if err := writeoutput(os.Stdout, results); err != nil {
+		log.Printf("Can't write output: %s\n", err)
+		return exitFailure
+	}
+	return exitcode(results)
+}
+
+func writedcat(filename string, format dcat.Format, md ogdat.Metadater) error {
+	g, err := dcat.Export(md, dcat.DefaultBaseURI)
+	if err != nil {
+		return err
+	}
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := g.Write(file, format); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func writeiso(filename string, md ogdat.Metadater) error {
+	doc, err := iso19139.Export(md)
+	if err != nil {
+		return err
+	}
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := doc.Write(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
 }
 
 func main() {
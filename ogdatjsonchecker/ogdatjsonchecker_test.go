@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/the42/ogdat"
+)
+
+func testresults(t *testing.T) []checkresult {
+	factory, err := getfactory("V23")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []checkresult{
+		{Source: "ok.json", Version: factory.Version, Set: factory.Spec},
+		{Source: "messages.json", Version: factory.Version, Set: factory.Spec, Messages: []ogdat.CheckMessage{
+			ogdat.NewCheckMessage(ogdat.Info, 22, ogdat.CodeToponymUnknown, ogdat.ValueParams("Entenhausen")),
+			ogdat.NewCheckMessage(ogdat.Warning, 21, ogdat.CodeLicenseNotURI, ogdat.ValueParams("CC-BY")),
+			ogdat.NewCheckMessage(ogdat.Error|ogdat.FetchableUrl, 14, ogdat.CodeURLStatus, ogdat.ValueParams("http://example.com", "status", "404", "method", "Head").AtResource(1)),
+		}},
+		{Source: "broken.json", Err: fmt.Errorf("Can't read metadata: no such file")},
+	}
+}
+
+var exitcodeTests = []struct {
+	types    []ogdat.Status
+	err      bool
+	exitcode int
+}{
+	{nil, false, exitOK},
+	{[]ogdat.Status{ogdat.Info | ogdat.FetchableUrl}, false, exitInfo},
+	{[]ogdat.Status{ogdat.Info, ogdat.Warning, ogdat.Info}, false, exitWarning},
+	{[]ogdat.Status{ogdat.Warning, ogdat.Error | ogdat.NoDataatUrlError}, false, exitError},
+	{[]ogdat.Status{ogdat.Info}, true, exitFailure},
+}
+
+func TestExitcode(t *testing.T) {
+	for idx, test := range exitcodeTests {
+		result := checkresult{Source: "test.json"}
+		for _, typ := range test.types {
+			result.Messages = append(result.Messages, ogdat.CheckMessage{Type: typ, OGDID: 1})
+		}
+		results := []checkresult{{Source: "ok.json"}, result}
+		if test.err {
+			results = append(results, checkresult{Source: "broken.json", Err: fmt.Errorf("broken")})
+		}
+		if code := exitcode(results); code != test.exitcode {
+			t.Errorf("TestExitcode [%d]: expected %d, got %d", idx, test.exitcode, code)
+		}
+	}
+}
+
+func TestWritetext(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writetext(&buf, testresults(t)); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, expected := range []string{
+		"== ok.json (OGD Austria Metadata 2.3) ==\nKeine Fehler gefunden",
+		"3 Informationspunkte gefunden:",
+		"geographic_toponym [22]: ",
+		"Dokument konnte nicht überprüft werden: Can't read metadata: no such file",
+		"broken.json",
+		"Gesamt (3 Dokumente)",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("TestWritetext: expected '%s' in:\n%s", expected, out)
+		}
+	}
+}
+
+func TestWritejson(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writejson(&buf, testresults(t)); err != nil {
+		t.Fatal(err)
+	}
+	var docs []reportdocument
+	if err := json.Unmarshal(buf.Bytes(), &docs); err != nil {
+		t.Fatalf("TestWritejson: invalid JSON: %s", err)
+	}
+	if len(docs) != 3 {
+		t.Fatalf("TestWritejson: expected 3 documents, got %d", len(docs))
+	}
+	if c := docs[1].Counts; c.Info != 1 || c.Warning != 1 || c.Error != 1 {
+		t.Errorf("TestWritejson: unexpected counts %+v", c)
+	}
+	msg := docs[1].Messages[2]
+	if msg.Severity != "Error" || msg.Code != ogdat.CodeURLStatus || msg.Field != "resource_url" || msg.Resource == nil || *msg.Resource != 1 || msg.Position != nil || msg.Args["status"] != "404" {
+		t.Errorf("TestWritejson: unexpected message %+v", msg)
+	}
+	if docs[2].Error == "" || len(docs[2].Messages) != 0 {
+		t.Errorf("TestWritejson: expected the error of broken.json, got %+v", docs[2])
+	}
+}
+
+func TestWritejunit(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writejunit(&buf, testresults(t)); err != nil {
+		t.Fatal(err)
+	}
+	var suites junittestsuites
+	if err := xml.Unmarshal(buf.Bytes(), &suites); err != nil {
+		t.Fatalf("TestWritejunit: invalid XML: %s", err)
+	}
+	expected := []struct{ tests, failures, errors int }{{1, 0, 0}, {3, 1, 0}, {1, 0, 1}}
+	if len(suites.Testsuites) != len(expected) {
+		t.Fatalf("TestWritejunit: expected %d test suites, got %d", len(expected), len(suites.Testsuites))
+	}
+	for idx, suite := range suites.Testsuites {
+		if suite.Tests != expected[idx].tests || suite.Failures != expected[idx].failures || suite.Errors != expected[idx].errors {
+			t.Errorf("TestWritejunit [%s]: expected %+v, got tests %d, failures %d, errors %d", suite.Name, expected[idx], suite.Tests, suite.Failures, suite.Errors)
+		}
+	}
+	if tc := suites.Testsuites[1].Testcases[2]; tc.Name != "resource_url [14]" || tc.Failure == nil {
+		t.Errorf("TestWritejunit: expected a failure for resource_url, got %+v", tc)
+	}
+}
+
+func TestWritesarif(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writesarif(&buf, testresults(t)); err != nil {
+		t.Fatal(err)
+	}
+	var log sariflog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("TestWritesarif: invalid JSON: %s", err)
+	}
+	if len(log.Runs) != 1 {
+		t.Fatalf("TestWritesarif: expected one run, got %d", len(log.Runs))
+	}
+	run := log.Runs[0]
+	var levels []string
+	for _, result := range run.Results {
+		levels = append(levels, result.Level)
+	}
+	if strings.Join(levels, " ") != "note warning error" {
+		t.Errorf("TestWritesarif: unexpected levels %v", levels)
+	}
+	if len(run.Tool.Driver.Rules) != 3 || run.Tool.Driver.Rules[2].ID != "OGD14" || run.Tool.Driver.Rules[2].Name != "resource_url" {
+		t.Errorf("TestWritesarif: unexpected rules %+v", run.Tool.Driver.Rules)
+	}
+	if run.Results[2].Properties["resource"] != float64(1) {
+		t.Errorf("TestWritesarif: expected resource 1, got %v", run.Results[2].Properties["resource"])
+	}
+	inv := run.Invocations[0]
+	if inv.ExecutionSuccessful || len(inv.ToolExecutionNotifications) != 1 || inv.ToolExecutionNotifications[0].Locations[0].PhysicalLocation.ArtifactLocation.Uri != "broken.json" {
+		t.Errorf("TestWritesarif: expected a notification for broken.json, got %+v", inv)
+	}
+}
+
+func TestCollectdocuments(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ogdatjsonchecker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"a.json":          `{"title": "a"}`,
+		"notes.txt":       "kein Metadatendokument",
+		"sub/b.jsonl":     "{\"title\": \"b1\"}\n\n{\"title\": \"b2\"}\n",
+		"other/c.ndjson":  `{"title": "c"}`,
+		"other/d.json.gz": "",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sources := []string{
+		filepath.Join(dir, "a.json"),
+		filepath.Join(dir, "missing.json"),
+		filepath.Join(dir, "sub"),
+		filepath.Join(dir, "*", "*.ndjson"),
+		filepath.Join(dir, "*.xml"),
+		filepath.Join(dir, "["),
+	}
+	expected := []struct {
+		source string
+		data   string
+		err    bool
+	}{
+		{filepath.Join(dir, "a.json"), `{"title": "a"}`, false},
+		{filepath.Join(dir, "missing.json"), "", true},
+		{filepath.Join(dir, "sub", "b.jsonl") + ":1", `{"title": "b1"}`, false},
+		{filepath.Join(dir, "sub", "b.jsonl") + ":3", `{"title": "b2"}`, false},
+		{filepath.Join(dir, "other", "c.ndjson") + ":1", `{"title": "c"}`, false},
+		{filepath.Join(dir, "*.xml"), "", true},
+		{filepath.Join(dir, "["), "", true},
+	}
+
+	docs := collectdocuments(sources, false, ckanformat)
+	if len(docs) != len(expected) {
+		t.Fatalf("TestCollectdocuments: expected %d documents, got %d: %v", len(expected), len(docs), docs)
+	}
+	for idx, doc := range docs {
+		exp := expected[idx]
+		if doc.Source != exp.source || string(doc.Data) != exp.data || (doc.Err != nil) != exp.err {
+			t.Errorf("TestCollectdocuments [%d]: expected %s '%s' (error: %v), got %s '%s' (%v)", idx, exp.source, exp.data, exp.err, doc.Source, doc.Data, doc.Err)
+		}
+	}
+
+	// a directory is searched for JSON files only
+	docs = collectdocuments([]string{dir}, false, ckanformat)
+	if len(docs) != 4 {
+		t.Errorf("TestCollectdocuments: expected 4 documents in %s, got %d", dir, len(docs))
+	}
+}
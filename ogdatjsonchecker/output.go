@@ -0,0 +1,457 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/the42/ogdat"
+)
+
+// exit codes of ogdatjsonchecker. When messages were found, the exit code
+// reflects the most severe message
+const (
+	exitOK = iota
+	exitFailure
+	exitUsage
+	exitInfo
+	exitWarning
+	exitError
+)
+
+// checkresult holds the outcome of checking a single metadata document
+type checkresult struct {
+	Source        string
+	Version       string
+	VersionReason string // set if the version was detected automatically
+	Set           *ogdat.OGDSet
+	Messages      []ogdat.CheckMessage
+	Changes       []ogdat.Change // made by -fix
+	Err           error          // the document could not be checked
+
+	metadata ogdat.Metadater
+	fixed    []byte // the document corrected by -fix
+}
+
+// counts holds the number of messages per severity
+type counts struct {
+	Info    int `json:"info"`
+	Warning int `json:"warning"`
+	Error   int `json:"error"`
+}
+
+func (c *counts) add(t ogdat.Status) {
+	switch t.Severity() {
+	case ogdat.Error:
+		c.Error++
+	case ogdat.Warning:
+		c.Warning++
+	case ogdat.Info:
+		c.Info++
+	}
+}
+
+func (result *checkresult) counts() (c counts) {
+	for _, msg := range result.Messages {
+		c.add(msg.Type)
+	}
+	return
+}
+
+// exitcode returns exitFailure if any document could not be checked, otherwise
+// the exit code corresponding to the most severe message
+func exitcode(results []checkresult) int {
+	var worst ogdat.Status
+	for _, result := range results {
+		if result.Err != nil {
+			return exitFailure
+		}
+		for _, msg := range result.Messages {
+			if s := msg.Type.Severity(); s > worst {
+				worst = s
+			}
+		}
+	}
+	switch worst {
+	case ogdat.Error:
+		return exitError
+	case ogdat.Warning:
+		return exitWarning
+	case ogdat.Info:
+		return exitInfo
+	}
+	return exitOK
+}
+
+func fieldname(set *ogdat.OGDSet, id int) string {
+	_, name := set.GetBeschreibungForID(id)
+	return name
+}
+
+// messagetext renders msg in the language selected by -lang
+func messagetext(msg ogdat.CheckMessage) string {
+	if *lang == ogdat.LangDE || msg.Code == "" {
+		return msg.Text
+	}
+	text := msg.Render(*lang)
+	if msg.Params.Resource > -1 {
+		text = fmt.Sprintf("R%4d: ", msg.Params.Resource) + text
+	}
+	return text
+}
+
+// optionalindex returns nil for indexes < 0, which denote 'not applicable'
+func optionalindex(idx int) *int {
+	if idx < 0 {
+		return nil
+	}
+	return &idx
+}
+
+type reportmessage struct {
+	Severity string            `json:"severity"`
+	Type     int               `json:"type"`
+	Flags    []string          `json:"flags"`
+	Code     string            `json:"code,omitempty"`
+	OGDID    int               `json:"ogdid"`
+	Field    string            `json:"field,omitempty"`
+	Resource *int              `json:"resource,omitempty"`
+	Position *int              `json:"position,omitempty"`
+	Value    string            `json:"value,omitempty"`
+	Args     map[string]string `json:"args,omitempty"`
+	Text     string            `json:"text"`
+}
+
+type reportdocument struct {
+	Source        string          `json:"source"`
+	Version       string          `json:"version"`
+	VersionReason string          `json:"versionreason,omitempty"`
+	Error         string          `json:"error,omitempty"`
+	Counts        counts          `json:"counts"`
+	Messages      []reportmessage `json:"messages"`
+	Changes       []ogdat.Change  `json:"changes,omitempty"`
+}
+
+func newreportdocument(result checkresult) reportdocument {
+	doc := reportdocument{Source: result.Source, Version: result.Version, VersionReason: result.VersionReason, Counts: result.counts(), Messages: []reportmessage{}, Changes: result.Changes}
+	if result.Err != nil {
+		doc.Error = result.Err.Error()
+	}
+	for _, msg := range result.Messages {
+		rmsg := reportmessage{
+			Severity: labels[msg.Type.Severity()],
+			Type:     int(msg.Type),
+			Flags:    msg.Type.Flags(),
+			Code:     msg.Code,
+			OGDID:    msg.OGDID,
+			Field:    fieldname(result.Set, msg.OGDID),
+			Text:     messagetext(msg)}
+		if msg.Code != "" {
+			rmsg.Resource = optionalindex(msg.Params.Resource)
+			rmsg.Position = optionalindex(msg.Params.Position)
+			rmsg.Value = msg.Params.Value
+			rmsg.Args = msg.Params.Args
+		}
+		doc.Messages = append(doc.Messages, rmsg)
+	}
+	return doc
+}
+
+type outputwriter func(io.Writer, []checkresult) error
+
+var outputwriters = map[string]outputwriter{
+	"text":  writetext,
+	"json":  writejson,
+	"junit": writejunit,
+	"sarif": writesarif,
+}
+
+func writetext(w io.Writer, results []checkresult) error {
+	for _, result := range results {
+		if len(results) > 1 {
+			fmt.Fprintf(w, "== %s (%s) ==\n", result.Source, result.Version)
+		}
+		if len(result.Changes) > 0 {
+			fmt.Fprintf(w, "%d Änderungen vorgenommen:\n", len(result.Changes))
+			for idx, change := range result.Changes {
+				fmt.Fprintf(w, "%d: %s\n", idx+1, change)
+			}
+		}
+		if result.Err != nil {
+			fmt.Fprintf(w, "Dokument konnte nicht überprüft werden: %s\n", result.Err)
+		} else if fmsgs := len(result.Messages); fmsgs > 0 {
+			fmt.Fprintf(w, "%d Informationspunkte gefunden:\n", fmsgs)
+			for idx, val := range result.Messages {
+				// filter higher informative flags
+				fmt.Fprintf(w, "%d: %s:(%d) %s [%d]: %s\n", idx+1, labels[val.Type&ogdat.SeverityMask], val.Type, fieldname(result.Set, val.OGDID), val.OGDID, messagetext(val))
+			}
+		} else {
+			fmt.Fprintln(w, "Keine Fehler gefunden")
+		}
+	}
+	if len(results) > 1 {
+		return writesummary(w, results)
+	}
+	return nil
+}
+
+type idcounts struct {
+	id    int
+	field string
+	counts
+}
+
+// writesummary prints the number of messages by severity for every document,
+// followed by the number of messages by OGDID and severity over all documents
+func writesummary(w io.Writer, results []checkresult) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+
+	fmt.Fprintln(w, "\nZusammenfassung je Dokument:")
+	fmt.Fprintln(tw, "Dokument\tVersion\tInfo\tWarning\tError\t")
+	var total counts
+	byid := make(map[int]*idcounts)
+	for _, result := range results {
+		if result.Err != nil {
+			fmt.Fprintf(tw, "%s\t%s\t-\t-\t-\t\n", result.Source, "nicht überprüfbar")
+			continue
+		}
+		c := result.counts()
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\t\n", result.Source, result.Version, c.Info, c.Warning, c.Error)
+		for _, msg := range result.Messages {
+			idc, ok := byid[msg.OGDID]
+			if !ok {
+				idc = &idcounts{id: msg.OGDID}
+				byid[msg.OGDID] = idc
+			}
+			if idc.field == "" {
+				idc.field = fieldname(result.Set, msg.OGDID)
+			}
+			idc.add(msg.Type)
+			total.add(msg.Type)
+		}
+	}
+	fmt.Fprintf(tw, "Gesamt (%d Dokumente)\t\t%d\t%d\t%d\t\n", len(results), total.Info, total.Warning, total.Error)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	ids := make([]int, 0, len(byid))
+	for id := range byid {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	fmt.Fprintln(w, "\nMeldungen je OGD-ID:")
+	fmt.Fprintln(tw, "OGDID\tFeld\tInfo\tWarning\tError\t")
+	for _, id := range ids {
+		idc := byid[id]
+		fmt.Fprintf(tw, "%d\t%s\t%d\t%d\t%d\t\n", idc.id, idc.field, idc.Info, idc.Warning, idc.Error)
+	}
+	return tw.Flush()
+}
+
+func writejson(w io.Writer, results []checkresult) error {
+	var docs []reportdocument
+	for _, result := range results {
+		docs = append(docs, newreportdocument(result))
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(docs)
+}
+
+// JUnit XML, as understood by most CI systems. Every message becomes a test case,
+// errors are reported as failures, infos and warnings as system-out
+type junitfailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junittestcase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Failure   *junitfailure `xml:"failure,omitempty"`
+	Error     *junitfailure `xml:"error,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junittestsuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Testcases []junittestcase `xml:"testcase"`
+}
+
+type junittestsuites struct {
+	XMLName    xml.Name         `xml:"testsuites"`
+	Testsuites []junittestsuite `xml:"testsuite"`
+}
+
+func writejunit(w io.Writer, results []checkresult) error {
+	suites := junittestsuites{}
+	for _, result := range results {
+		suite := junittestsuite{Name: result.Source}
+		if result.Err != nil {
+			suite.Testcases = append(suite.Testcases, junittestcase{Name: "metadata", Classname: result.Source,
+				Error: &junitfailure{Message: result.Err.Error(), Type: "Error"}})
+			suite.Errors++
+		}
+		doc := newreportdocument(result)
+		for _, msg := range doc.Messages {
+			field := msg.Field
+			if field == "" {
+				field = "metadata"
+			}
+			tc := junittestcase{Name: fmt.Sprintf("%s [%d]", field, msg.OGDID), Classname: result.Source}
+			if msg.Severity == labels[ogdat.Error] {
+				tc.Failure = &junitfailure{Message: msg.Text, Type: msg.Severity, Text: fmt.Sprintf("%s: %v", msg.Severity, msg.Flags)}
+				suite.Failures++
+			} else {
+				tc.SystemOut = fmt.Sprintf("%s %v: %s", msg.Severity, msg.Flags, msg.Text)
+			}
+			suite.Testcases = append(suite.Testcases, tc)
+		}
+		if len(suite.Testcases) == 0 {
+			suite.Testcases = append(suite.Testcases, junittestcase{Name: "metadata", Classname: result.Source})
+		}
+		suite.Tests = len(suite.Testcases)
+		suites.Testsuites = append(suites.Testsuites, suite)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// SARIF 2.1.0, cf. https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+type sarifmessage struct {
+	Text string `json:"text"`
+}
+
+type sarifrule struct {
+	ID               string        `json:"id"`
+	Name             string        `json:"name,omitempty"`
+	ShortDescription *sarifmessage `json:"shortDescription,omitempty"`
+}
+
+type sarifdriver struct {
+	Name           string      `json:"name"`
+	InformationUri string      `json:"informationUri"`
+	Rules          []sarifrule `json:"rules"`
+}
+
+type sariftool struct {
+	Driver sarifdriver `json:"driver"`
+}
+
+type sariflocation struct {
+	PhysicalLocation struct {
+		ArtifactLocation struct {
+			Uri string `json:"uri"`
+		} `json:"artifactLocation"`
+	} `json:"physicalLocation"`
+}
+
+type sarifresult struct {
+	RuleID     string                 `json:"ruleId"`
+	Level      string                 `json:"level"`
+	Message    sarifmessage           `json:"message"`
+	Locations  []sariflocation        `json:"locations"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+}
+
+type sarifnotification struct {
+	Level     string          `json:"level"`
+	Message   sarifmessage    `json:"message"`
+	Locations []sariflocation `json:"locations"`
+}
+
+type sarifinvocation struct {
+	ExecutionSuccessful        bool                `json:"executionSuccessful"`
+	ToolExecutionNotifications []sarifnotification `json:"toolExecutionNotifications,omitempty"`
+}
+
+type sarifrun struct {
+	Tool        sariftool         `json:"tool"`
+	Invocations []sarifinvocation `json:"invocations"`
+	Results     []sarifresult     `json:"results"`
+}
+
+type sariflog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifrun `json:"runs"`
+}
+
+// SARIF levels by severity, messages without severity are "none"
+var sariflevels = map[ogdat.Status]string{
+	0:             "none",
+	ogdat.Info:    "note",
+	ogdat.Warning: "warning",
+	ogdat.Error:   "error",
+}
+
+func writesarif(w io.Writer, results []checkresult) error {
+	run := sarifrun{Tool: sariftool{Driver: sarifdriver{Name: "ogdatjsonchecker", InformationUri: "https://github.com/the42/ogdat", Rules: []sarifrule{}}},
+		Invocations: []sarifinvocation{{ExecutionSuccessful: true}},
+		Results:     []sarifresult{}}
+	knownrules := make(map[string]bool)
+
+	for _, result := range results {
+		if result.Err != nil {
+			var loc sariflocation
+			loc.PhysicalLocation.ArtifactLocation.Uri = result.Source
+			run.Invocations[0].ExecutionSuccessful = false
+			run.Invocations[0].ToolExecutionNotifications = append(run.Invocations[0].ToolExecutionNotifications,
+				sarifnotification{Level: "error", Message: sarifmessage{Text: result.Err.Error()}, Locations: []sariflocation{loc}})
+		}
+		for _, msg := range result.Messages {
+			ruleid := fmt.Sprintf("OGD%d", msg.OGDID)
+			if msg.OGDID < 0 {
+				ruleid = "OGD"
+			}
+			if !knownrules[ruleid] {
+				rule := sarifrule{ID: ruleid}
+				if desc, name := result.Set.GetBeschreibungForID(msg.OGDID); desc != nil {
+					rule.Name = name
+					rule.ShortDescription = &sarifmessage{Text: desc.Bezeichner}
+				}
+				run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, rule)
+				knownrules[ruleid] = true
+			}
+
+			var loc sariflocation
+			loc.PhysicalLocation.ArtifactLocation.Uri = result.Source
+			run.Results = append(run.Results, sarifresult{
+				RuleID:    ruleid,
+				Level:     sariflevels[msg.Type.Severity()],
+				Message:   sarifmessage{Text: messagetext(msg)},
+				Locations: []sariflocation{loc},
+				Properties: map[string]interface{}{
+					"code":    msg.Code,
+					"type":    int(msg.Type),
+					"flags":   msg.Type.Flags(),
+					"field":   fieldname(result.Set, msg.OGDID),
+					"version": result.Version}})
+			if msg.Code != "" && msg.Params.Resource > -1 {
+				run.Results[len(run.Results)-1].Properties["resource"] = msg.Params.Resource
+			}
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sariflog{Version: "2.1.0", Schema: "https://json.schemastore.org/sarif-2.1.0.json", Runs: []sarifrun{run}})
+}
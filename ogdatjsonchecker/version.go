@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/the42/ogdat"
+	// register the supported versions of the specification
+	_ "github.com/the42/ogdat/ogdatv21"
+	_ "github.com/the42/ogdat/ogdatv22"
+	_ "github.com/the42/ogdat/ogdatv23"
+)
+
+const autoversion = "auto"
+
+// versionflag turns a version number as "2.3" into the identifier "V23" as accepted by -version
+func versionflag(alias string) string {
+	return "V" + strings.Replace(alias, ".", "", -1)
+}
+
+// getfactory returns the factory for the -version identifier flag
+func getfactory(flag string) (*ogdat.MetadataFactory, error) {
+	for _, f := range ogdat.MetadataFactories() {
+		for _, alias := range f.Aliases {
+			if versionflag(alias) == flag {
+				return f, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("Nicht unterstützte OGD Version: '%s'", flag)
+}
+
+// versionflags returns all identifiers accepted by -version
+func versionflags() (flags []string) {
+	flags = append(flags, autoversion)
+	for _, f := range ogdat.MetadataFactories() {
+		for _, alias := range f.Aliases {
+			if ogdat.OGDVersionfromString(alias) == alias {
+				flags = append(flags, versionflag(alias))
+			}
+		}
+	}
+	return
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/the42/ogdat"
+	"io/ioutil"
+	"log"
+	"os"
+
+	// register the supported versions of the specification
+	_ "github.com/the42/ogdat/ogdatv21"
+	_ "github.com/the42/ogdat/ogdatv22"
+	_ "github.com/the42/ogdat/ogdatv23"
+)
+
+// exit codes of ogdatmigrate, as those of ogdatjsonchecker
+const (
+	exitOK = iota
+	exitFailure
+	exitUsage
+	exitInfo
+	exitWarning
+	exitError
+)
+
+var inputfile = flag.String("if", "", "CKAN-compatible JSON-Beschreibung eines Metadatensatzes (Standard: stdin)")
+var outputfile = flag.String("of", "", "Dateiname, unter dem die migrierten Metadaten gespeichert werden (Standard: stdout)")
+var from = flag.String("from", "", "Version der Spezifikation, nach der das Dokument vorliegt, z.B. 2.1. Standard: anhand des Dokuments ermitteln")
+var to = flag.String("to", "", "Version der Spezifikation, in die das Dokument migriert werden soll, z.B. 2.3")
+var format = flag.String("format", "text", "Ausgabeformat des Migrationsberichts (stderr). Werte: {text|json}")
+
+func writetext(m *ogdat.Migration) {
+	fmt.Fprintf(os.Stderr, "Migration von %s nach %s\n", m.From, m.To)
+	if len(m.Changes) > 0 {
+		fmt.Fprintf(os.Stderr, "%d Änderungen vorgenommen:\n", len(m.Changes))
+		for idx, change := range m.Changes {
+			fmt.Fprintf(os.Stderr, "%d: %s\n", idx+1, change)
+		}
+	}
+	if len(m.Issues) > 0 {
+		fmt.Fprintf(os.Stderr, "%d Felder nicht automatisch migriert:\n", len(m.Issues))
+		for idx, issue := range m.Issues {
+			fmt.Fprintf(os.Stderr, "%d: %s\n", idx+1, issue)
+		}
+	}
+	if len(m.Messages) > 0 {
+		fmt.Fprintf(os.Stderr, "%d Informationspunkte bei der Überprüfung nach %s gefunden:\n", len(m.Messages), m.To)
+		for idx, msg := range m.Messages {
+			fmt.Fprintf(os.Stderr, "%d: %s:(%d) [%d]: %s\n", idx+1, msg.Type.Severity(), msg.Type, msg.OGDID, msg.Text)
+		}
+	} else {
+		fmt.Fprintf(os.Stderr, "Keine Fehler bei der Überprüfung nach %s gefunden\n", m.To)
+	}
+}
+
+func writejson(m *ogdat.Migration) error {
+	report := struct {
+		*ogdat.Migration
+		Messages []ogdat.CheckMessage `json:"messages"`
+	}{m, m.Messages}
+	enc := json.NewEncoder(os.Stderr)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+// exitcode returns the exit code corresponding to the most severe message
+func exitcode(messages []ogdat.CheckMessage) int {
+	var worst ogdat.Status
+	for _, msg := range messages {
+		if s := msg.Type.Severity(); s > worst {
+			worst = s
+		}
+	}
+	switch worst {
+	case ogdat.Error:
+		return exitError
+	case ogdat.Warning:
+		return exitWarning
+	case ogdat.Info:
+		return exitInfo
+	}
+	return exitOK
+}
+
+func mymain() int {
+	flag.Parse()
+
+	if *to == "" {
+		fmt.Println("Keine Zielversion angegeben. Verwendung: ogdatmigrate -to Version [Optionen]")
+		flag.PrintDefaults()
+		return exitUsage
+	}
+	if *format != "text" && *format != "json" {
+		log.Printf("Nicht unterstütztes Ausgabeformat: '%s'\n", *format)
+		return exitUsage
+	}
+
+	var data []byte
+	var err error
+	if *inputfile == "" {
+		data, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		data, err = ioutil.ReadFile(*inputfile)
+	}
+	if err != nil {
+		log.Printf("Can't read metadata: %s\n", err)
+		return exitFailure
+	}
+
+	m, err := ogdat.Migrate(data, *from, *to)
+	if err != nil {
+		if _, ok := err.(*ogdat.UnknownVersionError); ok {
+			log.Println(err)
+			return exitUsage
+		}
+		log.Printf("Migration nicht möglich: %s\n", err)
+		return exitFailure
+	}
+
+	if *outputfile == "" {
+		os.Stdout.Write(m.Data)
+	} else if err := ioutil.WriteFile(*outputfile, m.Data, 0666); err != nil {
+		log.Printf("Can't write migrated metadata: %s\n", err)
+		return exitFailure
+	}
+
+	if *format == "json" {
+		if err := writejson(m); err != nil {
+			log.Printf("Can't write output: %s\n", err)
+			return exitFailure
+		}
+	} else {
+		writetext(m)
+	}
+	return exitcode(m.Messages)
+}
+
+func main() {
+	os.Exit(mymain())
+}
@@ -1,7 +1,9 @@
 package ogdatv21
 
 import (
+	"embed"
 	"github.com/the42/ogdat"
+	"github.com/the42/ogdat/geometry"
 	"reflect"
 )
 
@@ -11,20 +13,20 @@ const specfile = "ogdat_spec-2.1.csv"
 
 type Extras struct {
 	// Core
-	Metadata_Identifier *ogdat.Identifier        `json:"metadata_identifier" ogdat:"ID:1"` // CKAN uses since API Version 2 a UUID V4, cf. https://github.com/okfn/ckan/blob/master/ckan/model/types.py
-	Metadata_Modified   *ogdat.Time              `json:"metadata_modified" ogdat:"ID:5"`
-	Categorization      *ogdat.MetaDataKategorie `json:"categorization" ogdat:"ID:10"`
-	Begin_DateTime      *ogdat.Time              `json:"begin_datetime" ogdat:"ID:24"`
+	Metadata_Identifier *ogdat.Identifier        `json:"metadata_identifier,omitempty" ogdat:"ID:1"` // CKAN uses since API Version 2 a UUID V4, cf. https://github.com/okfn/ckan/blob/master/ckan/model/types.py
+	Metadata_Modified   *ogdat.Time              `json:"metadata_modified,omitempty" ogdat:"ID:5"`
+	Categorization      *ogdat.MetaDataKategorie `json:"categorization,omitempty" ogdat:"ID:10"`
+	Begin_DateTime      *ogdat.Time              `json:"begin_datetime,omitempty" ogdat:"ID:24"`
 
 	// Optional
-	Schema_Name           *string                `json:"schema_name" ogdat:"ID:2"`
-	Schema_Language       *string                `json:"schema_language" ogdat:"ID:3"`     // always "ger"
-	Schema_Characterset   *string                `json:"schema_characterset" ogdat:"ID:4"` // always "utf8", cf. https://www.ghrsst.org/files/download.php?m=documents&f=ISO%2019115%20.pdf
-	Metadata_Linkage      *ogdat.MetaDataLinkage `json:"metadata_linkage" ogdat:"ID:6"`
-	Attribute_Description *string                `json:"attribute_description" ogdat:"ID:12"`
-	Maintainer_Link       *ogdat.Url             `json:"maintainer_link" ogdat:"ID:13"`
-	Publisher             *string                `json:"publisher" ogdat:"ID:20"`
-	Geographich_Toponym   *string                `json:"geographic_toponym" ogdat:"ID:22"`
+	Schema_Name           *string                `json:"schema_name,omitempty" ogdat:"ID:2"`
+	Schema_Language       *string                `json:"schema_language,omitempty" ogdat:"ID:3"`     // always "ger"
+	Schema_Characterset   *string                `json:"schema_characterset,omitempty" ogdat:"ID:4"` // always "utf8", cf. https://www.ghrsst.org/files/download.php?m=documents&f=ISO%2019115%20.pdf
+	Metadata_Linkage      *ogdat.MetaDataLinkage `json:"metadata_linkage,omitempty" ogdat:"ID:6"`
+	Attribute_Description *string                `json:"attribute_description,omitempty" ogdat:"ID:12"`
+	Maintainer_Link       *ogdat.Url             `json:"maintainer_link,omitempty" ogdat:"ID:13"`
+	Publisher             *string                `json:"publisher,omitempty" ogdat:"ID:20"`
+	Geographich_Toponym   *string                `json:"geographic_toponym,omitempty" ogdat:"ID:22"`
 
 	/*  ON/EN/ISO 19115:2003: westBL (344) & eastBL (345) & southBL (346) & northBL (347)
 	 * Specifiaction says a WKT of POLYGON should be used, which would make a
@@ -32,23 +34,23 @@ type Extras struct {
 	 * POLYGON (-180.00 -90.00, 180.00 90.00)
 	 * The situation is currently erroneous but unambigous, so we support both formats
 	 */
-	Geographic_BBox  *string      `json:"geographic_bbox" ogdat:"ID:23"`
-	End_DateTime     *ogdat.Time  `json:"end_datetime" ogdat:"ID:25"`
-	Update_Frequency *ogdat.Cycle `json:"update_frequency" ogdat:"ID:26"`
-	Lineage_Quality  *string      `json:"lineage_quality" ogdat:"ID:27"`
-	EnTitleDesc      *string      `json:"en_title_and_desc" ogdat:"ID:28"`
-	License_Citation *string      `json:"license_citation" ogdat:"ID:30"`
+	Geographic_BBox  *string      `json:"geographic_bbox,omitempty" ogdat:"ID:23"`
+	End_DateTime     *ogdat.Time  `json:"end_datetime,omitempty" ogdat:"ID:25"`
+	Update_Frequency *ogdat.Cycle `json:"update_frequency,omitempty" ogdat:"ID:26"`
+	Lineage_Quality  *string      `json:"lineage_quality,omitempty" ogdat:"ID:27"`
+	EnTitleDesc      *string      `json:"en_title_and_desc,omitempty" ogdat:"ID:28"`
+	License_Citation *string      `json:"license_citation,omitempty" ogdat:"ID:30"`
 }
 
 type Resource struct {
 	// Core
-	Url    *ogdat.Url               `json:"url" ogdat:"ID:14"`
-	Format *ogdat.ResourceSpecifier `json:"format" ogdat:"ID:15"`
+	Url    *ogdat.Url               `json:"url,omitempty" ogdat:"ID:14"`
+	Format *ogdat.ResourceSpecifier `json:"format,omitempty" ogdat:"ID:15"`
 
 	// Optional
-	Name         *string     `json:"name" ogdat:"ID:16"`
-	Created      *ogdat.Time `json:"created" ogdat:"ID:17"`
-	LastModified *ogdat.Time `json:"last_modified" ogdat:"ID:18"`
+	Name         *string     `json:"name,omitempty" ogdat:"ID:16"`
+	Created      *ogdat.Time `json:"created,omitempty" ogdat:"ID:17"`
+	LastModified *ogdat.Time `json:"last_modified,omitempty" ogdat:"ID:18"`
 
 	/*
 	 * dcat:bytes a rdf:Property, owl:DatatypeProperty;
@@ -58,27 +60,27 @@ type Resource struct {
 	 * rdfs:domain dcat:Distribution;
 	 * rdfs:range xsd:integer .
 	 */
-	Size     *string `json:"size" ogdat:"ID:29"`
-	Language *string `json:"language" ogdat:"ID:31"`
+	Size     *string `json:"size,omitempty" ogdat:"ID:29"`
+	Language *string `json:"language,omitempty" ogdat:"ID:31"`
 	/* Here we have a problem in spec 2.1. which says "nach ISO\IEC 10646-1", which means utf-8, utf-16 and utf-32.
 	 * We would certainly support more encodings, as eg.
 	 * ISO 19115 / B.5.10 MD_CharacterSetCode<> or
 	 * http://www.iana.org/assignments/character-sets/character-sets.xml
 	 */
-	Encoding *string `json:"characterset" ogdat:"ID:32"`
+	Encoding *string `json:"characterset,omitempty" ogdat:"ID:32"`
 }
 
 type MetaData struct {
 	// Core
-	Title       *string      `json:"title" ogdat:"ID:8"`
-	Description *string      `json:"notes" ogdat:"ID:9"`
-	Schlagworte []ogdat.Tags `json:"tags" ogdat:"ID:11"`
-	Maintainer  *string      `json:"maintainer" ogdat:"ID:19"`
-	License     *string      `json:"license" ogdat:"ID:21"` // Sollte URI des Lizenzdokuments sein
+	Title       *string      `json:"title,omitempty" ogdat:"ID:8"`
+	Description *string      `json:"notes,omitempty" ogdat:"ID:9"`
+	Schlagworte []ogdat.Tags `json:"tags,omitempty" ogdat:"ID:11"`
+	Maintainer  *string      `json:"maintainer,omitempty" ogdat:"ID:19"`
+	License     *string      `json:"license,omitempty" ogdat:"ID:21"` // Sollte URI des Lizenzdokuments sein
 
 	// nested structs
 	Extras   `json:"extras"`
-	Resource []Resource `json:"resources"`
+	Resource []Resource `json:"resources,omitempty"`
 }
 
 func (md *MetaData) GetBeschreibungForFieldName(name string) *ogdat.Beschreibung {
@@ -93,11 +95,12 @@ func (md *MetaData) GetBeschreibungForFieldName(name string) *ogdat.Beschreibung
 
 func (md *MetaData) MinimalMetadata() *ogdat.MinimalMetaData {
 
-	minimd := &ogdat.MinimalMetaData{Description: md.Description,
+	minimd := &ogdat.MinimalMetaData{Description: md.Description, License: md.License,
 		Extras: ogdat.Extras{Schema_Name: md.Schema_Name,
 			Publisher:           md.Publisher,
 			Geographic_BBox:     md.Geographic_BBox,
-			Geographich_Toponym: md.Geographich_Toponym}}
+			Geographich_Toponym: md.Geographich_Toponym,
+			License_Citation:    md.License_Citation}}
 
 	if md.Metadata_Identifier != nil {
 		s := md.Metadata_Identifier.String()
@@ -109,9 +112,42 @@ func (md *MetaData) MinimalMetadata() *ogdat.MinimalMetaData {
 	}
 
 	minimd.Categorization = md.Categorization
+	minimd.BBox = md.BBox()
+	for _, res := range md.Resource {
+		var minires ogdat.MinimalResource
+		if res.Url != nil {
+			minires.URL = &res.Url.Raw
+		}
+		if res.Format != nil {
+			s := string(*res.Format)
+			minires.Format = &s
+		}
+		minires.Size = res.Size
+		minires.LastModified = res.LastModified
+		minimd.Resources = append(minimd.Resources, minires)
+	}
 	return minimd
 }
 
+// BBox returns the bounding box of the geographic extent, nil if not given or invalid
+func (md *MetaData) BBox() *geometry.BBox {
+	return ogdat.GeographicBBox(md.Geographic_BBox)
+}
+
+//go:embed ogdat_spec-2.1.csv
+var specfs embed.FS
+
 func init() {
-	ogdat.RegisterFromCSVFile(Version, specfile)
+	aliases := []string{Version20, "2.0", "2.1"}
+	set, err := ogdat.RegisterFromFS(Version, specfs, specfile)
+	if err != nil {
+		ogdat.RegisterMetadataFactoryError(Version, aliases, err)
+		return
+	}
+	ogdat.RegisterMetadataFactory(&ogdat.MetadataFactory{
+		Version: Version,
+		Aliases: aliases,
+		Parse:   parse,
+		New:     func() ogdat.Metadater { return &MetaData{} },
+		Spec:    set})
 }
@@ -3,6 +3,7 @@ package ogdatv22
 import (
 	"encoding/json"
 	"github.com/the42/ogdat"
+	"github.com/the42/ogdat/internal/testutil"
 	"io/ioutil"
 	"os"
 	"path"
@@ -31,46 +32,46 @@ var checkTests = []checkTest{
 	},
 	{ // POLYGON may be specified with two (like the spec) or with one enclosing pair of brackets. Here test if one is ok
 		&checkRequest{"file23a.json", false},
-		&checkResponse{message: []ogdat.CheckMessage{{Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 6},
-			{Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 13}}},
+		&checkResponse{message: []ogdat.CheckMessage{{Type: ogdat.Warning, OGDID: 21}, {Type: ogdat.Info, OGDID: 2}, {Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 6},
+			{Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 13}, {Type: ogdat.Info, OGDID: 22}}},
 	},
 	{ // POLYGON may only be specified in all caps
 		&checkRequest{"file23b.json", false},
-		&checkResponse{message: []ogdat.CheckMessage{{Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 6},
-			{Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 13}, {Type: ogdat.Error, OGDID: 23}}},
+		&checkResponse{message: []ogdat.CheckMessage{{Type: ogdat.Warning, OGDID: 21}, {Type: ogdat.Info, OGDID: 2}, {Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 6},
+			{Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 13}, {Type: ogdat.Info, OGDID: 22}, {Type: ogdat.Error, OGDID: 23}}},
 	},
 	{ // Begin and end point of polygon must match (closed polygon)
 		&checkRequest{"file23c.json", false},
-		&checkResponse{message: []ogdat.CheckMessage{{Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 6},
-			{Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 13}, {Type: ogdat.Error, OGDID: 23}}},
+		&checkResponse{message: []ogdat.CheckMessage{{Type: ogdat.Warning, OGDID: 21}, {Type: ogdat.Info, OGDID: 2}, {Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 6},
+			{Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 13}, {Type: ogdat.Info, OGDID: 22}, {Type: ogdat.Error, OGDID: 23}}},
 	},
 	{ // metadata_original_portal must start with urn://data., this one does not
 		&checkRequest{"file33a.json", false},
-		&checkResponse{message: []ogdat.CheckMessage{{Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 6},
-			{Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 13},
+		&checkResponse{message: []ogdat.CheckMessage{{Type: ogdat.Warning, OGDID: 21}, {Type: ogdat.Info, OGDID: 2}, {Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 6},
+			{Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 13}, {Type: ogdat.Info, OGDID: 22},
 			{Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 33}, {Type: ogdat.Warning, OGDID: 33}}},
 	},
 	{ // metadata_original_portal must start with urn://data., this one does
 		&checkRequest{"file33b.json", false},
-		&checkResponse{message: []ogdat.CheckMessage{{Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 6},
-			{Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 13},
+		&checkResponse{message: []ogdat.CheckMessage{{Type: ogdat.Warning, OGDID: 21}, {Type: ogdat.Info, OGDID: 2}, {Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 6},
+			{Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 13}, {Type: ogdat.Info, OGDID: 22},
 			{Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 33}}},
 	},
 	{ // maintainer_email must be a valid email address, this one is not
 		&checkRequest{"file34a.json", false},
-		&checkResponse{message: []ogdat.CheckMessage{{Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 6},
-			{Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 13},
+		&checkResponse{message: []ogdat.CheckMessage{{Type: ogdat.Warning, OGDID: 21}, {Type: ogdat.Info, OGDID: 2}, {Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 6},
+			{Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 13}, {Type: ogdat.Info, OGDID: 22},
 			{Type: ogdat.Warning, OGDID: 34}}},
 	},
 	{ // maintainer_email must be a valid email address, this one is
 		&checkRequest{"file34b.json", false},
-		&checkResponse{message: []ogdat.CheckMessage{{Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 6},
-			{Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 13}}},
+		&checkResponse{message: []ogdat.CheckMessage{{Type: ogdat.Warning, OGDID: 21}, {Type: ogdat.Info, OGDID: 2}, {Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 6},
+			{Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 13}, {Type: ogdat.Info, OGDID: 22}}},
 	},
 	{ // This test is to check a metadata file in which every entry is OK
 		&checkRequest{"fullandok.json", false},
-		&checkResponse{message: []ogdat.CheckMessage{{Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 6},
-			{Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 13}}},
+		&checkResponse{message: []ogdat.CheckMessage{{Type: ogdat.Warning, OGDID: 21}, {Type: ogdat.Info, OGDID: 2}, {Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 6},
+			{Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 13}, {Type: ogdat.Info, OGDID: 22}}},
 	},
 }
 
@@ -111,3 +112,8 @@ func TestCheck(t *testing.T) {
 		}
 	}
 }
+
+// TestRoundTrip checks that marshalling the unmarshalled test files loses nothing
+func TestRoundTrip(t *testing.T) {
+	testutil.RoundTrip(t, "./testfiles/*.json", func() interface{} { return &MetaData{} })
+}
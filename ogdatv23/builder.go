@@ -0,0 +1,239 @@
+package ogdatv23
+
+import (
+	"fmt"
+	"github.com/the42/ogdat"
+	"strconv"
+	"time"
+)
+
+// Builder creates a metadata document conforming to this version of the
+// specification. Fields are set by chaining the setters, the document is
+// checked and returned by Build:
+//
+//	md, msgs, err := ogdatv23.NewMetaData().
+//		Title("Bevölkerung nach Bezirken").
+//		Description("Bevölkerungsstand der Bezirke zum Jahresbeginn").
+//		Categories(ogdat.Bevoelkerung).
+//		Keywords("Bevölkerung", "Bezirke").
+//		Maintainer("Statistik").
+//		Publisher("Stadt Wien").
+//		License("CC-BY-3.0").
+//		Begin(time.Date(2002, 1, 1, 0, 0, 0, 0, time.UTC)).
+//		AddResource(ogdatv23.NewResource("http://example.com/bev.csv", "csv")).
+//		Build()
+type Builder struct {
+	md *MetaData
+}
+
+// NewMetaData starts a new metadata document with a random UUID as
+// metadata_identifier, today as metadata_modified and the schema fields
+// set as required by this version of the specification
+func NewMetaData() *Builder {
+	schemaname, language, characterset := Version, "ger", "utf8"
+	md := &MetaData{}
+	md.Metadata_Identifier = ogdat.NewIdentifier()
+	md.Metadata_Modified = ogdat.NewTime(time.Now(), ogdat.CustomTimeSpecifier2)
+	md.Schema_Name = &schemaname
+	md.Schema_Language = &language
+	md.Schema_Characterset = &characterset
+	return &Builder{md: md}
+}
+
+func (b *Builder) Identifier(id *ogdat.Identifier) *Builder {
+	b.md.Metadata_Identifier = id
+	return b
+}
+
+func (b *Builder) Modified(t time.Time) *Builder {
+	b.md.Metadata_Modified = ogdat.NewTime(t, ogdat.CustomTimeSpecifier2)
+	return b
+}
+
+func (b *Builder) Title(title string) *Builder {
+	b.md.Title = &title
+	return b
+}
+
+func (b *Builder) Description(description string) *Builder {
+	b.md.Description = &description
+	return b
+}
+
+// Categories sets the categories, usually taken from ogdat.Categories
+func (b *Builder) Categories(cats ...ogdat.Kategorie) *Builder {
+	b.md.Categorization = &ogdat.MetaDataKategorie{Kategorie: cats}
+	return b
+}
+
+func (b *Builder) Keywords(keywords ...string) *Builder {
+	for _, keyword := range keywords {
+		b.md.Schlagworte = append(b.md.Schlagworte, ogdat.Tags(keyword))
+	}
+	return b
+}
+
+func (b *Builder) Maintainer(maintainer string) *Builder {
+	b.md.Maintainer = &maintainer
+	return b
+}
+
+func (b *Builder) MaintainerLink(link string) *Builder {
+	b.md.Maintainer_Link = ogdat.NewUrl(link)
+	return b
+}
+
+func (b *Builder) MaintainerEmail(email string) *Builder {
+	b.md.Maintainer_Email = ogdat.NewUrl(email)
+	return b
+}
+
+func (b *Builder) Publisher(publisher string) *Builder {
+	b.md.Publisher = &publisher
+	return b
+}
+
+func (b *Builder) License(license string) *Builder {
+	b.md.License = &license
+	return b
+}
+
+func (b *Builder) LicenseCitation(citation string) *Builder {
+	b.md.License_Citation = &citation
+	return b
+}
+
+// Begin sets the begin of the time period the data covers
+func (b *Builder) Begin(t time.Time) *Builder {
+	b.md.Begin_DateTime = ogdat.NewTime(t, ogdat.CustomTimeSpecifier1)
+	return b
+}
+
+// End sets the end of the time period the data covers
+func (b *Builder) End(t time.Time) *Builder {
+	b.md.End_DateTime = ogdat.NewTime(t, ogdat.CustomTimeSpecifier1)
+	return b
+}
+
+// UpdateFrequency sets the update cycle, one of the ogdat.Cyc* constants
+func (b *Builder) UpdateFrequency(cyc ogdat.Cycle) *Builder {
+	cyc.Raw = cyc.MD_MaintenanceFrequencyCode
+	b.md.Update_Frequency = &cyc
+	return b
+}
+
+func (b *Builder) Linkage(links ...string) *Builder {
+	linkage := &ogdat.MetaDataLinkage{IsArray: true}
+	for _, link := range links {
+		linkage.Url = append(linkage.Url, *ogdat.NewUrl(link))
+	}
+	b.md.Metadata_Linkage = linkage
+	return b
+}
+
+func (b *Builder) OriginalPortal(link string) *Builder {
+	b.md.Metadata_OriginalPortal = ogdat.NewUrl(link)
+	return b
+}
+
+func (b *Builder) AttributeDescription(description string) *Builder {
+	b.md.Attribute_Description = &description
+	return b
+}
+
+func (b *Builder) Toponym(toponym string) *Builder {
+	b.md.Geographich_Toponym = &toponym
+	return b
+}
+
+// BBox sets the bounding box as WKT POLYGON
+func (b *Builder) BBox(bbox string) *Builder {
+	b.md.Geographic_BBox = &bbox
+	return b
+}
+
+func (b *Builder) LineageQuality(quality string) *Builder {
+	b.md.Lineage_Quality = &quality
+	return b
+}
+
+func (b *Builder) EnTitleDesc(desc string) *Builder {
+	b.md.EnTitleDesc = &desc
+	return b
+}
+
+func (b *Builder) AddResource(res *ResourceBuilder) *Builder {
+	b.md.Resource = append(b.md.Resource, res.res)
+	return b
+}
+
+// Build checks the metadata document. If the check reports errors, no
+// document is returned and err names the first of them. Otherwise the
+// document is returned along with the remaining messages of the check.
+func (b *Builder) Build() (*MetaData, []ogdat.CheckMessage, error) {
+	msgs, err := b.md.Check(false)
+	if err != nil {
+		return nil, msgs, err
+	}
+	var errors []ogdat.CheckMessage
+	for _, msg := range msgs {
+		if msg.Type.IsError() {
+			errors = append(errors, msg)
+		}
+	}
+	if len(errors) > 0 {
+		field := "Metadatensatz"
+		if beschreibung, _ := ogdat.GetOGDSetForVersion(Version).GetBeschreibungForID(errors[0].OGDID); beschreibung != nil {
+			field = beschreibung.OGD_Kurzname
+		}
+		return nil, msgs, fmt.Errorf("Metadaten sind nicht spezifikationskonform, %d Fehler, u.a. %s: %s", len(errors), field, errors[0].Text)
+	}
+	md := *b.md
+	return &md, msgs, nil
+}
+
+// ResourceBuilder creates a resource to be added by Builder.AddResource
+type ResourceBuilder struct {
+	res Resource
+}
+
+// NewResource starts a resource available at link in format, which is given
+// in lower case without leading dot, e.g. "csv"
+func NewResource(link, format string) *ResourceBuilder {
+	rs := ogdat.ResourceSpecifier(format)
+	return &ResourceBuilder{res: Resource{Url: ogdat.NewUrl(link), Format: &rs}}
+}
+
+func (rb *ResourceBuilder) Name(name string) *ResourceBuilder {
+	rb.res.Name = &name
+	return rb
+}
+
+func (rb *ResourceBuilder) Created(t time.Time) *ResourceBuilder {
+	rb.res.Created = ogdat.NewTime(t, ogdat.CustomTimeSpecifier2)
+	return rb
+}
+
+func (rb *ResourceBuilder) LastModified(t time.Time) *ResourceBuilder {
+	rb.res.LastModified = ogdat.NewTime(t, ogdat.CustomTimeSpecifier2)
+	return rb
+}
+
+// Size sets the size of the resource in bytes
+func (rb *ResourceBuilder) Size(size int64) *ResourceBuilder {
+	s := strconv.FormatInt(size, 10)
+	rb.res.Size = &s
+	return rb
+}
+
+// Language sets the language of the resource as ISO 639-2 code, e.g. "ger"
+func (rb *ResourceBuilder) Language(language string) *ResourceBuilder {
+	rb.res.Language = &language
+	return rb
+}
+
+// Encoding sets the character set of the resource as registered by IANA, e.g. "utf8"
+func (rb *ResourceBuilder) Encoding(encoding string) *ResourceBuilder {
+	rb.res.Encoding = &encoding
+	return rb
+}
@@ -2,6 +2,7 @@ package ogdatv23
 
 import (
 	"encoding/json"
+	"github.com/the42/ogdat"
 	"io"
 	"io/ioutil"
 )
@@ -21,3 +22,11 @@ func MetadatafromJSONStream(jsondata io.Reader) (*MetaData, error) {
 	}
 	return data, nil
 }
+
+func parse(jsondata io.Reader) (ogdat.Metadater, error) {
+	md, err := MetadatafromJSONStream(jsondata)
+	if md == nil {
+		return nil, err
+	}
+	return md, err
+}
@@ -3,10 +3,16 @@ package ogdatv23
 import (
 	"encoding/json"
 	"github.com/the42/ogdat"
+	"github.com/the42/ogdat/internal/testutil"
+	"github.com/the42/ogdat/ogdatv21"
+	"github.com/the42/ogdat/ogdatv22"
 	"io/ioutil"
 	"os"
 	"path"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 type checkRequest struct {
@@ -31,13 +37,70 @@ var checkTests = []checkTest{
 	},
 	{ // as of V2.3 maintainer is a required field
 		&checkRequest{"file20.json", false},
-		&checkResponse{message: []ogdat.CheckMessage{{Type: ogdat.Error, OGDID: 20}, {Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 6},
+		&checkResponse{message: []ogdat.CheckMessage{{Type: ogdat.Error, OGDID: 20}, {Type: ogdat.Warning, OGDID: 21}, {Type: ogdat.Info, OGDID: 2}, {Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 6},
+			{Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 13}, {Type: ogdat.Info, OGDID: 22}}},
+	},
+	{ // metadata_original_portal must start with urn://data., this one does not
+		&checkRequest{"file33a.json", false},
+		&checkResponse{message: []ogdat.CheckMessage{{Type: ogdat.Warning, OGDID: 21}, {Type: ogdat.Info, OGDID: 2}, {Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 6},
+			{Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 13}, {Type: ogdat.Info, OGDID: 22},
+			{Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 33}, {Type: ogdat.Warning, OGDID: 33}}},
+	},
+	{ // metadata_original_portal must start with urn://data., this one does
+		&checkRequest{"file33b.json", false},
+		&checkResponse{message: []ogdat.CheckMessage{{Type: ogdat.Warning, OGDID: 21}, {Type: ogdat.Info, OGDID: 2}, {Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 6},
+			{Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 13}, {Type: ogdat.Info, OGDID: 22},
+			{Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 33}}},
+	},
+	{ // license is the URI of a known license
+		&checkRequest{"file21a.json", false},
+		&checkResponse{message: []ogdat.CheckMessage{{Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 6},
+			{Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 13}, {Type: ogdat.Info, OGDID: 22}}},
+	},
+	{ // license names a known license by a variant instead of its URI
+		&checkRequest{"file21b.json", false},
+		&checkResponse{message: []ogdat.CheckMessage{{Type: ogdat.Warning, OGDID: 21}, {Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 6},
+			{Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 13}, {Type: ogdat.Info, OGDID: 22}}},
+	},
+	{ // license is the URI of an unknown license
+		&checkRequest{"file21c.json", false},
+		&checkResponse{message: []ogdat.CheckMessage{{Type: ogdat.Warning, OGDID: 21}, {Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 6},
+			{Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 13}, {Type: ogdat.Info, OGDID: 22}}},
+	},
+	{ // CC0 requires no license_citation, this one has one
+		&checkRequest{"file21d.json", false},
+		&checkResponse{message: []ogdat.CheckMessage{{Type: ogdat.Info, OGDID: 21}, {Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 6},
+			{Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 13}, {Type: ogdat.Info, OGDID: 22}}},
+	},
+	{ // CC BY requires license_citation, this one has none
+		&checkRequest{"file21e.json", false},
+		&checkResponse{message: []ogdat.CheckMessage{{Type: ogdat.Warning, OGDID: 21}, {Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 6},
+			{Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 13}, {Type: ogdat.Info, OGDID: 22}}},
+	},
+	{ // geographic_toponym names a Bundesland and a Gemeinde of the gazetteer
+		&checkRequest{"file22a.json", false},
+		&checkResponse{message: []ogdat.CheckMessage{{Type: ogdat.Warning, OGDID: 21}, {Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 6},
 			{Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 13}}},
 	},
+	{ // geographic_toponym spells St. Pölten differently than the gazetteer
+		&checkRequest{"file22b.json", false},
+		&checkResponse{message: []ogdat.CheckMessage{{Type: ogdat.Warning, OGDID: 21}, {Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 6},
+			{Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 13}, {Type: ogdat.Info, OGDID: 22}}},
+	},
+	{ // geographic_toponym names no Austrian place
+		&checkRequest{"file22c.json", false},
+		&checkResponse{message: []ogdat.CheckMessage{{Type: ogdat.Warning, OGDID: 21}, {Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 6},
+			{Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 13}, {Type: ogdat.Info, OGDID: 22}}},
+	},
+	{ // geographic_toponym names Vorarlberg, geographic_bbox encloses Wien
+		&checkRequest{"file22d.json", false},
+		&checkResponse{message: []ogdat.CheckMessage{{Type: ogdat.Warning, OGDID: 21}, {Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 6},
+			{Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 13}, {Type: ogdat.Warning, OGDID: 22}}},
+	},
 	{ // This test is to check a metadata file in which every entry is OK
 		&checkRequest{"fullandok.json", false},
-		&checkResponse{message: []ogdat.CheckMessage{{Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 6},
-			{Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 13}}},
+		&checkResponse{message: []ogdat.CheckMessage{{Type: ogdat.Warning, OGDID: 21}, {Type: ogdat.Info, OGDID: 2}, {Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 6},
+			{Type: ogdat.Info | ogdat.FetchableUrl, OGDID: 13}, {Type: ogdat.Info, OGDID: 22}}},
 	},
 }
 
@@ -78,3 +141,202 @@ func TestCheck(t *testing.T) {
 		}
 	}
 }
+
+func TestParseMetadata(t *testing.T) {
+	md, err := ogdat.ParseMetadata(strings.NewReader(`{"extras": {"schema_name": "OGD Austria Metadata 2.3"}}`))
+	if err != nil {
+		t.Fatalf("TestParseMetadata: %s", err)
+	}
+	if _, ok := md.(*MetaData); !ok {
+		t.Fatalf("TestParseMetadata: expected *ogdatv23.MetaData, got %T", md)
+	}
+
+	for doc, code := range map[string]string{
+		`{"extras": {}}`: ogdat.CodeSchemaMissing,
+		`{"extras": {"schema_name": "OGD Austria Metadata"}}`:     ogdat.CodeSchemaNoVersion,
+		`{"extras": {"schema_name": "OGD Austria Metadata 9.9"}}`: ogdat.CodeVersionUnsupported} {
+		_, err := ogdat.ParseMetadata(strings.NewReader(doc))
+		versionerror, ok := err.(*ogdat.UnknownVersionError)
+		if !ok {
+			t.Fatalf("TestParseMetadata (%s): expected *ogdat.UnknownVersionError, got %v", doc, err)
+		}
+		if msg := versionerror.Message(); msg.Code != code {
+			t.Errorf("TestParseMetadata (%s): expected %s, got %s", doc, code, msg.Code)
+		}
+	}
+}
+
+// TestRoundTrip checks that marshalling the unmarshalled test files loses nothing
+func TestRoundTrip(t *testing.T) {
+	testutil.RoundTrip(t, "./testfiles/*.json", func() interface{} { return &MetaData{} })
+}
+
+func TestBuilder(t *testing.T) {
+	md, msgs, err := NewMetaData().
+		Title("Bevölkerung nach Bezirken").
+		Description("Bevölkerungsstand der Wiener Bezirke zum Jahresbeginn").
+		Categories(ogdat.Bevoelkerung, ogdat.GesellSoziales).
+		Keywords("Bevölkerung", "Bezirke").
+		Maintainer("Statistik Wien").
+		Publisher("Stadt Wien").
+		License("Creative Commons Namensnennung 3.0 Österreich").
+		Begin(time.Date(2002, 1, 1, 0, 0, 0, 0, time.UTC)).
+		UpdateFrequency(ogdat.CycAnnually).
+		AddResource(NewResource("http://example.com/bev.csv", "csv").Name("Bevölkerung").Size(4681).Encoding("utf8")).
+		Build()
+	if err != nil {
+		t.Fatalf("TestBuilder: %s (%v)", err, msgs)
+	}
+	if md.Metadata_Identifier == nil || md.Metadata_Identifier.UUID == nil {
+		t.Errorf("TestBuilder: expected a generated UUID, got %v", md.Metadata_Identifier)
+	}
+	if md.Begin_DateTime.Raw != "2002-01-01T00:00:00" {
+		t.Errorf("TestBuilder: unexpected begin_datetime %s", md.Begin_DateTime.Raw)
+	}
+
+	data, err := json.Marshal(md)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed := &MetaData{}
+	if err := json.Unmarshal(data, parsed); err != nil {
+		t.Fatal(err)
+	}
+	if parsedmsgs, _ := parsed.Check(false); !reflect.DeepEqual(parsedmsgs, msgs) {
+		t.Errorf("TestBuilder: check of marshalled document differs: %v <-> %v", parsedmsgs, msgs)
+	}
+
+	if md, _, err := NewMetaData().Title("Ohne Beschreibung").Build(); err == nil || md != nil {
+		t.Errorf("TestBuilder: expected incomplete metadata to be refused, got %v", md)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	data, err := ioutil.ReadFile("../ogdatv21/testfiles/fullandok.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	extras := doc["extras"].(map[string]interface{})
+	delete(extras, "publisher")
+	extras["maintainer_link"] = "mailto:ogd@example.com"
+	if data, err = json.Marshal(doc); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := ogdat.Migrate(data, "", "2.3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.From != ogdatv21.Version || m.To != Version {
+		t.Errorf("TestMigrate: unexpected versions %s -> %s", m.From, m.To)
+	}
+	md, err := MetadatafromJSONStream(strings.NewReader(string(m.Data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if md.Schema_Name == nil || *md.Schema_Name != Version {
+		t.Errorf("TestMigrate: schema_name not set to %s", Version)
+	}
+	if md.Geographic_BBox == nil || *md.Geographic_BBox != "POLYGON ((-180.00 -90.00, 180.00 -90.00, 180.00 90.00, -180.00 90.00, -180.00 -90.00))" {
+		t.Errorf("TestMigrate: bounding box not closed: %v", md.Geographic_BBox)
+	}
+	if md.Maintainer_Email == nil || md.Maintainer_Email.Raw != "ogd@example.com" {
+		t.Errorf("TestMigrate: maintainer_email not taken from maintainer_link: %v", md.Maintainer_Email)
+	}
+	issues := make(map[string]bool)
+	for _, issue := range m.Issues {
+		issues[issue.Field] = true
+	}
+	if !issues["extras:publisher"] || !issues["extras:metadata_original_portal"] || issues["maintainer_email"] {
+		t.Errorf("TestMigrate: unexpected issues %v", m.Issues)
+	}
+	publishermissing := false
+	for _, msg := range m.Messages {
+		if msg.Type.IsError() {
+			if msg.OGDID != 20 {
+				t.Errorf("TestMigrate: unexpected error %v", msg)
+			}
+			publishermissing = true
+		}
+	}
+	if !publishermissing {
+		t.Errorf("TestMigrate: expected the check to report the missing publisher")
+	}
+
+	back, err := ogdat.Migrate(m.Data, "", "2.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	md21, err := ogdatv21.MetadatafromJSONStream(strings.NewReader(string(back.Data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if md21.Geographic_BBox == nil || *md21.Geographic_BBox != "POLYGON ((-180.00 -90.00, 180.00 90.00))" {
+		t.Errorf("TestMigrate: bounding box not converted back: %v", md21.Geographic_BBox)
+	}
+	if len(back.Issues) != 1 || back.Issues[0].Field != "maintainer_email" {
+		t.Errorf("TestMigrate: expected maintainer_email to be reported as not part of 2.1, got %v", back.Issues)
+	}
+
+	if _, err := ogdat.Migrate(data, "", "1.0"); err == nil {
+		t.Errorf("TestMigrate: expected error for unknown target version")
+	}
+}
+
+func TestCheckSchemaName(t *testing.T) {
+	field, _ := ogdat.GetOGDSetForVersion(Version).GetBeschreibungForID(2)
+	tests := []struct {
+		schemaname string
+		codes      []string
+	}{
+		{"OGD Austria Metadata 2.3", nil},
+		{"OGD Austria Metadata 2.2", []string{ogdat.CodeSchemaNameVersion}},
+		{"OGD Austria\\nMetadata 2.3", []string{ogdat.CodeTextPosixEscape}},
+		{"OGD Austria\\nMetadata 2.2", []string{ogdat.CodeTextPosixEscape, ogdat.CodeSchemaNameVersion}},
+	}
+	for idx, test := range tests {
+		schemaname := test.schemaname
+		msgs, err := checkschemaname(&ogdat.RuleContext{Version: Version, Field: field, Value: &schemaname, Resource: -1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(msgs) != len(test.codes) {
+			t.Errorf("TestCheckSchemaName [%d]: expected %v, got %v", idx, test.codes, msgs)
+			continue
+		}
+		for midx, msg := range msgs {
+			if msg.Code != test.codes[midx] {
+				t.Errorf("TestCheckSchemaName [%d]: expected %s, got %s", idx, test.codes[midx], msg.Code)
+			}
+			if msg.Code == ogdat.CodeSchemaNameVersion && msg.Params.Args["version"] != "2.3" {
+				t.Errorf("TestCheckSchemaName [%d]: expected version 2.3, got %s", idx, msg.Params.Args["version"])
+			}
+		}
+	}
+}
+
+func TestDetectMetadataVersion(t *testing.T) {
+	tests := []struct {
+		doc, version string
+	}{
+		{`{"extras": {"schema_name": "OGD Austria Metadata 2.3"}}`, Version},
+		{`{"extras": {"schema_name": "OGD Austria Metadata 9.9", "publisher": "ACME"}}`, ogdatv21.Version},
+		{`{"notes": "Beschreibung", "isopen": true, "extras": {"publisher": "ACME"}}`, ogdatv21.Version},
+		// fields of version 2.2 are kept by 2.3, the oldest version is chosen
+		{`{"maintainer_email": "info@example.com", "extras": {"publisher": "ACME"}}`, ogdatv22.Version},
+		{`{"extras": {"metadata_original_portal": "http://data.example.com"}}`, ogdatv22.Version},
+	}
+	for idx, test := range tests {
+		f, reason, err := ogdat.DetectMetadataVersion([]byte(test.doc))
+		if err != nil {
+			t.Fatalf("TestDetectMetadataVersion [%d]: %s", idx, err)
+		}
+		if f.Version != test.version {
+			t.Errorf("TestDetectMetadataVersion [%d]: expected %s, got %s (%s)", idx, test.version, f.Version, reason)
+		}
+	}
+}
@@ -31,13 +31,15 @@ func (conn *watcherdb) GetLastHit() (*time.Time, error) {
 type DataUrl struct {
 	Url         string
 	Field_id    int
-	FieldStatus int
+	Resource    int // index of the resource the link belongs to, -1 if unknown
+	FieldStatus ogdat.Status
 	DatasetID   database.DBID
+	Declared    declaredresource
 }
 
 var getdataurlselect = fmt.Sprintf(`
 
-SELECT DISTINCT t.datasetid, t.field_id, t.fieldstatus, t.reason_text
+SELECT DISTINCT t.datasetid, t.field_id, t.fieldstatus, t.reason_text, t.resource, rh.declaredsize, rh.declaredlastmodified
 FROM status AS t
 JOIN (
   SELECT datasetid, max(hittime) hittime
@@ -56,6 +58,15 @@ AND NOT EXISTS (
   FROM status AS s
   WHERE s.datasetid = t.datasetid
   AND s.status = 'deleted')
+-- die zuletzt angegebene Größe und Änderungszeit der Ressource
+LEFT JOIN resourcehead AS rh
+ON rh.datasetid = t.datasetid
+AND rh.resource = t.resource
+AND rh.hittime = (
+  SELECT max(hittime)
+  FROM resourcehead
+  WHERE datasetid = t.datasetid
+  AND resource = t.resource)
 ORDER BY t.datasetid`, ogdat.Info|ogdat.FetchableUrl, ogdat.Info|ogdat.FetchableUrl)
 
 func (conn *watcherdb) GetDataUrls() ([][]DataUrl, error) {
@@ -68,23 +79,40 @@ func (conn *watcherdb) GetDataUrls() ([][]DataUrl, error) {
 
 	var url string
 	var field_id int
-	var fieldstatus int
+	var fieldstatus ogdat.Status
+	var resource sql.NullInt64
+	var declaredsize sql.NullString
+	var declaredlastmodified pq.NullTime
 	var dbid database.DBID
 	var olddbid database.DBID = -1
 
 	for rows.Next() {
 
-		if err := rows.Scan(&dbid, &field_id, &fieldstatus, &url); err != nil {
+		if err := rows.Scan(&dbid, &field_id, &fieldstatus, &url, &resource, &declaredsize, &declaredlastmodified); err != nil {
 			return nil, err
 		}
 
+		idx := -1
+		if resource.Valid {
+			idx = int(resource.Int64)
+		}
+		var declared declaredresource
+		if declaredsize.Valid {
+			size := declaredsize.String
+			declared.size = &size
+		}
+		if declaredlastmodified.Valid {
+			lastmodified := declaredlastmodified.Time
+			declared.lastmodified = &lastmodified
+		}
+
 		if olddbid != dbid {
 			if dataurl != nil {
 				dataurls = append(dataurls, dataurl)
 			}
 			dataurl = nil
 		}
-		dataurl = append(dataurl, DataUrl{DatasetID: dbid, Url: url, Field_id: field_id, FieldStatus: fieldstatus})
+		dataurl = append(dataurl, DataUrl{DatasetID: dbid, Url: url, Field_id: field_id, Resource: idx, FieldStatus: fieldstatus, Declared: declared})
 		olddbid = dbid
 	}
 	if err := rows.Err(); err != nil {
@@ -94,7 +122,7 @@ func (conn *watcherdb) GetDataUrls() ([][]DataUrl, error) {
 }
 
 func (conn *watcherdb) ResetDatabase() error {
-	_, err := conn.Exec("DELETE FROM status; DELETE FROM dataset;")
+	_, err := conn.Exec("DELETE FROM resourcehead; DELETE FROM status; DELETE FROM dataset;")
 	if err != nil {
 		return err
 	}
@@ -136,7 +164,7 @@ func (conn *watcherdb) MarkDatasetDeleted(ckanid string) (database.DBID, error)
 
 func (conn *watcherdb) InsertOrUpdateMetadataInfo(ckanid string, md *ogdat.MinimalMetaData) (database.DBID, bool, error) {
 	// insertorupdatemetadatainfo(id character varying, pub character varying, cont character varying, descr text, vers character varying, category json, stime timestamp with time zone)
-	const stmt = "SELECT * FROM insertorupdatemetadatainfo($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)"
+	const stmt = "SELECT * FROM insertorupdatemetadatainfo($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)"
 
 	if md == nil {
 		return -1, false, fmt.Errorf("No input to process")
@@ -179,6 +207,11 @@ func (conn *watcherdb) InsertOrUpdateMetadataInfo(ckanid string, md *ogdat.Minim
 		*geotoponym = DBStringLen(*geotoponym, 255)
 	}
 
+	license := md.License
+	if license != nil {
+		*license = DBStringLen(*license, 255)
+	}
+
 	var cats []string
 	if cat := md.Categorization; cat != nil {
 		for _, cat := range cat.Kategorie {
@@ -191,7 +224,7 @@ func (conn *watcherdb) InsertOrUpdateMetadataInfo(ckanid string, md *ogdat.Minim
 
 	var sysid database.DBID
 	var isnew bool
-	err = dbs.QueryRow(ckanid, id, pub, maint, desc, vers, string(cat), t, geobbox, geotoponym).Scan(&sysid, &isnew)
+	err = dbs.QueryRow(ckanid, id, pub, maint, desc, vers, string(cat), t, geobbox, geotoponym, license).Scan(&sysid, &isnew)
 
 	if err != nil {
 		return -1, false, err
@@ -203,29 +236,101 @@ func (conn *watcherdb) InsertOrUpdateMetadataInfo(ckanid string, md *ogdat.Minim
 func (conn *watcherdb) ProtocollCheck(id database.DBID, isnew bool, messages []ogdat.CheckMessage) error {
 
 	// This is append only; revise later if it should also delete or update entries.
-	const insstmt = "INSERT INTO status(datasetid, field_id, status, fieldstatus, reason_text, hittime) VALUES ($1, $2, $3, $4, $5, $6)"
+	const insstmt = "INSERT INTO status(datasetid, field_id, status, fieldstatus, reason_text, hittime, resource) VALUES ($1, $2, $3, $4, $5, $6, $7)"
 
 	var stmt *sql.Stmt
 	var err error
 	if stmt, err = conn.Prepare(insstmt); err != nil {
 		return err
 	}
+	defer stmt.Close()
 
 	// get time here and not within the loop so we have a grouping possibilitiy
 	t := time.Now().UTC()
 	var status string
 	for _, msg := range messages {
 		switch {
-		case (msg.Type & ogdat.Error) != 0:
+		case msg.Type.IsError():
 			status = "error"
-		case (msg.Type & ogdat.Warning) != 0:
+		case msg.Type.IsWarning():
 			status = "warning"
-		case (msg.Type & ogdat.Info) != 0:
+		case msg.Type.IsInfo():
 			status = "info"
 		}
-		if _, err = stmt.Exec(id, msg.OGDID, status, msg.Type, msg.Text, t); err != nil {
+		var resource *int
+		if msg.Params.Resource > -1 {
+			idx := msg.Params.Resource
+			resource = &idx
+		}
+		if _, err = stmt.Exec(id, msg.OGDID, status, int(msg.Type), msg.Text, t, resource); err != nil {
 			return fmt.Errorf("Error inserting status for datasetid %d, fieldid %d: %s", id, msg.OGDID, err)
 		}
 	}
 	return nil
 }
+
+// declaredresource is the size and date of last modification a metadata
+// record declares for one of its resources
+type declaredresource struct {
+	size         *string
+	lastmodified *time.Time
+}
+
+// ProtocollResourceHeads stores what the servers reported about the resources
+// of the metadata record id, as carried by the messages of reachable resource
+// links in Head, together with the declared size and date of last modification.
+func (conn *watcherdb) ProtocollResourceHeads(id database.DBID, md *ogdat.MinimalMetaData, messages []ogdat.CheckMessage) error {
+	declared := make(map[int]declaredresource, len(md.Resources))
+	for idx, res := range md.Resources {
+		var d declaredresource
+		if res.Size != nil {
+			size := DBStringLen(*res.Size, 255)
+			d.size = &size
+		}
+		if res.LastModified != nil && res.LastModified.Format != "" {
+			d.lastmodified = &res.LastModified.Time
+		}
+		declared[idx] = d
+	}
+	return conn.protocollresourceheads(id, declared, messages)
+}
+
+// protocollresourceheads stores the heads carried by messages about the
+// resources listed in declared
+func (conn *watcherdb) protocollresourceheads(id database.DBID, declared map[int]declaredresource, messages []ogdat.CheckMessage) error {
+	const insstmt = "INSERT INTO resourcehead(datasetid, resource, url, contentlength, lastmodified, etag, declaredsize, declaredlastmodified, hittime) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)"
+
+	var stmt *sql.Stmt
+	var err error
+	if stmt, err = conn.Prepare(insstmt); err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	t := time.Now().UTC()
+	for _, msg := range messages {
+		head, resource := msg.Head, msg.Params.Resource
+		if head == nil {
+			continue
+		}
+		res, ok := declared[resource]
+		if !ok {
+			continue
+		}
+
+		var contentlength *int64
+		if head.ContentLength > -1 {
+			contentlength = &head.ContentLength
+		}
+		var etag *string
+		if head.ETag != "" {
+			tag := DBStringLen(head.ETag, 255)
+			etag = &tag
+		}
+
+		if _, err = stmt.Exec(id, resource, head.URL, contentlength, head.LastModified, etag, res.size, res.lastmodified, t); err != nil {
+			return fmt.Errorf("Error inserting resource head for datasetid %d, resource %d: %s", id, resource, err)
+		}
+	}
+	return nil
+}
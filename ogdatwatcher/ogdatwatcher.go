@@ -16,9 +16,10 @@ import (
 	"github.com/the42/ogdat"
 	"github.com/the42/ogdat/ckan"
 	"github.com/the42/ogdat/database"
-	"github.com/the42/ogdat/ogdatv21"
-	"github.com/the42/ogdat/ogdatv22"
-	"github.com/the42/ogdat/ogdatv23"
+	// register the supported versions of the specification
+	_ "github.com/the42/ogdat/ogdatv21"
+	_ "github.com/the42/ogdat/ogdatv22"
+	_ "github.com/the42/ogdat/ogdatv23"
 	"github.com/the42/ogdat/schedule"
 )
 
@@ -31,6 +32,7 @@ var portal *ckan.Portal
 var resettdb = flag.Bool("resetdb", false, "Delete the tracking database. You will be prompted before actual deletion. Process will terminate afterwards.")
 var servetdb = flag.Bool("serve", false, "Start in watchdog mode. Process will continue to run until it receives a (clean shutdown) or gets killed")
 var sdidle = flag.Duration("sdidle", -1, "Shutdown the process when the next action is longer than x minutes ahead")
+var rulesfile = flag.String("rules", "", "JSON file configuring the check rules (enable, disable, severity, options)")
 
 func gotyesonprompt() bool {
 	var prompt string
@@ -214,30 +216,22 @@ nextid:
 			logger.Printf("Info: Minimal Metadata for ID %v could not be parsed, error returned?\n", id)
 			continue
 		}
-		var version string
-		if mmd.Schema_Name != nil {
-			version = ogdat.OGDVersionfromString(*mmd.Schema_Name)
-		}
-
 		dbdatasetid, isnew, err := conn.InsertOrUpdateMetadataInfo(id, mmd)
 		if err != nil {
 			return fmt.Errorf("InsertOrUpdateMetadataInfo: database error at id %v: %s", id, err)
 		}
 
-		switch version {
-		case "2.0", "2.1":
-			md, jsonparseerror = ogdatv21.MetadatafromJSONStream(mdjson)
-		case "2.2":
-			md, jsonparseerror = ogdatv22.MetadatafromJSONStream(mdjson)
-		case "2.3":
-			md, jsonparseerror = ogdatv23.MetadatafromJSONStream(mdjson)
-		case "":
-			logger.Printf("No Metadata Schema given for ID %v, skipping", id)
-			messages = []ogdat.CheckMessage{{Type: ogdat.Info, Text: "Kein Schema spezifiziert, Metadaten können nicht überprüft werden", OGDID: -1}}
-		default:
-			logger.Printf("Identified Metadata Version %s but no checker implemented", version)
-			s := fmt.Sprintf("Für die Metadatenversion %s ist keine Überprüfung implementiert", version)
-			messages = []ogdat.CheckMessage{{Type: ogdat.Info, Text: s, OGDID: -1}}
+		md, jsonparseerror = ogdat.ParseMetadata(mdjson)
+		if versionerror, ok := jsonparseerror.(*ogdat.UnknownVersionError); ok {
+			if versionerror.SchemaName == "" {
+				logger.Printf("No Metadata Schema given for ID %v, skipping", id)
+			} else if versionerror.Version == "" {
+				logger.Printf("Metadata Schema '%s' of ID %v names no version, skipping", versionerror.SchemaName, id)
+			} else {
+				logger.Printf("Identified Metadata Version %s but no checker implemented", versionerror.Version)
+			}
+			messages = []ogdat.CheckMessage{versionerror.Message()}
+			jsonparseerror = nil
 		}
 
 		if jsonparseerror != nil {
@@ -253,6 +247,9 @@ nextid:
 		if err = conn.ProtocollCheck(dbdatasetid, isnew, messages); err != nil {
 			return fmt.Errorf("ProtocollCheck: database error at id %v: %s", id, err)
 		}
+		if err = conn.ProtocollResourceHeads(dbdatasetid, mmd, messages); err != nil {
+			return fmt.Errorf("ProtocollResourceHeads: database error at id %v: %s", id, err)
+		}
 	}
 	logger.Printf("Worker finished processing %d entries\n", nums)
 	return nil
@@ -264,19 +261,24 @@ func processdataseturls(conn *watcherdb, nestedurls [][]DataUrl) error {
 	for setidx, urls := range nestedurls {
 		logger.Printf("%4d / %4d", setidx+1, len(nestedurls))
 		messages := make([]ogdat.CheckMessage, len(urls))
+		declared := make(map[int]declaredresource)
 		for idx, url := range urls {
 			logger.Printf("%4d / %4d: processing %s", idx+1, len(urls), url.Url)
 
 			_, checkresult := ogdat.FetchHead(url.Url)
 
-			messages[idx].Type = checkresult.Status
-			messages[idx].Text = checkresult.Context
-			messages[idx].OGDID = url.Field_id
+			messages[idx] = checkresult.Message(url.Field_id, url.Resource)
+			if url.Resource > -1 {
+				declared[url.Resource] = url.Declared
+			}
 
 			anz++
 		}
 		if err := conn.ProtocollCheck(urls[0].DatasetID, true, messages); err != nil {
-			return fmt.Errorf("ProtocollCheck: database error at id %v: %s", urls[setidx].DatasetID, err)
+			return fmt.Errorf("ProtocollCheck: database error at id %v: %s", urls[0].DatasetID, err)
+		}
+		if err := conn.protocollresourceheads(urls[0].DatasetID, declared, messages); err != nil {
+			return fmt.Errorf("ProtocollResourceHeads: database error at id %v: %s", urls[0].DatasetID, err)
 		}
 	}
 	logger.Printf("Worker finished processing %d entries\n", anz)
@@ -422,6 +424,16 @@ func mymain() int {
 		logger.Panicln("Fatal: No command line flags given")
 	}
 
+	if *rulesfile != "" {
+		cfg, err := ogdat.LoadRuleConfigFile(*rulesfile)
+		if err != nil {
+			logger.Panicln(err)
+		}
+		if err := ogdat.SetRuleConfig(cfg); err != nil {
+			logger.Panicln(err)
+		}
+	}
+
 	dbconnection, err := database.GetDatabaseConnection()
 	if err != nil {
 		logger.Panicln(err)
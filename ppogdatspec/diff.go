@@ -0,0 +1,154 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/the42/ogdat"
+	htmltpl "html/template"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	texttpl "text/template"
+
+	// register the specifications of the supported versions
+	_ "github.com/the42/ogdat/ogdatv21"
+	_ "github.com/the42/ogdat/ogdatv22"
+	_ "github.com/the42/ogdat/ogdatv23"
+)
+
+// loadspec returns the specification in the CSV file source or, if there is
+// no such file, the specification registered for the version source
+func loadspec(source string) (*ogdat.OGDSet, error) {
+	if _, err := os.Stat(source); err == nil {
+		return ogdat.Loadogdatspec(speclabel(source), source)
+	}
+	f, err := ogdat.GetMetadataFactory(source)
+	if err != nil {
+		return nil, fmt.Errorf("Weder Datei noch unterstützte Version: '%s'", source)
+	}
+	return f.Spec, nil
+}
+
+// speclabel returns the version of the specification in filename, as found in
+// the file name, e.g. "OGD Austria Metadata 2.3" for "ogdat_spec-2.3.csv"
+func speclabel(filename string) string {
+	if number := ogdat.OGDVersionfromString(filepath.Base(filename)); number != "" {
+		return "OGD Austria Metadata " + number
+	}
+	return filepath.Base(filename)
+}
+
+func writediffjson(w io.Writer, diff *ogdat.SpecDiff) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(diff)
+}
+
+var difffuncs = map[string]interface{}{
+	"kindname": func(kind string) string {
+		switch kind {
+		case ogdat.FieldAdded:
+			return "hinzugefügt"
+		case ogdat.FieldRemoved:
+			return "entfernt"
+		}
+		return "geändert"
+	},
+}
+
+// diffmain implements 'ppogdatspec diff [Optionen] alt neu'
+func diffmain(args []string) {
+	flags := flag.NewFlagSet("diff", flag.ExitOnError)
+	format := flags.String("format", "text", "Ausgabeformat des Vergleichs. Werte: {text|html|json}")
+	output := flags.String("of", "", "Ausgabe des Vergleichs nach (Standard: stdout)")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Verwendung: ppogdatspec diff [Optionen] alt neu\nalt und neu sind Spezifikationsdateien (CSV) oder Versionen, z.B. 2.1")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() != 2 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	old, err := loadspec(flags.Arg(0))
+	if err != nil {
+		log.Fatalln(err)
+	}
+	new, err := loadspec(flags.Arg(1))
+	if err != nil {
+		log.Fatalln(err)
+	}
+	diff := ogdat.DiffSpec(old, new)
+
+	w := os.Stdout
+	if *output != "" {
+		if w, err = os.Create(*output); err != nil {
+			log.Fatalf("Can't open file %s for writing, the error was: %s\n", *output, err)
+		}
+		defer w.Close()
+	}
+
+	switch *format {
+	case "text":
+		err = texttpl.Must(texttpl.New("diff").Funcs(difffuncs).Parse(difftexttpl)).Execute(w, diff)
+	case "html":
+		err = htmltpl.Must(htmltpl.New("diff").Funcs(difffuncs).Parse(diffhtmltpl)).Execute(w, diff)
+	case "json":
+		err = writediffjson(w, diff)
+	default:
+		log.Fatalf("Nicht unterstütztes Ausgabeformat: '%s'\n", *format)
+	}
+	if err != nil {
+		log.Fatalf("Template execution failed: %s\n", err)
+	}
+}
+
+const difftexttpl = `Unterschiede zwischen {{.Old}} und {{.New}}: {{len .Fields}} Felder
+{{range .Fields}}
+[{{.ID}}] {{.Name}} ({{kindname .Kind}})
+{{- range .Changes}}
+  {{.Property}}: {{if .Old}}'{{.Old}}'{{else}}-{{end}} -> {{if .New}}'{{.New}}'{{else}}-{{end}}
+{{- end}}
+{{end}}`
+
+const diffhtmltpl = `<!DOCTYPE html>
+<html lang="de">
+<head>
+<title>{{.Old}} - {{.New}}</title>
+<meta charset="UTF-8">
+<style>
+  table.ogdatspecdiff {
+    width: 800px;
+    border-collapse: collapse;
+  }
+  th {
+    text-align: left;
+  }
+  tr.added td {
+    background-color: hsl(120, 100%, 95%);
+  }
+  tr.removed td {
+    background-color: hsl(0, 100%, 95%);
+  }
+</style>
+</head>
+<body>
+<h1>Unterschiede zwischen {{.Old}} und {{.New}}</h1>
+<table class="ogdatspecdiff">
+  <thead>
+    <tr><th>ID</th><th>Feld</th><th>Eigenschaft</th><th>{{.Old}}</th><th>{{.New}}</th></tr>
+  </thead>
+  <tbody>{{range .Fields}}{{$field := .}}{{range $idx, $change := .Changes}}
+    <tr class="{{$field.Kind}}">
+      {{if eq $idx 0}}<td rowspan="{{len $field.Changes}}">{{$field.ID}}</td><td rowspan="{{len $field.Changes}}">{{$field.Name}} ({{kindname $field.Kind}})</td>{{end}}
+      <td>{{$change.Property}}</td><td>{{$change.Old}}</td><td>{{$change.New}}</td>
+    </tr>{{end}}{{end}}
+  </tbody>
+</table>
+</body>
+</html>
+`
@@ -8,11 +8,18 @@ import (
 	"io"
 	"log"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	texttpl "text/template"
 )
 
-var inputfile = flag.String("if", "", "Eingabe mit OGD-Spezifikation (Standard: stdin)")
+var inputfile = flag.String("if", "", "Eingabe mit OGD-Spezifikation (Standard: stdin, oder die mit -version angegebene Version)")
 var outputfile = flag.String("of", "", "Ausgabe der Spezifikation nach (Standard: stdout)")
+var overwrite = flag.Bool("overwrite", false, "Eine bestehende Ausgabedatei überschreiben")
+var version = flag.String("version", "", "Version der Spezifikation, z.B. 2.3. Ohne -if wird die eingebaute Spezifikation dieser Version verwendet")
+var all = flag.Bool("all", false, "Alle unterstützten Versionen nebeneinander mit Kennzeichnung der Änderungen ausgeben")
+var format = flag.String("format", "html", "Format des eingebauten Templates. Werte: {"+strings.Join(formats(), "|")+"}")
 var templateset = flag.String("ts", "", "(Satz von) Template-Dateien, die die Transformation der Spezifikation ins Ausgabeformat beschreibt")
 var html = flag.Bool("html", true, "Anwendung von HTML-Escaping in der Ausgabe")
 var printbuiltin = flag.Bool("printbuiltin", false, "Ausgabe von eingebautem Template (stdout)")
@@ -22,39 +29,158 @@ type Templater interface {
 	Execute(io.Writer, interface{}) error
 }
 
+// formats returns the formats of the builtin templates
+func formats() []string {
+	names := make([]string, 0, len(builtintpls))
+	for name := range builtintpls {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// readspec returns the specification in filename, labeled with version or, if
+// not given, the version found in the file name. Without filename the builtin
+// specification of version is returned, or the specification read from stdin.
+func readspec(filename, version string) (*ogdat.OGDSet, string, error) {
+	if filename == "" && version != "" {
+		f, err := ogdat.GetMetadataFactory(version)
+		if err != nil {
+			return nil, "", err
+		}
+		return f.Spec, f.Version, nil
+	}
+	if filename == "" {
+		filename = os.Stdin.Name()
+	}
+	label := speclabel(filename)
+	if version != "" {
+		label = "OGD Austria Metadata " + version
+		if f, err := ogdat.GetMetadataFactory(version); err == nil {
+			label = f.Version
+		}
+	}
+	spec, err := ogdat.Loadogdatspec(label, filename)
+	return spec, label, err
+}
+
+// readall returns the specifications of all registered versions side-by-side
+func readall() versionsdata {
+	var versions []string
+	var sets []*ogdat.OGDSet
+	for _, f := range ogdat.MetadataFactories() {
+		versions = append(versions, f.Version)
+		sets = append(sets, f.Spec)
+	}
+	return sidebyside(versions, sets)
+}
+
+// createoutput opens filename for writing. An existing file is not overwritten
+// but reported as an error, unless -overwrite is given.
+func createoutput(filename string) (*os.File, error) {
+	mode := os.O_RDWR | os.O_CREATE | os.O_EXCL
+	if *overwrite {
+		mode = os.O_RDWR | os.O_CREATE | os.O_TRUNC
+	}
+	return os.OpenFile(filename, mode, os.FileMode(0666))
+}
+
+// writeschemas writes the JSON Schema of each registered version into dir,
+// named e.g. ogdat_schema-2.3.json
+func writeschemas(dir string) error {
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return err
+	}
+	for _, f := range ogdat.MetadataFactories() {
+		schema, err := ogdat.JSONSchema(f.Spec)
+		if err != nil {
+			return err
+		}
+		filename := filepath.Join(dir, "ogdat_schema-"+ogdat.OGDVersionfromString(f.Version)+".json")
+		ofile, err := createoutput(filename)
+		if err != nil {
+			return err
+		}
+		_, err = ofile.Write(append(schema, '\n'))
+		if cerr := ofile.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return err
+		}
+		log.Printf("JSON Schema of %s written to %s\n", f.Version, filename)
+	}
+	return nil
+}
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "diff" {
+		diffmain(os.Args[2:])
+		return
+	}
 	flag.Parse()
 
 	// Display help and exit
 	if *help {
+		fmt.Println("Verwendung: ppogdatspec [Optionen] oder ppogdatspec diff [Optionen] alt neu")
 		flag.PrintDefaults()
 		return
 	}
 
+	// JSON Schemas of all versions are written to the directory given by -of
+	if *all && *format == "jsonschema" && *templateset == "" && !*printbuiltin {
+		if *outputfile == "" {
+			log.Fatalln("-all -format jsonschema benötigt mit -of ein Verzeichnis für die Ausgabe")
+		}
+		if err := writeschemas(*outputfile); err != nil {
+			log.Fatalf("Can't write JSON Schema, the error was: %s\n", err)
+		}
+		return
+	}
+
+	builtins := builtintpls
+	if *all {
+		builtins = builtinalltpls
+	}
+	builtin, ok := builtins[*format]
+	if !ok && *all {
+		log.Fatalf("Kein eingebautes Template für das Format '%s' mit -all\n", *format)
+	} else if !ok {
+		log.Fatalf("Nicht unterstütztes Ausgabeformat: '%s'\n", *format)
+	}
+
 	// Display default template and exit
 	if *printbuiltin {
-		fmt.Print(builtintpl)
+		fmt.Print(builtin)
 		return
 	}
 
-	// If no input spec file is given, read from stdin
-	if *inputfile == "" {
-		*inputfile = os.Stdin.Name()
-	}
-	spec, err := ogdat.Loadogdatspec("unknown", *inputfile)
-	if err != nil {
-		log.Panicf("Could not load specification file %s, the error was %s\n", *inputfile, err)
+	var data interface{}
+	if *all {
+		if *inputfile != "" || *version != "" {
+			log.Fatalln("-all kann nicht mit -if oder -version kombiniert werden")
+		}
+		data = readall()
+	} else {
+		spec, label, err := readspec(*inputfile, *version)
+		if err != nil {
+			log.Fatalf("Could not load specification %s, the error was %s\n", *inputfile, err)
+		}
+		data = specdata{OGDSet: spec, Version: label}
 	}
 
 	var tpl Templater
 	if *templateset != "" { // If a template set was given, try to read it
+		name := filepath.Base(*templateset)
 		if *html {
-			tpl = htmltpl.Must(htmltpl.ParseFiles(*templateset))
+			tpl = htmltpl.Must(htmltpl.New(name).Funcs(templatefuncs).ParseFiles(*templateset))
 		} else {
-			tpl = texttpl.Must(texttpl.ParseFiles(*templateset))
+			tpl = texttpl.Must(texttpl.New(name).Funcs(templatefuncs).ParseFiles(*templateset))
 		}
-	} else { // otherwise use the built-in
-		tpl = htmltpl.Must(htmltpl.New("").Parse(builtintpl))
+	} else if *format == "html" { // otherwise use the built-in
+		tpl = htmltpl.Must(htmltpl.New(*format).Funcs(templatefuncs).Parse(builtin))
+	} else {
+		tpl = texttpl.Must(texttpl.New(*format).Funcs(templatefuncs).Parse(builtin))
 	}
 
 	// Open the output file
@@ -63,113 +189,13 @@ func main() {
 		ofile = os.Stdout
 	} else {
 		var err error
-		// Do not overwrite an exisiting file but fail
-		ofile, err = os.OpenFile(*outputfile, os.O_RDWR|os.O_CREATE|os.O_EXCL, os.FileMode(0666))
-		if err != nil {
-			log.Panicf("Can't open file %s for writing, the error was: %s\n", *inputfile, err)
+		if ofile, err = createoutput(*outputfile); err != nil {
+			log.Fatalf("Can't open file %s for writing, the error was: %s\n", *outputfile, err)
 		}
 		defer ofile.Close()
 	}
-	if err := tpl.Execute(ofile, spec); err != nil {
-		log.Panicf("Template execution failed: %s\n", err)
+	if err := tpl.Execute(ofile, data); err != nil {
+		log.Fatalf("Template execution failed: %s\n", err)
 	}
-	log.Printf("Sepcification successfully transformed\n")
+	log.Printf("Specification successfully transformed\n")
 }
-
-const builtintpl = `
-<!DOCTYPE html>
-<html lang="de">
-<head>
-<title></title>
-<meta charset="UTF-8">
-<style>
-  table.ogdatspectable{
-    width: 800px;
-  }
-  table.ogdatspectable colgroup.idcolumn {
-    width: 200px;
-  }
-  th {
-    text-align: left;
-  }
-  table.ogdatspectable[ogdrequired="true"] {
-    background-color:hsl(30, 100%, 88%);
-    border: 1px solid hsl(30, 100%, 68%);  /* orange */
-  }
-  table.ogdatspectable[ogdrequired="false"] {
-    background-color:hsl(120, 100%, 88%);
-    border: 1px solid hsl(120, 100%, 68%);  /* grenish */
-  }
-  table.ogdatspectable[ogdrequired="true"] th {
-    background-color:hsl(30, 100%, 75%);
-  }
-  table.ogdatspectable[ogdrequired="false"] th {
-    background-color: hsl(120, 100%, 75%);
-  }
-  table.ogdatspectable[ogdrequired="true"] tr:nth-child(odd) td {
-    background-color:hsl(30, 100%, 95%);
-  }
-  table.ogdatspectable[ogdrequired="false"]  tr:nth-child(odd) td {
-    background-color: hsl(120, 100%, 95%);
-  }
-</style>
-</head>
-<body>
-<h1>Open Government Data Österreich Metadatenspezifikation</h1>
-<div id=ogdatspecarea>{{range .Beschreibung}}
-<p>
-<table class="ogdatspectable" ogdrequired='{{.IsRequired}}'>
-  <caption>{{.Bezeichner}} - {{if .IsRequired}}Pflichtfeld{{else}}optionaler Eintrag{{end}}</caption>
-  <colgroup class="idcolumn">
-  <colgroup class="valcolumn" span="4">
-  <tbody>
-    <tr>
-      <th id="ID.desc.{{.ID}}">{{index $.Label 0}}</th>
-      <th id="Bezeichner.desc.{{.ID}}">{{index $.Label 1}}</th>
-      <th id="OGD_Kurzname.desc.{{.ID}}">{{index $.Label 2}}</th>
-      <th id="CKAN_Feld.desc.{{.ID}}">{{index $.Label 3}}</th>
-      <th id="Anzahl.desc.{{.ID}}">{{index $.Label 4}}</th>
-    </tr>
-    <tr>
-      <td id="ID.item.{{.ID}}">{{.ID}}</td>
-      <td id="Bezeichner.item.{{.ID}}">{{.Bezeichner}}</td>
-      <td id="OGD_Kurzname.item.{{.ID}}">{{.OGD_Kurzname}}</td>
-      <td id="CKAN_Feld.item.{{.ID}}">{{.CKAN_Feld}}</td>
-      <td id="Anzahl.item.{{.ID}}">{{.Anzahl}}</td>
-    </tr>
-  </tbody>
-  <tbody>
-    <tr>
-      <th id="Definition_DE.desc.{{.ID}}">{{index $.Label 5}}</th>
-      <td id="Definition_DE.item.{{.ID}}" colspan="4">{{.Definition_DE}}</td>
-    </tr>
-    <tr>
-      <th id="Erlauterung.desc.{{.ID}}">{{index $.Label 6}}</th>
-      <td id="Erlauterung.item.{{.ID}}" colspan="4">{{.Erlauterung}}</td>
-    </tr>
-    <tr>
-      <th id="Beispiel.desc.{{.ID}}">{{index $.Label 7}}</th>
-      <td id="Beispiel.item.{{.ID}}" colspan="4">{{.Beispiel}}</td>
-    </tr>
-    <tr>
-      <th id="ONA2270.desc.{{.ID}}">{{index $.Label 8}}</th>
-      <td id="ONA2270.item.{{.ID}}" colspan="4">{{.ONA2270}}</td>
-    </tr>
-    <tr>
-      <th id="ISO19115.desc.{{.ID}}">{{index $.Label 9}}</th>
-      <td id="ISO19115.item.{{.ID}}" colspan="4">{{.ISO19115}}</td>
-    </tr>
-    <tr>
-      <th id="RDFProperty.desc.{{.ID}}">{{index $.Label 10}}</th>
-      <td id="RDFProperty.item.{{.ID}}" colspan="4">{{.RDFProperty}}</td>
-    </tr>
-    <tr>
-      <th id="Definition_EN.desc.{{.ID}}">{{index $.Label 11}}</th>
-      <td id="Definition_EN.item.{{.ID}}" colspan="4">{{.Definition_EN}}</td>
-    </tr>
-  </tbody>
-</table>{{end}}
-</div>
-</body>
-</html>
-`
@@ -0,0 +1,354 @@
+package main
+
+import (
+	"github.com/the42/ogdat"
+	"sort"
+	"strings"
+)
+
+// specdata is passed to the templates rendering a single specification. The
+// embedded OGDSet keeps templates written for earlier releases working.
+type specdata struct {
+	*ogdat.OGDSet
+	Version string
+}
+
+// versioncell describes a field in one version of the specification. Beschreibung
+// is nil if the field is not part of that version, Marker tells whether the field
+// is "neu", "geändert" or "entfernt" compared to the preceding version.
+type versioncell struct {
+	Version      string
+	Beschreibung *ogdat.Beschreibung
+	Marker       string
+	Changes      []ogdat.PropertyChange
+}
+
+type versionrow struct {
+	ID    int
+	Name  string // OGD_Kurzname in the newest version containing the field
+	Cells []versioncell
+}
+
+// versionsdata is passed to the templates rendering all registered versions side-by-side
+type versionsdata struct {
+	Label    []string // labels of the newest version
+	Versions []string
+	Fields   []versionrow
+}
+
+// markers for the changes of a field compared to the preceding version
+var kindmarkers = map[string]string{
+	ogdat.FieldAdded:   "neu",
+	ogdat.FieldRemoved: "entfernt",
+	ogdat.FieldChanged: "geändert",
+}
+
+// sidebyside arranges the fields of the specifications sets, ordered from
+// oldest to newest, by ID and marks the differences between consecutive versions
+func sidebyside(versions []string, sets []*ogdat.OGDSet) versionsdata {
+	data := versionsdata{Versions: versions}
+	if len(sets) > 0 {
+		data.Label = sets[len(sets)-1].Label
+	}
+
+	rows := make(map[int]*versionrow)
+	var ids []int
+	for idx, set := range sets {
+		diffs := make(map[int]ogdat.FieldDiff)
+		if idx > 0 {
+			for _, f := range ogdat.DiffSpec(sets[idx-1], set).Fields {
+				diffs[f.ID] = f
+			}
+		}
+		for _, desc := range set.Beschreibung {
+			if _, ok := rows[desc.ID]; !ok {
+				rows[desc.ID] = &versionrow{ID: desc.ID, Cells: make([]versioncell, len(sets))}
+				ids = append(ids, desc.ID)
+			}
+			rows[desc.ID].Name = desc.OGD_Kurzname
+		}
+		for id, row := range rows {
+			desc, _ := set.GetBeschreibungForID(id)
+			cell := versioncell{Version: versions[idx], Beschreibung: desc}
+			if f, ok := diffs[id]; ok {
+				cell.Marker, cell.Changes = kindmarkers[f.Kind], f.Changes
+			}
+			row.Cells[idx] = cell
+		}
+	}
+
+	sort.Ints(ids)
+	for _, id := range ids {
+		data.Fields = append(data.Fields, *rows[id])
+	}
+	return data
+}
+
+// jsonschema returns the JSON Schema of the CKAN package according to the specification
+func jsonschema(data specdata) (string, error) {
+	b, err := ogdat.JSONSchema(data.OGDSet)
+	return string(b) + "\n", err
+}
+
+// tablecell puts text on a single line and escapes "|" for a cell of a Markdown or AsciiDoc table
+func tablecell(s string) string {
+	return strings.Replace(strings.Join(strings.Fields(s), " "), "|", `\|`, -1)
+}
+
+var templatefuncs = map[string]interface{}{
+	"occurrence": func(desc *ogdat.Beschreibung) string {
+		if desc.IsRequired() {
+			return "R"
+		}
+		return "O"
+	},
+	"md":   tablecell,
+	"adoc": tablecell,
+	// csv quotes a field according to RFC 4180
+	"csv": func(s string) string {
+		if strings.ContainsAny(s, ",\"\r\n") {
+			return `"` + strings.Replace(s, `"`, `""`, -1) + `"`
+		}
+		return s
+	},
+	"properties": func(changes []ogdat.PropertyChange) string {
+		names := make([]string, len(changes))
+		for idx, c := range changes {
+			names[idx] = c.Property
+		}
+		return strings.Join(names, ", ")
+	},
+	"jsonschema": jsonschema,
+}
+
+// builtin templates rendering a single specification, by output format
+var builtintpls = map[string]string{
+	"html":       builtinhtml,
+	"markdown":   builtinmarkdown,
+	"asciidoc":   builtinasciidoc,
+	"jsonschema": "{{jsonschema .}}",
+	"csv":        builtincsv,
+}
+
+// builtin templates rendering all registered versions, by output format
+var builtinalltpls = map[string]string{
+	"html":     builtinhtmlall,
+	"markdown": builtinmarkdownall,
+	"asciidoc": builtinasciidocall,
+	"csv":      builtincsvall,
+}
+
+const builtinhtml = `
+<!DOCTYPE html>
+<html lang="de">
+<head>
+<title>{{.Version}}</title>
+<meta charset="UTF-8">
+<style>
+  table.ogdatspectable{
+    width: 800px;
+  }
+  table.ogdatspectable colgroup.idcolumn {
+    width: 200px;
+  }
+  th {
+    text-align: left;
+  }
+  table.ogdatspectable[ogdrequired="true"] {
+    background-color:hsl(30, 100%, 88%);
+    border: 1px solid hsl(30, 100%, 68%);  /* orange */
+  }
+  table.ogdatspectable[ogdrequired="false"] {
+    background-color:hsl(120, 100%, 88%);
+    border: 1px solid hsl(120, 100%, 68%);  /* grenish */
+  }
+  table.ogdatspectable[ogdrequired="true"] th {
+    background-color:hsl(30, 100%, 75%);
+  }
+  table.ogdatspectable[ogdrequired="false"] th {
+    background-color: hsl(120, 100%, 75%);
+  }
+  table.ogdatspectable[ogdrequired="true"] tr:nth-child(odd) td {
+    background-color:hsl(30, 100%, 95%);
+  }
+  table.ogdatspectable[ogdrequired="false"]  tr:nth-child(odd) td {
+    background-color: hsl(120, 100%, 95%);
+  }
+</style>
+</head>
+<body>
+<h1>Open Government Data Österreich Metadatenspezifikation</h1>
+<h2>{{.Version}}</h2>
+<div id=ogdatspecarea>{{range .Beschreibung}}
+<p>
+<table class="ogdatspectable" ogdrequired='{{.IsRequired}}'>
+  <caption>{{.Bezeichner}} - {{if .IsRequired}}Pflichtfeld{{else}}optionaler Eintrag{{end}}</caption>
+  <colgroup class="idcolumn">
+  <colgroup class="valcolumn" span="4">
+  <tbody>
+    <tr>
+      <th id="ID.desc.{{.ID}}">{{index $.Label 0}}</th>
+      <th id="Bezeichner.desc.{{.ID}}">{{index $.Label 1}}</th>
+      <th id="OGD_Kurzname.desc.{{.ID}}">{{index $.Label 2}}</th>
+      <th id="CKAN_Feld.desc.{{.ID}}">{{index $.Label 3}}</th>
+      <th id="Anzahl.desc.{{.ID}}">{{index $.Label 4}}</th>
+    </tr>
+    <tr>
+      <td id="ID.item.{{.ID}}">{{.ID}}</td>
+      <td id="Bezeichner.item.{{.ID}}">{{.Bezeichner}}</td>
+      <td id="OGD_Kurzname.item.{{.ID}}">{{.OGD_Kurzname}}</td>
+      <td id="CKAN_Feld.item.{{.ID}}">{{.CKAN_Feld}}</td>
+      <td id="Anzahl.item.{{.ID}}">{{.Anzahl}}</td>
+    </tr>
+  </tbody>
+  <tbody>
+    <tr>
+      <th id="Definition_DE.desc.{{.ID}}">{{index $.Label 5}}</th>
+      <td id="Definition_DE.item.{{.ID}}" colspan="4">{{.Definition_DE}}</td>
+    </tr>
+    <tr>
+      <th id="Erlauterung.desc.{{.ID}}">{{index $.Label 6}}</th>
+      <td id="Erlauterung.item.{{.ID}}" colspan="4">{{.Erlauterung}}</td>
+    </tr>
+    <tr>
+      <th id="Beispiel.desc.{{.ID}}">{{index $.Label 7}}</th>
+      <td id="Beispiel.item.{{.ID}}" colspan="4">{{.Beispiel}}</td>
+    </tr>
+    <tr>
+      <th id="ONA2270.desc.{{.ID}}">{{index $.Label 8}}</th>
+      <td id="ONA2270.item.{{.ID}}" colspan="4">{{.ONA2270}}</td>
+    </tr>
+    <tr>
+      <th id="ISO19115.desc.{{.ID}}">{{index $.Label 9}}</th>
+      <td id="ISO19115.item.{{.ID}}" colspan="4">{{.ISO19115}}</td>
+    </tr>
+    <tr>
+      <th id="RDFProperty.desc.{{.ID}}">{{index $.Label 10}}</th>
+      <td id="RDFProperty.item.{{.ID}}" colspan="4">{{.RDFProperty}}</td>
+    </tr>
+    <tr>
+      <th id="Definition_EN.desc.{{.ID}}">{{index $.Label 11}}</th>
+      <td id="Definition_EN.item.{{.ID}}" colspan="4">{{.Definition_EN}}</td>
+    </tr>
+  </tbody>
+</table>{{end}}
+</div>
+</body>
+</html>
+`
+
+const builtinmarkdown = `# Open Government Data Österreich Metadatenspezifikation
+
+{{.Version}}
+{{range .Beschreibung}}
+## {{.ID}} {{md .Bezeichner}} ({{if .IsRequired}}Pflichtfeld{{else}}optionaler Eintrag{{end}})
+
+| {{index $.Label 2}} | {{index $.Label 3}} | {{index $.Label 4}} |
+| --- | --- | --- |
+| {{md .OGD_Kurzname}} | {{md .CKAN_Feld}} | {{md .Anzahl}} |
+
+| | |
+| --- | --- |
+| {{index $.Label 5}} | {{md .Definition_DE}} |
+| {{index $.Label 6}} | {{md .Erlauterung}} |
+| {{index $.Label 7}} | {{md .Beispiel}} |
+| {{index $.Label 8}} | {{md .ONA2270}} |
+| {{index $.Label 9}} | {{md .ISO19115}} |
+| {{index $.Label 10}} | {{md .RDFProperty}} |
+| {{index $.Label 11}} | {{md .Definition_EN}} |
+{{end}}`
+
+const builtinasciidoc = `= Open Government Data Österreich Metadatenspezifikation
+
+{{.Version}}
+{{range .Beschreibung}}
+== {{.ID}} {{.Bezeichner}} ({{if .IsRequired}}Pflichtfeld{{else}}optionaler Eintrag{{end}})
+
+[cols="1,3"]
+|===
+|{{index $.Label 2}} |{{adoc .OGD_Kurzname}}
+|{{index $.Label 3}} |{{adoc .CKAN_Feld}}
+|{{index $.Label 4}} |{{adoc .Anzahl}}
+|{{index $.Label 5}} |{{adoc .Definition_DE}}
+|{{index $.Label 6}} |{{adoc .Erlauterung}}
+|{{index $.Label 7}} |{{adoc .Beispiel}}
+|{{index $.Label 8}} |{{adoc .ONA2270}}
+|{{index $.Label 9}} |{{adoc .ISO19115}}
+|{{index $.Label 10}} |{{adoc .RDFProperty}}
+|{{index $.Label 11}} |{{adoc .Definition_EN}}
+|===
+{{end}}`
+
+const builtincsv = `{{range $idx, $label := .Label}}{{if $idx}},{{end}}{{csv $label}}{{end}}
+{{range .Beschreibung}}{{.ID}},{{csv .Bezeichner}},{{csv .OGD_Kurzname}},{{csv .CKAN_Feld}},{{csv .Anzahl}},{{csv .Definition_DE}},{{csv .Erlauterung}},{{csv .Beispiel}},{{csv .ONA2270}},{{csv .ISO19115}},{{csv .RDFProperty}},{{csv .Definition_EN}},{{occurrence .}}
+{{end}}`
+
+const builtinhtmlall = `<!DOCTYPE html>
+<html lang="de">
+<head>
+<title>Open Government Data Österreich Metadatenspezifikation</title>
+<meta charset="UTF-8">
+<style>
+  table.ogdatspecversions {
+    border-collapse: collapse;
+  }
+  th, td {
+    text-align: left;
+    vertical-align: top;
+    border: 1px solid hsl(0, 0%, 80%);
+    padding: 2px 4px;
+  }
+  td.required {
+    background-color: hsl(30, 100%, 95%);
+  }
+  td.neu {
+    background-color: hsl(120, 100%, 88%);
+  }
+  td.geändert {
+    background-color: hsl(60, 100%, 85%);
+  }
+  td.entfernt {
+    background-color: hsl(0, 100%, 90%);
+  }
+  span.marker {
+    font-weight: bold;
+  }
+</style>
+</head>
+<body>
+<h1>Open Government Data Österreich Metadatenspezifikation</h1>
+<table class="ogdatspecversions">
+  <thead>
+    <tr><th>{{index .Label 0}}</th><th>{{index .Label 2}}</th>{{range .Versions}}<th>{{.}}</th>{{end}}</tr>
+  </thead>
+  <tbody>{{range .Fields}}
+    <tr>
+      <td>{{.ID}}</td><td>{{.Name}}</td>{{range .Cells}}
+      <td class="{{.Marker}}{{with .Beschreibung}}{{if .IsRequired}} required{{end}}{{end}}">{{with .Beschreibung}}{{.CKAN_Feld}}<br>{{if .IsRequired}}Pflichtfeld{{else}}optional{{end}}, {{.Anzahl}}{{end}}{{if .Marker}}<br><span class="marker">{{.Marker}}</span>{{with .Changes}}: {{properties .}}{{end}}{{end}}</td>{{end}}
+    </tr>{{end}}
+  </tbody>
+</table>
+</body>
+</html>
+`
+
+const builtinmarkdownall = `# Open Government Data Österreich Metadatenspezifikation
+
+| {{index .Label 0}} | {{index .Label 2}} |{{range .Versions}} {{.}} |{{end}}
+| --- | --- |{{range .Versions}} --- |{{end}}
+{{range .Fields}}| {{.ID}} | {{md .Name}} |{{range .Cells}} {{with .Beschreibung}}{{md .CKAN_Feld}} ({{occurrence .}}, {{md .Anzahl}}){{end}}{{if .Marker}} **{{.Marker}}**{{with .Changes}}: {{properties .}}{{end}}{{end}} |{{end}}
+{{end}}`
+
+const builtinasciidocall = `= Open Government Data Österreich Metadatenspezifikation
+
+[options="header"]
+|===
+|{{index .Label 0}} |{{index .Label 2}}{{range .Versions}} |{{.}}{{end}}
+{{range .Fields}}
+|{{.ID}} |{{adoc .Name}}{{range .Cells}} |{{with .Beschreibung}}{{adoc .CKAN_Feld}} ({{occurrence .}}, {{adoc .Anzahl}}){{end}}{{if .Marker}} *{{.Marker}}*{{with .Changes}}: {{properties .}}{{end}}{{end}}{{end}}
+{{end}}|===
+`
+
+const builtincsvall = `Version,{{csv (index .Label 0)}},{{csv (index .Label 2)}},{{csv (index .Label 3)}},{{csv (index .Label 4)}},{{csv (index .Label 12)}},Änderung,Eigenschaften
+{{range .Fields}}{{$field := .}}{{range .Cells}}{{if or .Beschreibung .Marker}}{{csv .Version}},{{$field.ID}},{{with .Beschreibung}}{{csv .OGD_Kurzname}},{{csv .CKAN_Feld}},{{csv .Anzahl}},{{occurrence .}}{{else}}{{csv $field.Name}},,,{{end}},{{.Marker}},{{csv (properties .Changes)}}
+{{end}}{{end}}{{end}}`
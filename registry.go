@@ -0,0 +1,282 @@
+package ogdat
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// MetadataFactory describes how metadata documents of a specific version of the
+// specification get parsed and checked. Every version package registers its
+// factory by calling RegisterMetadataFactory.
+type MetadataFactory struct {
+	Version string   // e.g. "OGD Austria Metadata 2.3"
+	Aliases []string // version numbers as found in schema_name, e.g. "2.3"
+	Parse   func(io.Reader) (Metadater, error)
+	New     func() Metadater
+	Spec    *OGDSet
+}
+
+// UnknownVersionError is returned when a metadata document does not name a
+// version of the specification, or names a version for which no factory is registered.
+type UnknownVersionError struct {
+	SchemaName string // the value of extras.schema_name, if any
+	Version    string // the version number extracted from SchemaName, if any
+}
+
+func (e *UnknownVersionError) Error() string {
+	return e.Message().Text
+}
+
+// Message returns the error as informational check message
+func (e *UnknownVersionError) Message() CheckMessage {
+	switch {
+	case e.SchemaName == "" && e.Version == "":
+		return NewCheckMessage(Info, -1, CodeSchemaMissing, NoParams)
+	case e.Version == "":
+		return NewCheckMessage(Info, -1, CodeSchemaNoVersion, ValueParams(e.SchemaName))
+	}
+	return NewCheckMessage(Info, -1, CodeVersionUnsupported, ValueParams(e.Version))
+}
+
+var factories = struct {
+	sync.RWMutex
+	byname map[string]*MetadataFactory
+	all    []*MetadataFactory
+	errors map[string]error // the errors of versions whose factory could not be created
+}{byname: make(map[string]*MetadataFactory), errors: make(map[string]error)}
+
+// RegisterMetadataFactory makes a factory available by its version and aliases.
+func RegisterMetadataFactory(f *MetadataFactory) *MetadataFactory {
+	factories.Lock()
+	defer factories.Unlock()
+
+	factories.byname[f.Version] = f
+	for _, alias := range f.Aliases {
+		factories.byname[alias] = f
+	}
+	factories.all = append(factories.all, f)
+	sort.Sort(byversion(factories.all))
+	return f
+}
+
+// RegisterMetadataFactoryError records that no factory can be created for
+// version and its aliases, e.g. as the specification could not be loaded.
+// GetMetadataFactory and ParseMetadata return err for these versions.
+func RegisterMetadataFactoryError(version string, aliases []string, err error) {
+	factories.Lock()
+	defer factories.Unlock()
+
+	factories.errors[version] = err
+	for _, alias := range aliases {
+		factories.errors[alias] = err
+	}
+}
+
+// GetMetadataFactory returns the factory for version, which is either the full
+// version name, an alias or any string from which a registered version number
+// can be extracted, as e.g. a schema_name.
+func GetMetadataFactory(version string) (*MetadataFactory, error) {
+	factories.RLock()
+	defer factories.RUnlock()
+
+	number := OGDVersionfromString(version)
+	for _, name := range []string{version, number} {
+		if f, ok := factories.byname[name]; ok {
+			return f, nil
+		}
+		if err, ok := factories.errors[name]; ok {
+			return nil, err
+		}
+	}
+	return nil, &UnknownVersionError{SchemaName: version, Version: number}
+}
+
+// MetadataFactories returns all registered factories, ordered from the oldest
+// to the newest version of the specification.
+func MetadataFactories() []*MetadataFactory {
+	factories.RLock()
+	defer factories.RUnlock()
+	return append([]*MetadataFactory(nil), factories.all...)
+}
+
+// ParseMetadata reads a JSON metadata document and parses it according to the
+// version given in extras.schema_name. If the version can not be determined or
+// is not supported, the returned error is of type *UnknownVersionError.
+func ParseMetadata(r io.Reader) (Metadater, error) {
+	bytedata, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	mmd, err := MinimalMetaDataforJSONStream(bytes.NewReader(bytedata))
+	if err != nil {
+		return nil, err
+	}
+	if mmd == nil || mmd.Schema_Name == nil {
+		return nil, &UnknownVersionError{}
+	}
+	f, err := GetMetadataFactory(*mmd.Schema_Name)
+	if err != nil {
+		return nil, err
+	}
+	return f.parse(bytedata)
+}
+
+func (f *MetadataFactory) parse(bytedata []byte) (Metadater, error) {
+	md, err := f.Parse(bytes.NewReader(bytedata))
+	if err != nil {
+		return nil, err
+	}
+	if md == nil {
+		return nil, fmt.Errorf("Metadaten nach %s können nicht gelesen werden", f.Version)
+	}
+	return md, nil
+}
+
+// DetectMetadataVersion determines the factory to use for the JSON document
+// bytedata and returns it together with a human readable reason for the choice.
+// The version is primarily taken from extras.schema_name. If schema_name is
+// missing or names an unsupported version, the fields present in the document
+// decide: the oldest registered version whose specification contains all of
+// them is chosen, the newest version if there is none.
+func DetectMetadataVersion(bytedata []byte) (*MetadataFactory, string, error) {
+	mmd, err := MinimalMetaDataforJSONStream(bytes.NewReader(bytedata))
+	if err != nil {
+		return nil, "", err
+	}
+
+	var reason string
+	if mmd != nil && mmd.Schema_Name != nil {
+		f, err := GetMetadataFactory(*mmd.Schema_Name)
+		if err == nil {
+			return f, fmt.Sprintf("schema_name '%s' verweist auf Version %s", *mmd.Schema_Name, OGDVersionfromString(*mmd.Schema_Name)), nil
+		}
+		reason = fmt.Sprintf("schema_name '%s' verweist auf keine unterstützte Version", *mmd.Schema_Name)
+	} else {
+		reason = "schema_name nicht angegeben"
+	}
+
+	all := MetadataFactories()
+	if len(all) == 0 {
+		return nil, "", &UnknownVersionError{}
+	}
+
+	present, err := ckanfieldsindocument(bytedata)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// fields unknown to every version, e.g. those added by CKAN, do not count
+	var known []string
+	for _, field := range present {
+		for _, f := range all {
+			if f.Spec.hasCKANField(field) {
+				known = append(known, field)
+				break
+			}
+		}
+	}
+
+	for _, f := range all {
+		var missing []string
+		for _, field := range known {
+			if !f.Spec.hasCKANField(field) {
+				missing = append(missing, field)
+			}
+		}
+		if len(missing) > 0 {
+			reason = fmt.Sprintf("%s; Felder nicht in %s (%s)", reason, f.Version, strings.Join(missing, ", "))
+			continue
+		}
+		return f, fmt.Sprintf("%s; alle Felder in %s vorhanden", reason, f.Version), nil
+	}
+	newest := all[len(all)-1]
+	return newest, fmt.Sprintf("%s; keine Version enthält alle Felder, verwende die aktuellste Version", reason), nil
+}
+
+// normalizeCKANField turns the CKAN field as written in the specification,
+// e.g. 'extras:categorization[“…“,“…“]' into 'extras:categorization'
+func normalizeCKANField(field string) string {
+	if idx := strings.IndexRune(field, '['); idx > -1 {
+		field = field[:idx]
+	}
+	return strings.Replace(field, " ", "", -1)
+}
+
+func (set *OGDSet) hasCKANField(field string) bool {
+	if set == nil {
+		return false
+	}
+	for _, desc := range set.Beschreibung {
+		if normalizeCKANField(desc.CKAN_Feld) == field {
+			return true
+		}
+	}
+	return false
+}
+
+// ckanfieldsindocument returns the fields present in a CKAN JSON document,
+// named like the CKAN fields in the specification
+func ckanfieldsindocument(bytedata []byte) ([]string, error) {
+	var toplevel map[string]json.RawMessage
+	if err := json.Unmarshal(bytedata, &toplevel); err != nil {
+		return nil, err
+	}
+
+	var fields []string
+	for key := range toplevel {
+		fields = append(fields, key)
+	}
+
+	var extras map[string]json.RawMessage
+	if raw, ok := toplevel["extras"]; ok {
+		// extras which are not a JSON object are of no interest here
+		json.Unmarshal(raw, &extras)
+	}
+	for key := range extras {
+		fields = append(fields, "extras:"+key)
+	}
+
+	var resources []map[string]json.RawMessage
+	if raw, ok := toplevel["resources"]; ok {
+		json.Unmarshal(raw, &resources)
+	}
+	seen := make(map[string]bool)
+	for _, resource := range resources {
+		for key := range resource {
+			if !seen[key] {
+				fields = append(fields, "resources:"+key)
+				seen[key] = true
+			}
+		}
+	}
+	sort.Strings(fields)
+	return fields, nil
+}
+
+// byversion sorts factories by the version number of the specification
+type byversion []*MetadataFactory
+
+func (v byversion) Len() int      { return len(v) }
+func (v byversion) Swap(i, j int) { v[i], v[j] = v[j], v[i] }
+func (v byversion) Less(i, j int) bool {
+	return versionless(OGDVersionfromString(v[i].Version), OGDVersionfromString(v[j].Version))
+}
+
+func versionless(a, b string) bool {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		ai, _ := strconv.Atoi(as[i])
+		bi, _ := strconv.Atoi(bs[i])
+		if ai != bi {
+			return ai < bi
+		}
+	}
+	return len(as) < len(bs)
+}
@@ -0,0 +1,278 @@
+package ogdat
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sync"
+)
+
+// RuleContext is handed to a rule for every field of a metadata document the
+// rule applies to
+type RuleContext struct {
+	Version         string        // the version of the specification the document is checked against
+	Metadata        Metadater     // the document being checked
+	Field           *Beschreibung // the description of the field being checked
+	Value           interface{}   // the field as found in the metadata struct, usually a pointer which is nil if the field is not present
+	Resource        int           // index of the resource the field belongs to, -1 for fields of the metadata set
+	FollowHTTPLinks bool
+	Options         map[string]string // options of the rule, defaults overridden by the rule configuration
+	Done            bool              // set by a rule to skip the remaining rules of this field
+}
+
+// NewMessage creates a message of type typ with code for the field and resource being checked
+func (ctx *RuleContext) NewMessage(typ Status, code string, params MessageParams) CheckMessage {
+	return NewCheckMessage(typ, ctx.Field.ID, code, params.AtResource(ctx.Resource))
+}
+
+// Message turns the result of a check into a message for the field and resource being checked
+func (ctx *RuleContext) Message(info *CheckInfo) CheckMessage {
+	return info.Message(ctx.Field.ID, ctx.Resource)
+}
+
+// IsNil reports whether the field being checked is not present in the document
+func (ctx *RuleContext) IsNil() bool {
+	if ctx.Value == nil {
+		return true
+	}
+	switch val := reflect.ValueOf(ctx.Value); val.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+		return val.IsNil()
+	}
+	return false
+}
+
+// RuleFunc checks the field given by ctx and returns the messages found. An
+// error is returned if the check could not be performed.
+type RuleFunc func(ctx *RuleContext) ([]CheckMessage, error)
+
+// Rule is a check applied to the fields of a metadata document. Rules are
+// registered with RegisterRule and configured by SetRuleConfig.
+type Rule struct {
+	Name        string            // names the rule in the rule configuration; rules for different versions may share a name
+	Description string            // human readable description of the rule
+	Fields      []string          // OGD_Kurzname of the fields to check, all fields if empty
+	Versions    []string          // versions of the specification, either full name or number as e.g. "2.3"; all versions if empty
+	Disabled    bool              // the rule has to be enabled by the rule configuration
+	Options     map[string]string // default options
+	Check       RuleFunc
+}
+
+// AppliesTo reports whether rule checks the field of version
+func (rule *Rule) AppliesTo(version, field string) bool {
+	return rule.appliesTo(version, field, rule.Fields)
+}
+
+func (rule *Rule) appliesTo(version, field string, fields []string) bool {
+	if len(rule.Versions) > 0 {
+		found := false
+		for _, v := range rule.Versions {
+			if v == version || v == OGDVersionfromString(version) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(fields) == 0 {
+		return true
+	}
+	for _, f := range fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+var rules = struct {
+	sync.RWMutex
+	all    []*Rule
+	config *RuleConfig
+}{}
+
+// RegisterRule adds rule to the rules applied by ApplyRules. Rules run in the
+// order of their registration.
+func RegisterRule(rule *Rule) *Rule {
+	rules.Lock()
+	defer rules.Unlock()
+	rules.all = append(rules.all, rule)
+	return rule
+}
+
+// Rules returns all registered rules in the order of their registration
+func Rules() []*Rule {
+	rules.RLock()
+	defer rules.RUnlock()
+	return append([]*Rule(nil), rules.all...)
+}
+
+// RuleSetting configures all rules of a name
+type RuleSetting struct {
+	Enabled  *bool             `json:"enabled,omitempty"`  // enables or disables the rule, the default of the rule if not set
+	Severity string            `json:"severity,omitempty"` // replaces the severity of the rule's messages: "Info", "Warning" or "Error"
+	Fields   []string          `json:"fields,omitempty"`   // replaces the fields the rule applies to
+	Options  map[string]string `json:"options,omitempty"`  // overrides the default options of the rule
+
+	severity Status
+}
+
+// RuleConfig is the configuration of the rules, as read by LoadRuleConfig:
+//
+//	{
+//	  "rules": {
+//	    "sane-characters": {"enabled": false},
+//	    "keywords": {"severity": "Error"},
+//	    "allowed-values": {"enabled": true, "fields": ["publisher"], "options": {"values": "Stadt Wien|Land Tirol"}}
+//	  }
+//	}
+type RuleConfig struct {
+	Rules map[string]*RuleSetting `json:"rules"`
+}
+
+// LoadRuleConfig reads a rule configuration in JSON from reader
+func LoadRuleConfig(reader io.Reader) (*RuleConfig, error) {
+	cfg := &RuleConfig{}
+	if err := json.NewDecoder(reader).Decode(cfg); err != nil {
+		return nil, fmt.Errorf("Regelkonfiguration kann nicht gelesen werden: %s", err)
+	}
+	return cfg, nil
+}
+
+// LoadRuleConfigFile reads a rule configuration in JSON from the file filename
+func LoadRuleConfigFile(filename string) (*RuleConfig, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return LoadRuleConfig(file)
+}
+
+// SetRuleConfig validates cfg against the registered rules and makes it the
+// configuration used by ApplyRules. A nil cfg restores the defaults.
+// SetRuleConfig is meant to be called on startup, after all rules are registered.
+func SetRuleConfig(cfg *RuleConfig) error {
+	rules.Lock()
+	defer rules.Unlock()
+
+	if cfg != nil {
+	nextsetting:
+		for name, setting := range cfg.Rules {
+			if setting == nil {
+				return fmt.Errorf("Regelkonfiguration: keine Einstellungen für Regel '%s'", name)
+			}
+			setting.severity = 0
+			if setting.Severity != "" {
+				severity, err := ParseStatus(setting.Severity)
+				if err != nil || severity&^SeverityMask != 0 || severity.Severity() != severity {
+					return fmt.Errorf("Regelkonfiguration: ungültiger Schweregrad '%s' für Regel '%s'", setting.Severity, name)
+				}
+				setting.severity = severity
+			}
+			for _, rule := range rules.all {
+				if rule.Name == name {
+					continue nextsetting
+				}
+			}
+			return fmt.Errorf("Regelkonfiguration: unbekannte Regel '%s'", name)
+		}
+	}
+	rules.config = cfg
+	return nil
+}
+
+// ApplyRules applies the registered and enabled rules to the field given by ctx
+// and returns the messages found
+func ApplyRules(ctx *RuleContext) ([]CheckMessage, error) {
+	rules.RLock()
+	all, cfg := rules.all, rules.config
+	rules.RUnlock()
+
+	var messages []CheckMessage
+	for _, rule := range all {
+		var setting *RuleSetting
+		if cfg != nil {
+			setting = cfg.Rules[rule.Name]
+		}
+
+		enabled, fields := !rule.Disabled, rule.Fields
+		if setting != nil {
+			if setting.Enabled != nil {
+				enabled = *setting.Enabled
+			}
+			if setting.Fields != nil {
+				fields = setting.Fields
+			}
+		}
+		if !enabled || !rule.appliesTo(ctx.Version, ctx.Field.OGD_Kurzname, fields) {
+			continue
+		}
+
+		ctx.Options = make(map[string]string, len(rule.Options))
+		for key, val := range rule.Options {
+			ctx.Options[key] = val
+		}
+		if setting != nil {
+			for key, val := range setting.Options {
+				ctx.Options[key] = val
+			}
+		}
+
+		msgs, err := rule.Check(ctx)
+		if err != nil {
+			return messages, err
+		}
+		if setting != nil && setting.severity != 0 {
+			for idx := range msgs {
+				msgs[idx].Type = msgs[idx].Type&^SeverityMask | setting.severity
+			}
+		}
+		messages = append(messages, msgs...)
+		if ctx.Done {
+			break
+		}
+	}
+	return messages, nil
+}
+
+// FieldByID returns the field of the struct md, or the struct md points to,
+// which is tagged with the OGD ID id. Embedded and nested structs without
+// an ID, as Extras, are searched too.
+func FieldByID(md interface{}, id int) (interface{}, bool) {
+	val := reflect.ValueOf(md)
+	for val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			return nil, false
+		}
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Struct {
+		return nil, false
+	}
+	return fieldbyid(val, id)
+}
+
+func fieldbyid(val reflect.Value, id int) (interface{}, bool) {
+	typ := val.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if f.PkgPath != "" && !f.Anonymous {
+			continue
+		}
+		fid := GetIDFromMetaDataStructField(f)
+		if fid == id {
+			return val.Field(i).Interface(), true
+		}
+		if fid == -1 && f.Type.Kind() == reflect.Struct {
+			if fval, ok := fieldbyid(val.Field(i), id); ok {
+				return fval, true
+			}
+		}
+	}
+	return nil, false
+}
@@ -1,10 +1,10 @@
-CREATE OR REPLACE FUNCTION insertorupdatemetadatainfo(IN inckanid character varying, IN inid character varying, IN pub character varying, IN cont character varying, IN descr text, IN invers character varying, IN incategory json, IN stime timestamp with time zone, IN ingeobbox character varying, IN ingeotoponym character varying, OUT datasetsysid integer, OUT isnew boolean)
+CREATE OR REPLACE FUNCTION insertorupdatemetadatainfo(IN inckanid character varying, IN inid character varying, IN pub character varying, IN cont character varying, IN descr text, IN invers character varying, IN incategory json, IN stime timestamp with time zone, IN ingeobbox character varying, IN ingeotoponym character varying, IN inlicense character varying, OUT datasetsysid integer, OUT isnew boolean)
   RETURNS record AS
 $BODY$
 BEGIN
   IF NOT EXISTS (SELECT 1 FROM dataset WHERE ckanid=inckanid LIMIT 1) THEN
-    INSERT INTO dataset(ckanid, id, publisher, contact, description, vers, category, geobbox, geotoponym)
-    VALUES (inckanid, inid, pub, cont, descr, invers, incategory, ingeobbox, ingeotoponym)
+    INSERT INTO dataset(ckanid, id, publisher, contact, description, vers, category, geobbox, geotoponym, license)
+    VALUES (inckanid, inid, pub, cont, descr, invers, incategory, ingeobbox, ingeotoponym, inlicense)
     RETURNING sysid INTO datasetsysid;
 
     -- Write status line about newly inserted metadata 
@@ -20,7 +20,8 @@ BEGIN
       vers=invers,
       category=incategory,
       geobbox=ingeobbox,
-      geotoponym=ingeotoponym
+      geotoponym=ingeotoponym,
+      license=inlicense
     WHERE ckanid=inckanid
     RETURNING sysid INTO datasetsysid;
 
@@ -0,0 +1,12 @@
+-- Remaps status.fieldstatus written before EmptyData got its own bit.
+-- EmptyData used to be x'16000', which overlaps FetchableUrl (x'2000') and
+-- NoDataatUrlError (x'4000'); it is x'10000' now. Bit x'10000' was set by no
+-- other flag, so rows having it set carry the old EmptyData value.
+-- Run once against a database written by an older ogdatwatcher.
+BEGIN;
+
+UPDATE status
+  SET fieldstatus = (fieldstatus & ~x'16000'::int) | x'10000'::int
+  WHERE (fieldstatus & x'16000'::int) = x'16000'::int;
+
+COMMIT;
@@ -0,0 +1,12 @@
+-- Adds the license of a dataset, as given in the field license, to the table
+-- dataset. insertorupdatemetadatainfo takes the license as an additional
+-- parameter, so its former version is dropped; recreate it afterwards from
+-- insertorupdatemetadatainfo.sql.
+-- Run once against a database written by an older ogdatwatcher.
+BEGIN;
+
+ALTER TABLE dataset ADD COLUMN license character varying(255);
+
+DROP FUNCTION IF EXISTS insertorupdatemetadatainfo(character varying, character varying, character varying, character varying, text, character varying, json, timestamp with time zone, character varying, character varying);
+
+COMMIT;
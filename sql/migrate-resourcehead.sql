@@ -0,0 +1,44 @@
+-- Adds the table resourcehead, which keeps what servers report in the header
+-- of their response for the resources of a dataset, next to the declared
+-- resource_size and resource_lastmodified, and the column resource to the
+-- table status, which keeps the index of the resource a message is about.
+-- Run once against a database written by an older ogdatwatcher.
+BEGIN;
+
+ALTER TABLE status ADD COLUMN resource integer;
+
+CREATE TABLE resourcehead (
+    sysid integer NOT NULL,
+    datasetid integer NOT NULL,
+    resource integer NOT NULL,
+    url text,
+    contentlength bigint,
+    lastmodified timestamp with time zone,
+    etag character varying(255),
+    declaredsize character varying(255),
+    declaredlastmodified timestamp with time zone,
+    hittime timestamp with time zone
+);
+
+CREATE SEQUENCE resourcehead_sysid_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+ALTER SEQUENCE resourcehead_sysid_seq OWNED BY resourcehead.sysid;
+
+ALTER TABLE ONLY resourcehead ALTER COLUMN sysid SET DEFAULT nextval('resourcehead_sysid_seq'::regclass);
+
+ALTER TABLE ONLY resourcehead
+    ADD CONSTRAINT resourcehead_pkey PRIMARY KEY (sysid);
+
+CREATE INDEX resourcehead_datasetid ON resourcehead USING btree (datasetid);
+
+CREATE INDEX resourcehead_hittime ON resourcehead USING btree (hittime);
+
+ALTER TABLE ONLY resourcehead
+    ADD CONSTRAINT resourcehead_datasetid_fkey FOREIGN KEY (datasetid) REFERENCES dataset(sysid);
+
+COMMIT;
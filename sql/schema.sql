@@ -25,7 +25,8 @@ CREATE TABLE dataset (
     category json,
     ckanid character varying(255),
     geobbox character varying(255),
-    geotoponym character varying(255)
+    geotoponym character varying(255),
+    license character varying(255)
 );
 
 CREATE SEQUENCE dataset_sysid_seq
@@ -64,7 +65,8 @@ CREATE TABLE status (
     field_id integer,
     hittime timestamp with time zone,
     status odstatus,
-    fieldstatus integer
+    fieldstatus integer,
+    resource integer
 );
 
 
@@ -77,6 +79,28 @@ CREATE SEQUENCE status_sysid_seq
 
 ALTER SEQUENCE status_sysid_seq OWNED BY status.sysid;
 
+CREATE TABLE resourcehead (
+    sysid integer NOT NULL,
+    datasetid integer NOT NULL,
+    resource integer NOT NULL,
+    url text,
+    contentlength bigint,
+    lastmodified timestamp with time zone,
+    etag character varying(255),
+    declaredsize character varying(255),
+    declaredlastmodified timestamp with time zone,
+    hittime timestamp with time zone
+);
+
+CREATE SEQUENCE resourcehead_sysid_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+ALTER SEQUENCE resourcehead_sysid_seq OWNED BY resourcehead.sysid;
+
 
 ALTER TABLE ONLY dataset ALTER COLUMN sysid SET DEFAULT nextval('dataset_sysid_seq'::regclass);
 
@@ -84,6 +108,8 @@ ALTER TABLE ONLY heartbeat ALTER COLUMN sysid SET DEFAULT nextval('heartbeat_sys
 
 ALTER TABLE ONLY status ALTER COLUMN sysid SET DEFAULT nextval('status_sysid_seq'::regclass);
 
+ALTER TABLE ONLY resourcehead ALTER COLUMN sysid SET DEFAULT nextval('resourcehead_sysid_seq'::regclass);
+
 ALTER TABLE ONLY heartbeat
     ADD CONSTRAINT pk_sysid PRIMARY KEY (sysid);
 
@@ -93,6 +119,9 @@ ALTER TABLE ONLY dataset
 ALTER TABLE ONLY status
     ADD CONSTRAINT status_pkey PRIMARY KEY (sysid);
 
+ALTER TABLE ONLY resourcehead
+    ADD CONSTRAINT resourcehead_pkey PRIMARY KEY (sysid);
+
 CREATE INDEX dataset_ckanid ON dataset USING btree (ckanid);
 
 CREATE INDEX dataset_publisher ON dataset USING btree (publisher);
@@ -105,5 +134,12 @@ CREATE INDEX status_hittime ON status USING btree (hittime);
 
 CREATE INDEX status_status ON status USING btree (status);
 
+CREATE INDEX resourcehead_datasetid ON resourcehead USING btree (datasetid);
+
+CREATE INDEX resourcehead_hittime ON resourcehead USING btree (hittime);
+
 ALTER TABLE ONLY status
     ADD CONSTRAINT status_datasetid_fkey FOREIGN KEY (datasetid) REFERENCES dataset(sysid);
+
+ALTER TABLE ONLY resourcehead
+    ADD CONSTRAINT resourcehead_datasetid_fkey FOREIGN KEY (datasetid) REFERENCES dataset(sysid);
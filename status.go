@@ -0,0 +1,154 @@
+package ogdat
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Status is the outcome of a check: a severity, one of Info, Warning or Error,
+// optionally combined with flags further describing the finding.
+// The numeric value gets persisted as status.fieldstatus by ogdatwatcher.
+type Status int
+
+// severities
+const (
+	Info Status = 1 << iota
+	Warning
+	Error
+)
+
+// flags
+const (
+	FetchSuccess Status = 0x1000
+	FetchableUrl Status = 0x2000
+
+	NoDataatUrlError Status = 0x4000
+	StructuralError  Status = 0x8000
+
+	// EmptyData used to be 0x16000, overlapping FetchableUrl and NoDataatUrlError.
+	// sql/migrate-emptydata.sql remaps values persisted before.
+	EmptyData Status = 0x10000
+)
+
+// SeverityMask selects the severity bits of a Status
+const SeverityMask = Info | Warning | Error
+
+var statusnames = []struct {
+	flag Status
+	name string
+}{
+	{Info, "Info"},
+	{Warning, "Warning"},
+	{Error, "Error"},
+	{FetchSuccess, "FetchSuccess"},
+	{FetchableUrl, "FetchableUrl"},
+	{NoDataatUrlError, "NoDataatUrlError"},
+	{StructuralError, "StructuralError"},
+	{EmptyData, "EmptyData"},
+}
+
+// HasFlag reports whether all bits of flag are set in s
+func (s Status) HasFlag(flag Status) bool {
+	return s&flag == flag
+}
+
+// IsError reports whether s has the severity Error
+func (s Status) IsError() bool {
+	return s&Error != 0
+}
+
+// IsWarning reports whether s has the severity Warning
+func (s Status) IsWarning() bool {
+	return s&Warning != 0
+}
+
+// IsInfo reports whether s has the severity Info
+func (s Status) IsInfo() bool {
+	return s&Info != 0
+}
+
+// Severity returns the most severe of the severity bits set in s, 0 if none is set
+func (s Status) Severity() Status {
+	switch {
+	case s.IsError():
+		return Error
+	case s.IsWarning():
+		return Warning
+	case s.IsInfo():
+		return Info
+	}
+	return 0
+}
+
+// Flags returns the names of the bits set in s. Unknown bits are returned in hexadecimal notation.
+func (s Status) Flags() (flags []string) {
+	for _, flag := range statusnames {
+		if s.HasFlag(flag.flag) {
+			flags = append(flags, flag.name)
+			s &^= flag.flag
+		}
+	}
+	if s != 0 {
+		flags = append(flags, fmt.Sprintf("0x%x", int(s)))
+	}
+	return
+}
+
+// String returns the names of the bits set in s joined by "|", e.g. "Info|FetchableUrl"
+func (s Status) String() string {
+	if s == 0 {
+		return "0"
+	}
+	return strings.Join(s.Flags(), "|")
+}
+
+// ParseStatus is the inverse of Status.String
+func ParseStatus(str string) (Status, error) {
+	var s Status
+	if str == "0" {
+		return s, nil
+	}
+nextflag:
+	for _, name := range strings.Split(str, "|") {
+		for _, flag := range statusnames {
+			if name == flag.name {
+				s |= flag.flag
+				continue nextflag
+			}
+		}
+		if strings.HasPrefix(name, "0x") {
+			if val, err := strconv.ParseInt(name[2:], 16, 64); err == nil {
+				s |= Status(val)
+				continue
+			}
+		}
+		return 0, fmt.Errorf("Unbekannter Status: '%s'", name)
+	}
+	return s, nil
+}
+
+// MarshalJSON encodes s as its String representation
+func (s Status) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+// UnmarshalJSON accepts either the String representation or the numeric value of a Status
+func (s *Status) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		var val int
+		if err := json.Unmarshal(data, &val); err != nil {
+			return err
+		}
+		*s = Status(val)
+		return nil
+	}
+	val, err := ParseStatus(str)
+	if err != nil {
+		return err
+	}
+	*s = val
+	return nil
+}